@@ -16,13 +16,11 @@ type createAccountRequest struct {
 	CategoryID  int32     `json:"category_id" binding:"required"`
 	Date        time.Time `json:"date" binding:"required"`
 	Value       int32     `json:"value" binding:"required"`
+	WalletID    *int32    `json:"wallet_id"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req createAccountRequest
 	err := ctx.ShouldBindJSON(&req)
@@ -31,27 +29,32 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	var categoryId = req.CategoryID
-	var accountType = req.Type
-
-	cat, err := server.store.GetCategory(ctx, categoryId)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, errorResponse(err))
+	// Adding to a category changes the ledger, which viewers may not.
+	cat, ok := server.getUserCategory(ctx, userClaims, req.CategoryID, ActionWrite)
+	if !ok {
 		return
 	}
-	if cat.Type != accountType {
+	if cat.Type != req.Type {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error:": "Account type is different of category type"})
 		return
 	}
 
+	if req.WalletID != nil {
+		_, ok := server.getUserWallet(ctx, userClaims, *req.WalletID, ActionWrite)
+		if !ok {
+			return
+		}
+	}
+
 	arg := db.CreateAccountParams{
 		Title:       req.Title,
 		Type:        req.Type,
 		Description: req.Description,
-		UserID:      cat.UserID,
-		CategoryID:  req.CategoryID,
+		LedgerID:    userClaims.LedgerID,
+		CategoryID:  nullInt32(&req.CategoryID),
 		Date:        req.Date,
 		Value:       req.Value,
+		WalletID:    nullInt32(req.WalletID),
 	}
 
 	acc, err := server.store.CreateAccount(ctx, arg)
@@ -68,10 +71,7 @@ type getAccountRequest struct {
 }
 
 func (server *Server) getAccount(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req getAccountRequest
 	err := ctx.ShouldBindUri(&req)
@@ -80,28 +80,30 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	acc, err := server.store.GetAccount(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	acc, ok := server.getUserAccount(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, acc)
 }
 
+// getUserAccount loads an account of the ledger of the request and checks the
+// policy for action. It returns false when a response was written.
+func (server *Server) getUserAccount(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.Account, bool) {
+	acc, err := server.store.GetAccount(ctx, db.GetAccountParams{
+		ID:       id,
+		LedgerID: userClaims.LedgerID,
+	})
+	return acc, server.authorizeLedgerRow(ctx, userClaims, action, acc.LedgerID, err)
+}
+
 type deleteAccountRequest struct {
 	ID int32 `uri:"id" binding:"required"`
 }
 
 func (server *Server) deleteAccount(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req deleteAccountRequest
 	err := ctx.ShouldBindUri(&req)
@@ -110,7 +112,15 @@ func (server *Server) deleteAccount(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteAccount(ctx, req.ID)
+	acc, ok := server.getUserAccount(ctx, userClaims, req.ID, ActionWrite)
+	if !ok || !checkNotTransferLeg(ctx, acc) || !checkNotInstallment(ctx, acc) {
+		return
+	}
+
+	err = server.store.DeleteAccount(ctx, db.DeleteAccountParams{
+		ID:       acc.ID,
+		LedgerID: userClaims.LedgerID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -130,10 +140,7 @@ type updateAccountRequest struct {
 }
 
 func (server *Server) updateAccount(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var reqUri updateAccountIdRequest
 	err := ctx.ShouldBindUri(&reqUri)
@@ -149,14 +156,20 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 		return
 	}
 
+	acc, ok := server.getUserAccount(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok || !checkNotTransferLeg(ctx, acc) || !checkNotInstallment(ctx, acc) {
+		return
+	}
+
 	arg := db.UpdateAccountsParams{
-		ID:          reqUri.ID,
+		ID:          acc.ID,
 		Title:       reqBody.Title,
 		Description: reqBody.Description,
 		Value:       reqBody.Value,
+		LedgerID:    userClaims.LedgerID,
 	}
 
-	acc, err := server.store.UpdateAccounts(ctx, arg)
+	acc, err = server.store.UpdateAccounts(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -165,19 +178,38 @@ func (server *Server) updateAccount(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, acc)
 }
 
+// checkNotTransferLeg answers 409 when the account is one side of a transfer,
+// which may only be changed or removed through the transfer endpoints. It
+// returns false when a response was written.
+func checkNotTransferLeg(ctx *gin.Context, acc db.Account) bool {
+	if acc.TransferID.Valid {
+		ctx.JSON(http.StatusConflict, gin.H{"error:": "Account belongs to a transfer, change the transfer instead"})
+		return false
+	}
+	return true
+}
+
+// checkNotInstallment answers 409 when the account is one installment of a
+// purchase, which may only be changed or removed as a whole through the
+// installment endpoints. It returns false when a response was written.
+func checkNotInstallment(ctx *gin.Context, acc db.Account) bool {
+	if acc.InstallmentGroupID.Valid {
+		ctx.JSON(http.StatusConflict, gin.H{"error:": "Account is an installment, change the installments instead"})
+		return false
+	}
+	return true
+}
+
 type listAccountsRequest struct {
-	Type        string    `form: "type" json:"type" binding:"required"`
-	CategoryID  int32     `form: "category_id" json:"category_id"`
-	Title       string    `form: "title" json:"title"`
-	Description string    `form: "description" json:"description"`
-	Date        time.Time `form: "description" json:"date"`
+	Type        string    `form:"type" json:"type" binding:"required"`
+	CategoryID  int32     `form:"category_id" json:"category_id"`
+	Title       string    `form:"title" json:"title"`
+	Description string    `form:"description" json:"description"`
+	Date        time.Time `form:"date" json:"date"`
 }
 
 func (server *Server) getAccounts(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req listAccountsRequest
 	err := ctx.ShouldBindQuery(&req)
@@ -187,7 +219,7 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 	}
 
 	arg := db.GetAccountsParams{
-		UserID:      userClaims.UserID,
+		LedgerID:    userClaims.LedgerID,
 		Type:        req.Type,
 		Title:       req.Title,
 		Description: req.Description,
@@ -210,57 +242,23 @@ func (server *Server) getAccounts(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, cats)
 }
 
-type getAccountGraphRequest struct {
-	Type string `json:"type" binding:"required"`
-}
-
-func (server *Server) getAccountGraph(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
-
-	var req getAccountGraphRequest
-	err := ctx.ShouldBindJSON(&req)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	arg := db.GetAccountGraphParams{
-		UserID: userClaims.UserID,
-		Type:   req.Type,
-	}
-
-	value, err := server.store.GetAccountGraph(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	ctx.JSON(http.StatusOK, value)
-}
-
 type getAccountReportsRequest struct {
-	Type string `json:"type" binding:"required"`
+	Type string `form:"type" json:"type" binding:"required"`
 }
 
 func (server *Server) getAccountsReports(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req getAccountReportsRequest
-	err := ctx.ShouldBindJSON(&req)
+	err := ctx.ShouldBindQuery(&req)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
 	arg := db.GetAccountsReportsParams{
-		UserID: userClaims.UserID,
-		Type:   req.Type,
+		LedgerID: userClaims.LedgerID,
+		Type:     req.Type,
 	}
 
 	value, err := server.store.GetAccountsReports(ctx, arg)
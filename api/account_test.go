@@ -0,0 +1,168 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) createAccount(t *testing.T, user testUser, cat db.Category, value int32) db.Account {
+	return ts.createAccountOn(t, user, cat, value, time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC))
+}
+
+func (ts *testServer) createAccountOn(t *testing.T, user testUser, cat db.Category, value int32, date time.Time) db.Account {
+	recorder := ts.request(t, http.MethodPost, "/account", gin.H{
+		"title":       util.RandomString(10),
+		"type":        cat.Type,
+		"description": util.RandomString(20),
+		"category_id": cat.ID,
+		"date":        date,
+		"value":       value,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[db.Account](t, recorder)
+}
+
+func TestCreateAccountAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+
+	acc := ts.createAccount(t, user, cat, 150)
+	require.Equal(t, ts.personalLedger(t, user).ID, acc.LedgerID)
+	require.Equal(t, cat.ID, acc.CategoryID.Int32)
+	require.Equal(t, int32(150), acc.Value)
+	require.False(t, acc.WalletID.Valid)
+
+	recorder := ts.request(t, http.MethodPost, "/account", gin.H{
+		"title":       "Salary",
+		"type":        "credit",
+		"description": "May",
+		"category_id": cat.ID,
+		"date":        time.Now(),
+		"value":       1000,
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/account", gin.H{
+		"title":       "Lunch",
+		"type":        "debit",
+		"description": "Restaurant",
+		"category_id": 9999,
+		"date":        time.Now(),
+		"value":       30,
+	}, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestCreateAccountWithWalletAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+	wallet := ts.createWallet(t, user, 0)
+	otherWallet := ts.createWallet(t, other, 0)
+
+	body := gin.H{
+		"title":       "Lunch",
+		"type":        "debit",
+		"description": "Restaurant",
+		"category_id": cat.ID,
+		"date":        time.Now(),
+		"value":       30,
+		"wallet_id":   wallet.ID,
+	}
+	recorder := ts.request(t, http.MethodPost, "/account", body, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, wallet.ID, decodeBody[db.Account](t, recorder).WalletID.Int32)
+
+	body["wallet_id"] = otherWallet.ID
+	recorder = ts.request(t, http.MethodPost, "/account", body, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetUpdateDeleteAccountAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+	acc := ts.createAccount(t, user, cat, 150)
+
+	url := fmt.Sprintf("/account/%d", acc.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, acc.Title, decodeBody[db.Account](t, recorder).Title)
+
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"title":       "Rent",
+		"description": "June",
+		"value":       900,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	updated := decodeBody[db.Account](t, recorder)
+	require.Equal(t, "Rent", updated.Title)
+	require.Equal(t, int32(900), updated.Value)
+	require.Equal(t, acc.Date, updated.Date)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestListAccountsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat1 := ts.createCategory(t, user, "debit")
+	cat2 := ts.createCategory(t, user, "debit")
+
+	acc := ts.createAccount(t, user, cat1, 100)
+	ts.createAccount(t, user, cat2, 200)
+
+	recorder := ts.request(t, http.MethodGet, "/accounts?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, decodeBody[[]db.GetAccountsRow](t, recorder), 2)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/accounts?type=debit&category_id=%d", cat1.ID), nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	rows := decodeBody[[]db.GetAccountsRow](t, recorder)
+	require.Len(t, rows, 1)
+	require.Equal(t, acc.ID, rows[0].ID)
+	require.Equal(t, cat1.Title, rows[0].CategoryTitle.String)
+
+	recorder = ts.request(t, http.MethodGet, "/accounts?type=credit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, decodeBody[[]db.GetAccountsRow](t, recorder))
+}
+
+func TestAccountGraphAndReportsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+	ts.createAccount(t, user, cat, 100)
+	ts.createAccount(t, user, cat, 250)
+
+	recorder := ts.request(t, http.MethodGet, "/account/graph?from=2023-05-01&to=2023-05-31&granularity=month", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	graph := decodeBody[accountGraphResponse](t, recorder)
+	require.Len(t, graph.Points, 1)
+	require.Equal(t, int64(350), graph.Points[0].Expense)
+
+	recorder = ts.request(t, http.MethodGet, "/account/reports?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, int64(350), decodeBody[int64](t, recorder))
+
+	recorder = ts.request(t, http.MethodGet, "/account/reports?type=credit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, int64(0), decodeBody[int64](t, recorder))
+
+	// The type is a query parameter, like with every other GET.
+	recorder = ts.request(t, http.MethodGet, "/account/reports", gin.H{"type": "debit"}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
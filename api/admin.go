@@ -0,0 +1,378 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/token"
+)
+
+const (
+	// auditTargetKey and auditDetailsKey let admin handlers tell
+	// auditMiddleware which user they acted on and how.
+	auditTargetKey  = "audit_target"
+	auditDetailsKey = "audit_details"
+
+	defaultPageSize = 20
+)
+
+var (
+	errCannotModifySelf = errors.New("admins cannot do this to their own account")
+	errStaffAccount     = errors.New("only admins can manage admin and support accounts")
+	errUnknownRole      = fmt.Errorf("role must be one of %s, %s or %s", db.RoleUser, db.RoleSupport, db.RoleAdmin)
+)
+
+// auditMiddleware writes every request to the admin API to the audit trail
+// once it was handled, denied ones included.
+func (server *Server) auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		details := ctx.Request.URL.RequestURI()
+		if extra := ctx.GetString(auditDetailsKey); extra != "" {
+			details += " " + extra
+		}
+		arg := db.CreateAuditLogParams{
+			ActorID:  sql.NullInt32{Int32: getUserClaims(ctx).UserID, Valid: true},
+			Action:   ctx.Request.Method + " " + ctx.FullPath(),
+			Status:   int32(ctx.Writer.Status()),
+			Details:  details,
+			ClientIp: ctx.ClientIP(),
+		}
+		if target, ok := ctx.Get(auditTargetKey); ok {
+			arg.TargetUserID = sql.NullInt32{Int32: target.(int32), Valid: true}
+		}
+
+		_, err := server.store.CreateAuditLog(ctx, arg)
+		if err != nil {
+			log.Printf("cannot write audit log of %s by user %d: %v", arg.Action, arg.ActorID.Int32, err)
+		}
+	}
+}
+
+type pageRequest struct {
+	PageID   int32 `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32 `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+func (req pageRequest) limitOffset() (int32, int32) {
+	pageID, pageSize := req.PageID, req.PageSize
+	if pageID == 0 {
+		pageID = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	return pageSize, (pageID - 1) * pageSize
+}
+
+// adminUserResponse is userResponse with what only staff get to see.
+type adminUserResponse struct {
+	userResponse
+	DisabledAt *time.Time `json:"disabled_at"`
+}
+
+func newAdminUserResponse(user db.User) adminUserResponse {
+	rsp := adminUserResponse{userResponse: newUserResponse(user)}
+	if user.DisabledAt.Valid {
+		rsp.DisabledAt = &user.DisabledAt.Time
+	}
+	return rsp
+}
+
+type listUsersRequest struct {
+	pageRequest
+	Search string `form:"search"`
+}
+
+// listUsers pages through the users, optionally those whose username or
+// email contains search.
+func (server *Server) listUsers(ctx *gin.Context) {
+	var req listUsersRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, offset := req.limitOffset()
+	users, err := server.store.ListUsers(ctx, db.ListUsersParams{
+		Search:     req.Search,
+		PageLimit:  limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]adminUserResponse, 0, len(users))
+	for _, user := range users {
+		rsp = append(rsp, newAdminUserResponse(user))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type adminUserRequest struct {
+	ID int32 `uri:"id" binding:"required,min=1"`
+}
+
+// loadTargetUser loads the user an admin route acts on and records them as
+// its audit target. With forChange, admins cannot target themselves and only
+// admins can target staff. It returns false when a response was written.
+func (server *Server) loadTargetUser(ctx *gin.Context, forChange bool) (db.User, bool) {
+	var req adminUserRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.User{}, false
+	}
+
+	user, err := server.store.GetUserById(ctx, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	ctx.Set(auditTargetKey, user.ID)
+
+	if !forChange {
+		return user, true
+	}
+	actor, ok := server.getCurrentUser(ctx)
+	if !ok {
+		return user, false
+	}
+	if actor.ID == user.ID {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errCannotModifySelf))
+		return user, false
+	}
+	if user.Role != db.RoleUser && actor.Role != db.RoleAdmin {
+		ctx.JSON(http.StatusForbidden, errorResponse(errStaffAccount))
+		return user, false
+	}
+	return user, true
+}
+
+func (server *Server) getAdminUser(ctx *gin.Context) {
+	user, ok := server.loadTargetUser(ctx, false)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// disableUser keeps a user from logging in, refreshing tokens or using API
+// keys, and logs them out everywhere.
+func (server *Server) disableUser(ctx *gin.Context) {
+	user, ok := server.loadTargetUser(ctx, true)
+	if !ok {
+		return
+	}
+
+	user, err := server.store.DisableUserTx(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+func (server *Server) enableUser(ctx *gin.Context) {
+	user, ok := server.loadTargetUser(ctx, true)
+	if !ok {
+		return
+	}
+
+	user, err := server.store.EnableUser(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+// forcePasswordReset logs a user out everywhere and makes their password
+// stop working. They get a reset link by email to choose a new one.
+func (server *Server) forcePasswordReset(ctx *gin.Context) {
+	user, ok := server.loadTargetUser(ctx, true)
+	if !ok {
+		return
+	}
+
+	resetToken, err := token.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	user, err = server.store.ForcePasswordResetTx(ctx, db.ForcePasswordResetTxParams{
+		UserID:    user.ID,
+		TokenHash: token.HashOpaqueToken(resetToken),
+		ExpiresAt: time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.sendInBackground(mail.Message{
+		To:      user.Email,
+		Subject: passwordResetSubject,
+		Body: fmt.Sprintf("Hi %s,\n\nFor the safety of your account, your password was reset and you were logged out. Use the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n",
+			user.Username, server.config.PasswordResetDuration, tokenLink(server.config.PasswordResetURL, resetToken)),
+	})
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+type setUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// setUserRole changes the role of a user. The new role applies to admin
+// routes right away and to the claims of their tokens from their next login
+// or refresh.
+func (server *Server) setUserRole(ctx *gin.Context) {
+	var req setUserRoleRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	switch req.Role {
+	case db.RoleUser, db.RoleSupport, db.RoleAdmin:
+	default:
+		ctx.JSON(http.StatusBadRequest, errorResponse(errUnknownRole))
+		return
+	}
+
+	user, ok := server.loadTargetUser(ctx, true)
+	if !ok {
+		return
+	}
+	ctx.Set(auditDetailsKey, fmt.Sprintf("role %s -> %s", user.Role, req.Role))
+
+	user, err = server.store.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		ID:   user.ID,
+		Role: req.Role,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAdminUserResponse(user))
+}
+
+type systemStatsResponse struct {
+	db.GetSystemStatsRow
+	UsersByRole map[string]int64 `json:"users_by_role"`
+}
+
+// getSystemStats reports totals across all users, never anyone's data.
+func (server *Server) getSystemStats(ctx *gin.Context) {
+	stats, err := server.store.GetSystemStats(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	roles, err := server.store.CountUsersByRole(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := systemStatsResponse{
+		GetSystemStatsRow: stats,
+		UsersByRole:       map[string]int64{},
+	}
+	for _, role := range roles {
+		rsp.UsersByRole[role.Role] = role.Users
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type auditLogResponse struct {
+	ID           int64     `json:"id"`
+	ActorID      *int32    `json:"actor_id"`
+	Action       string    `json:"action"`
+	TargetUserID *int32    `json:"target_user_id"`
+	Status       int32     `json:"status"`
+	Details      string    `json:"details"`
+	ClientIP     string    `json:"client_ip"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newAuditLogResponse(entry db.AuditLog) auditLogResponse {
+	rsp := auditLogResponse{
+		ID:        entry.ID,
+		Action:    entry.Action,
+		Status:    entry.Status,
+		Details:   entry.Details,
+		ClientIP:  entry.ClientIp,
+		CreatedAt: entry.CreatedAt,
+	}
+	if entry.ActorID.Valid {
+		rsp.ActorID = &entry.ActorID.Int32
+	}
+	if entry.TargetUserID.Valid {
+		rsp.TargetUserID = &entry.TargetUserID.Int32
+	}
+	return rsp
+}
+
+type getAuditLogsRequest struct {
+	pageRequest
+	UserID string `form:"user_id"`
+}
+
+// getAuditLogs pages through the audit trail, newest first, optionally only
+// the entries about one user.
+func (server *Server) getAuditLogs(ctx *gin.Context) {
+	var req getAuditLogsRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, offset := req.limitOffset()
+	arg := db.ListAuditLogsParams{
+		PageLimit:  limit,
+		PageOffset: offset,
+	}
+	if req.UserID != "" {
+		userID, err := strconv.ParseInt(req.UserID, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.TargetUserID = sql.NullInt32{Int32: int32(userID), Valid: true}
+	}
+
+	entries, err := server.store.ListAuditLogs(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]auditLogResponse, 0, len(entries))
+	for _, entry := range entries {
+		rsp = append(rsp, newAuditLogResponse(entry))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
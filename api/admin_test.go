@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createStaffAndLogin signs up a user with role and logs them in.
+func (ts *testServer) createStaffAndLogin(t *testing.T, role string) testUser {
+	user := ts.createUserAndLogin(t)
+	_, err := ts.store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{ID: user.ID, Role: role})
+	require.NoError(t, err)
+	return ts.loginAs(t, user)
+}
+
+func (ts *testServer) auditLogs(t *testing.T, admin testUser, userID int32) []auditLogResponse {
+	recorder := ts.request(t, http.MethodGet, fmt.Sprintf("/admin/audit-logs?user_id=%d", userID), nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[[]auditLogResponse](t, recorder)
+}
+
+func TestAdminPermissionsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	support := ts.createStaffAndLogin(t, db.RoleSupport)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	target := ts.createUserAndLogin(t)
+	targetURL := fmt.Sprintf("/admin/users/%d", target.ID)
+
+	testCases := []struct {
+		method, url string
+		body        any
+		user        testUser
+		code        int
+	}{
+		{http.MethodGet, "/admin/users", nil, user, http.StatusForbidden},
+		{http.MethodGet, "/admin/stats", nil, user, http.StatusForbidden},
+		{http.MethodPost, targetURL + "/password-reset", nil, user, http.StatusForbidden},
+		{http.MethodGet, "/admin/users", nil, support, http.StatusOK},
+		{http.MethodGet, targetURL, nil, support, http.StatusOK},
+		{http.MethodGet, "/admin/stats", nil, support, http.StatusOK},
+		{http.MethodPost, targetURL + "/disable", nil, support, http.StatusForbidden},
+		{http.MethodPut, targetURL + "/role", gin.H{"role": db.RoleAdmin}, support, http.StatusForbidden},
+		{http.MethodGet, "/admin/audit-logs", nil, support, http.StatusForbidden},
+		{http.MethodGet, "/admin/audit-logs", nil, admin, http.StatusOK},
+		{http.MethodGet, "/admin/users/9999", nil, admin, http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s %s as %s", tc.method, tc.url, tc.user.Role), func(t *testing.T) {
+			recorder := ts.request(t, tc.method, tc.url, tc.body, tc.user.Token)
+			require.Equal(t, tc.code, recorder.Code, recorder.Body.String())
+		})
+	}
+
+	recorder := ts.request(t, http.MethodGet, "/admin/users", nil, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Support cannot act on staff accounts, and nobody on their own.
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/admin/users/%d/password-reset", admin.ID), nil, support.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/admin/users/%d/disable", admin.ID), nil, admin.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Permissions follow the role in the database, not the one in the token.
+	_, err := ts.store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{ID: support.ID, Role: db.RoleUser})
+	require.NoError(t, err)
+	recorder = ts.request(t, http.MethodGet, "/admin/users", nil, support.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestListUsersAPI(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	for i := 0; i < 3; i++ {
+		ts.createUserAndLogin(t)
+	}
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodGet, "/admin/users?page_size=2&page_id=2", nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, decodeBody[[]adminUserResponse](t, recorder), 2)
+
+	recorder = ts.request(t, http.MethodGet, "/admin/users?search="+url.QueryEscape(user.Email), nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	users := decodeBody[[]adminUserResponse](t, recorder)
+	require.Len(t, users, 1)
+	require.Equal(t, user.ID, users[0].ID)
+	require.Nil(t, users[0].DisabledAt)
+
+	recorder = ts.request(t, http.MethodGet, "/admin/users?page_size=1000", nil, admin.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestDisableUserAPI(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	user := ts.createUserAndLogin(t)
+	_, withKey := ts.createAPIKey(t, user, ScopeRead)
+
+	recorder := ts.request(t, http.MethodPost, fmt.Sprintf("/admin/users/%d/disable", user.ID), nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.NotNil(t, decodeBody[adminUserResponse](t, recorder).DisabledAt)
+
+	require.Equal(t, http.StatusForbidden, ts.login(t, user.Username, user.PlainPassword))
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	recorder = ts.request(t, http.MethodGet, "/categories?type=income", nil, withKey.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/admin/users/%d/enable", user.ID), nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Nil(t, decodeBody[adminUserResponse](t, recorder).DisabledAt)
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, user.PlainPassword))
+
+	entries := ts.auditLogs(t, admin, user.ID)
+	require.Len(t, entries, 2)
+	require.Equal(t, "POST /admin/users/:id/enable", entries[0].Action)
+	require.Equal(t, "POST /admin/users/:id/disable", entries[1].Action)
+	require.Equal(t, admin.ID, *entries[1].ActorID)
+	require.Equal(t, int32(http.StatusOK), entries[1].Status)
+}
+
+func TestForcePasswordResetAPI(t *testing.T) {
+	ts := newTestServer(t)
+	support := ts.createStaffAndLogin(t, db.RoleSupport)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodPost, fmt.Sprintf("/admin/users/%d/password-reset", user.ID), nil, support.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, user.PlainPassword))
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	msg := ts.outbox.next(t, user.Email, passwordResetSubject)
+	link, err := url.Parse(resetLinkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	newPassword := util.RandomString(12)
+	recorder = ts.request(t, http.MethodPost, "/password/reset", gin.H{
+		"token":    link.Query().Get("token"),
+		"password": newPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, newPassword))
+}
+
+func TestSetUserRoleAPI(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	user := ts.createUserAndLogin(t)
+	roleURL := fmt.Sprintf("/admin/users/%d/role", user.ID)
+
+	recorder := ts.request(t, http.MethodPut, roleURL, gin.H{"role": "root"}, admin.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPut, roleURL, gin.H{"role": db.RoleSupport}, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Equal(t, db.RoleSupport, decodeBody[adminUserResponse](t, recorder).Role)
+
+	support := ts.loginAs(t, user)
+	recorder = ts.request(t, http.MethodGet, "/admin/stats", nil, support.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	entries := ts.auditLogs(t, admin, user.ID)
+	require.Len(t, entries, 1)
+	require.Contains(t, entries[0].Details, "role user -> support")
+}
+
+func TestSystemStatsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	user := ts.createUserAndLogin(t)
+	ts.createCategory(t, user, "income")
+	ts.createWallet(t, user, 100)
+
+	recorder := ts.request(t, http.MethodGet, "/admin/stats", nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	stats := decodeBody[systemStatsResponse](t, recorder)
+	require.Equal(t, int64(2), stats.Users)
+	require.Equal(t, int64(1), stats.Categories)
+	require.Equal(t, int64(1), stats.Wallets)
+	require.Equal(t, map[string]int64{db.RoleAdmin: 1, db.RoleUser: 1}, stats.UsersByRole)
+}
+
+func TestAdminRequestsAreAudited(t *testing.T) {
+	ts := newTestServer(t)
+	admin := ts.createStaffAndLogin(t, db.RoleAdmin)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodGet, "/admin/users?search=abc", nil, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/admin/audit-logs", nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	entries := decodeBody[[]auditLogResponse](t, recorder)
+	require.Len(t, entries, 1)
+	require.Equal(t, user.ID, *entries[0].ActorID)
+	require.Equal(t, "GET /admin/users", entries[0].Action)
+	require.Equal(t, int32(http.StatusForbidden), entries[0].Status)
+	require.Equal(t, "/admin/users?search=abc", entries[0].Details)
+	require.Nil(t, entries[0].TargetUserID)
+}
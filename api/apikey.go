@@ -0,0 +1,315 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+)
+
+const (
+	// apiKeyPrefix tells API keys apart from access tokens in the
+	// Authorization header, and makes leaked keys easy to scan for.
+	apiKeyPrefix = "gfk_"
+	// apiKeyDisplayLength is how much of a key is kept in the clear, so
+	// users can tell their keys apart.
+	apiKeyDisplayLength = len(apiKeyPrefix) + 6
+	// apiKeyTouchInterval keeps last_used_at from being written on every
+	// request of a busy script.
+	apiKeyTouchInterval = time.Minute
+	maxAPIKeyNameLength = 100
+)
+
+// Scopes an API key can be granted. Reading is a scope of its own; the write
+// scopes only allow changes to their resource.
+const (
+	ScopeRead             = "read"
+	ScopeCategoriesWrite  = "categories:write"
+	ScopeAccountsWrite    = "accounts:write"
+	ScopeWalletsWrite     = "wallets:write"
+	ScopeTransfersWrite   = "transfers:write"
+	ScopeRecurrencesWrite = "recurrences:write"
+)
+
+var apiKeyScopes = []string{
+	ScopeRead,
+	ScopeCategoriesWrite,
+	ScopeAccountsWrite,
+	ScopeWalletsWrite,
+	ScopeTransfersWrite,
+	ScopeRecurrencesWrite,
+}
+
+// routeWriteScopes maps the first segment of a data route to the scope that
+// allows writing to it. Routes missing here cannot be used with API keys.
+var routeWriteScopes = map[string]string{
+	"category":    ScopeCategoriesWrite,
+	"categories":  ScopeCategoriesWrite,
+	"account":     ScopeAccountsWrite,
+	"accounts":    ScopeAccountsWrite,
+	"wallet":      ScopeWalletsWrite,
+	"wallets":     ScopeWalletsWrite,
+	"transfer":    ScopeTransfersWrite,
+	"transfers":   ScopeTransfersWrite,
+	"recurrence":  ScopeRecurrencesWrite,
+	"recurrences": ScopeRecurrencesWrite,
+}
+
+var (
+	errInvalidAPIKeyName = fmt.Errorf("name must be 1 to %d characters", maxAPIKeyNameLength)
+	errUnknownScope      = fmt.Errorf("scopes must be some of %s", strings.Join(apiKeyScopes, ", "))
+	errExpiryInPast      = errors.New("expires_at must be in the future")
+	errInsufficientScope = errors.New("API key lacks the scope for this request")
+)
+
+// requiredScope returns the scope a request to the current route needs.
+func requiredScope(ctx *gin.Context) (string, bool) {
+	segment := strings.SplitN(strings.TrimPrefix(ctx.FullPath(), "/"), "/", 2)[0]
+	writeScope, ok := routeWriteScopes[segment]
+	if !ok {
+		return "", false
+	}
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ScopeRead, true
+	}
+	return writeScope, true
+}
+
+// authenticateAPIKey is what dataAuthMiddleware does for API keys: the key
+// must be live and have the scope of the route. Requests are made as the
+// owner of the key, with their current role.
+func (server *Server) authenticateAPIKey(ctx *gin.Context, key string) {
+	apiKey, err := server.store.GetAPIKeyByHash(ctx, token.HashOpaqueToken(key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			abortUnauthorized(ctx)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	now := time.Now()
+	if apiKey.RevokedAt.Valid || (apiKey.ExpiresAt.Valid && !now.Before(apiKey.ExpiresAt.Time)) {
+		abortUnauthorized(ctx)
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			abortUnauthorized(ctx)
+			return
+		}
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.DisabledAt.Valid {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errAccountDisabled))
+		return
+	}
+
+	claims := &UserClaims{
+		UserID:   user.ID,
+		UserName: user.Username,
+		Role:     user.Role,
+		Scopes:   apiKey.Scopes,
+	}
+	scope, ok := requiredScope(ctx)
+	if !ok || !claims.hasScope(scope) {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errInsufficientScope))
+		return
+	}
+
+	if !apiKey.LastUsedAt.Valid || now.Sub(apiKey.LastUsedAt.Time) >= apiKeyTouchInterval {
+		err = server.store.TouchAPIKey(ctx, db.TouchAPIKeyParams{
+			ID:         apiKey.ID,
+			LastUsedAt: sql.NullTime{Time: now, Valid: true},
+		})
+		if err != nil {
+			log.Printf("cannot record use of API key %d: %v", apiKey.ID, err)
+		}
+	}
+
+	ctx.Set(authorizationClaimsKey, claims)
+	ctx.Next()
+}
+
+// apiKeyResponse describes a key without the key itself, which is only shown
+// when it is created.
+type apiKeyResponse struct {
+	ID         int32      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAPIKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	rsp := apiKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    apiKey.Scopes,
+		CreatedAt: apiKey.CreatedAt,
+	}
+	if apiKey.ExpiresAt.Valid {
+		rsp.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	if apiKey.LastUsedAt.Valid {
+		rsp.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	return rsp
+}
+
+type createAPIKeyRequest struct {
+	Password  string     `json:"password" binding:"required"`
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type createAPIKeyResponse struct {
+	apiKeyResponse
+	Key string `json:"key"`
+}
+
+// validateAPIKeyRequest checks the request and returns its scopes without
+// duplicates.
+func validateAPIKeyRequest(req createAPIKeyRequest) ([]string, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, errInvalidAPIKeyName
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errExpiryInPast
+	}
+
+	scopes := []string{}
+	for _, scope := range req.Scopes {
+		if !contains(apiKeyScopes, scope) {
+			return nil, errUnknownScope
+		}
+		if !contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, errUnknownScope
+	}
+	return scopes, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// createAPIKey issues a key for scripts. Like other sensitive changes it
+// takes the password, so a stolen access token cannot be turned into a
+// long-lived key.
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	scopes, err := validateAPIKeyRequest(req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.Password) {
+		return
+	}
+
+	secret, err := token.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	key := apiKeyPrefix + secret
+
+	arg := db.CreateAPIKeyParams{
+		UserID:  user.ID,
+		Name:    strings.TrimSpace(req.Name),
+		KeyHash: token.HashOpaqueToken(key),
+		Prefix:  key[:apiKeyDisplayLength],
+		Scopes:  scopes,
+	}
+	if req.ExpiresAt != nil {
+		arg.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+	apiKey, err := server.store.CreateAPIKey(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	log.Printf("audit: user %d created API key %d with scopes %s", user.ID, apiKey.ID, strings.Join(scopes, ","))
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{
+		apiKeyResponse: newAPIKeyResponse(apiKey),
+		Key:            key,
+	})
+}
+
+// getAPIKeys lists the keys of the authenticated user that were not revoked,
+// expired ones included.
+func (server *Server) getAPIKeys(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	apiKeys, err := server.store.ListAPIKeys(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]apiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		rsp = append(rsp, newAPIKeyResponse(apiKey))
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type revokeAPIKeyRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+// revokeAPIKey stops a key from working, right away.
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req revokeAPIKeyRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	apiKey, err := server.store.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{
+		ID:     req.ID,
+		UserID: userClaims.UserID,
+	})
+	if !server.authorizeRow(ctx, userClaims, ActionWrite, apiKey.UserID, err) {
+		return
+	}
+
+	log.Printf("audit: user %d revoked API key %d", userClaims.UserID, apiKey.ID)
+	ctx.JSON(http.StatusOK, true)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/stretchr/testify/require"
+)
+
+// createAPIKey issues a key for user. The returned testUser sends the key in
+// place of an access token, so the usual helpers make requests with it.
+func (ts *testServer) createAPIKey(t *testing.T, user testUser, scopes ...string) (createAPIKeyResponse, testUser) {
+	recorder := ts.request(t, http.MethodPost, "/me/api-keys", gin.H{
+		"password": user.PlainPassword,
+		"name":     "script",
+		"scopes":   scopes,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	created := decodeBody[createAPIKeyResponse](t, recorder)
+
+	withKey := user
+	withKey.Token = created.Key
+	return created, withKey
+}
+
+func TestCreateAPIKeyAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		code int
+	}{
+		{"WrongPassword", gin.H{"password": "wrong-password", "name": "ci", "scopes": []string{ScopeRead}}, http.StatusForbidden},
+		{"NoName", gin.H{"password": user.PlainPassword, "name": " ", "scopes": []string{ScopeRead}}, http.StatusBadRequest},
+		{"NoScopes", gin.H{"password": user.PlainPassword, "name": "ci", "scopes": []string{}}, http.StatusBadRequest},
+		{"UnknownScope", gin.H{"password": user.PlainPassword, "name": "ci", "scopes": []string{"admin"}}, http.StatusBadRequest},
+		{"ExpiryInPast", gin.H{"password": user.PlainPassword, "name": "ci", "scopes": []string{ScopeRead}, "expires_at": time.Now().Add(-time.Hour)}, http.StatusBadRequest},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := ts.request(t, http.MethodPost, "/me/api-keys", tc.body, user.Token)
+			require.Equal(t, tc.code, recorder.Code, recorder.Body.String())
+		})
+	}
+
+	created, _ := ts.createAPIKey(t, user, ScopeRead, ScopeRead, ScopeAccountsWrite)
+	require.True(t, strings.HasPrefix(created.Key, apiKeyPrefix))
+	require.True(t, strings.HasPrefix(created.Key, created.Prefix))
+	require.Equal(t, []string{ScopeRead, ScopeAccountsWrite}, created.Scopes)
+	require.Nil(t, created.ExpiresAt)
+
+	// Only the hash is stored.
+	stored, err := ts.store.GetAPIKeyByHash(context.Background(), token.HashOpaqueToken(created.Key))
+	require.NoError(t, err)
+	require.NotEqual(t, created.Key, stored.KeyHash)
+
+	// API keys cannot manage the account, not even create more keys.
+	_, withKey := ts.createAPIKey(t, user, ScopeRead)
+	recorder := ts.request(t, http.MethodGet, "/me", nil, withKey.Token)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	recorder = ts.request(t, http.MethodPost, "/me/api-keys", gin.H{
+		"password": user.PlainPassword,
+		"name":     "another",
+		"scopes":   []string{ScopeRead},
+	}, withKey.Token)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	_, reader := ts.createAPIKey(t, user, ScopeRead)
+	_, writer := ts.createAPIKey(t, user, ScopeCategoriesWrite)
+
+	category := ts.createCategory(t, writer, "debit")
+	require.Equal(t, ts.personalLedger(t, user).ID, category.LedgerID)
+
+	recorder := ts.request(t, http.MethodGet, fmt.Sprintf("/category/%d", category.ID), nil, reader.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/category/%d", category.ID), nil, writer.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/category/%d", category.ID), nil, reader.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// A write scope only covers its own resource.
+	recorder = ts.request(t, http.MethodPost, "/wallet", gin.H{
+		"name":            "savings",
+		"type":            "checking",
+		"currency":        "BRL",
+		"opening_balance": 0,
+	}, writer.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Keys are checked, not decoded as access tokens.
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, apiKeyPrefix+"unknown")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestListAndRevokeAPIKeysAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	used, withKey := ts.createAPIKey(t, user, ScopeRead)
+	unused, _ := ts.createAPIKey(t, user, ScopeRead)
+	otherKey, _ := ts.createAPIKey(t, other, ScopeRead)
+
+	recorder := ts.request(t, http.MethodGet, "/categories?type=debit", nil, withKey.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/me/api-keys", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), used.Key)
+	keys := decodeBody[[]apiKeyResponse](t, recorder)
+	require.Len(t, keys, 2)
+	require.Equal(t, used.ID, keys[0].ID)
+	require.NotNil(t, keys[0].LastUsedAt)
+	require.Equal(t, unused.ID, keys[1].ID)
+	require.Nil(t, keys[1].LastUsedAt)
+
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/me/api-keys/%d", otherKey.ID), nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/me/api-keys/%d", used.ID), nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/me/api-keys/%d", used.ID), nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, withKey.Token)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/me/api-keys", nil, user.Token)
+	require.Len(t, decodeBody[[]apiKeyResponse](t, recorder), 1)
+}
+
+func TestExpiredAPIKey(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	key := apiKeyPrefix + "expired"
+	_, err := ts.store.CreateAPIKey(context.Background(), db.CreateAPIKeyParams{
+		UserID:    user.ID,
+		Name:      "old script",
+		KeyHash:   token.HashOpaqueToken(key),
+		Prefix:    key[:apiKeyDisplayLength],
+		Scopes:    []string{ScopeRead},
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	recorder := ts.request(t, http.MethodGet, "/categories?type=debit", nil, key)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -1,16 +1,19 @@
 package api
 
 import (
-	"bytes"
-	"crypto/sha512"
 	"database/sql"
+	"errors"
+	"log"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/password"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
 )
 
 type loginRequest struct {
@@ -18,15 +21,30 @@ type loginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+var (
+	errMissingUserID        = errors.New("token has no user id")
+	errTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+	errWrongTokenPurpose    = errors.New("token is not an access token")
+	errAccountDisabled      = errors.New("account is disabled")
+)
+
 type MyCustomClaims struct {
+	UserID   int32  `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`
+	// Purpose is set on tokens that are not access tokens, such as the MFA
+	// challenge, so they cannot be used as one.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type loginResponseStruct struct {
-	UserID   int32  `json:"user_id"`
-	UserName string `json:"username"`
-	Token    string `json:"token"`
+	UserID                int32     `json:"user_id"`
+	UserName              string    `json:"username"`
+	Token                 string    `json:"token"`
+	TokenExpiresAt        time.Time `json:"token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 func (server *Server) login(ctx *gin.Context) {
@@ -37,108 +55,153 @@ func (server *Server) login(ctx *gin.Context) {
 		return
 	}
 
+	wait, err := server.logins.Check(ctx, req.Username, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if wait > 0 {
+		setRetryAfter(ctx, wait)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return
+	}
+
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			server.loginFailed(ctx, req.Username)
 			return
 		}
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	hashedInput := sha512.Sum512_256([]byte(req.Password))
-	trimmedHash := bytes.Trim(hashedInput[:], "\x00")
-	preparedPassword := string(trimmedHash)
-	plainTextInBytes := []byte(preparedPassword)
-	hashTextInBytes := []byte(user.Password)
-	err = bcrypt.CompareHashAndPassword(hashTextInBytes, plainTextInBytes)
+	needsRehash, err := server.passwords.Verify(req.Password, user.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		if !errors.Is(err, password.ErrMismatch) {
+			log.Printf("cannot verify password of user %d: %v", user.ID, err)
+		}
+		server.loginFailed(ctx, req.Username)
 		return
 	}
-
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &MyCustomClaims{
-		Username: req.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
+	if needsRehash {
+		server.rehashPassword(ctx, user, req.Password)
 	}
 
-	generatedToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	var jwtSignedKey = []byte("secret_key")
-	generatedTokenString, err := generatedToken.SignedString(jwtSignedKey)
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountDisabled))
+		return
+	}
+	if server.config.EmailVerificationPolicy == util.EmailVerificationLogin && !user.EmailVerifiedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errEmailNotVerified))
+		return
+	}
 
+	// Failed logins are only forgotten once the second factor is in too,
+	// otherwise the password would buy unlimited guesses of codes.
+	mfaEnabled, err := server.totpEnabled(ctx, user.ID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if mfaEnabled {
+		challenge, err := server.mfaChallenge(user)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusOK, challenge)
+		return
+	}
 
-	result := loginResponseStruct{
-		UserID:   user.ID,
-		UserName: user.Username,
-		Token:    generatedTokenString,
+	err = server.logins.Success(ctx, req.Username)
+	if err != nil {
+		log.Printf("cannot reset failed logins of user %d: %v", user.ID, err)
+	}
+
+	result, err := server.startSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
 	}
 	ctx.JSON(http.StatusOK, result)
 }
 
-func validateToken(ctx *gin.Context, token string) (error, string) {
-	var jwtSignedKey = []byte("secret_key")
-	tokenParse, err := jwt.ParseWithClaims(token, &MyCustomClaims{},
-		func(t *jwt.Token) (interface{}, error) {
-			return jwtSignedKey, nil
-		})
-
+// loginFailed counts a failed login as username and answers 401, telling the
+// client how long to wait when the next attempt is held back.
+func (server *Server) loginFailed(ctx *gin.Context, username string) {
+	wait, err := server.logins.Failure(ctx, username, ctx.ClientIP())
 	if err != nil {
-		if err == jwt.ErrSignatureInvalid {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return err, ""
-		}
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return err, ""
+		log.Printf("cannot record failed login: %v", err)
 	}
-
-	claims := tokenParse.Claims.(*MyCustomClaims)
-
-	if !tokenParse.Valid {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token is invalid"})
-		return &gin.Error{}, ""
+	if wait > 0 {
+		setRetryAfter(ctx, wait)
 	}
-
-	ctx.Next()
-	return nil, claims.Username
-
+	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 }
 
-type UserClaims struct {
-	UserID   int32
-	UserName string
+// setRetryAfter sets the Retry-After header to wait, in whole seconds
+// rounded up.
+func setRetryAfter(ctx *gin.Context, wait time.Duration) {
+	seconds := (wait + time.Second - 1) / time.Second
+	ctx.Header("Retry-After", strconv.FormatInt(int64(seconds), 10))
 }
 
-func (server *Server) GetTokenInHeaderAndVerify(ctx *gin.Context) *UserClaims {
-	authorizationHeaderKey := ctx.GetHeader("Authorization")
-	fields := strings.Fields(authorizationHeaderKey)
-
-	if len(fields) < 2 {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return nil
+// rehashPassword replaces the stored hash of user, known to match password,
+// with one in the current format. Failing to do so does not fail the login;
+// it is tried again next time.
+func (server *Server) rehashPassword(ctx *gin.Context, user db.User, password string) {
+	hash, err := server.passwords.Hash(password)
+	if err == nil {
+		_, err = server.store.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+			ID:       user.ID,
+			Password: hash,
+		})
 	}
-
-	tokenToValidade := fields[1]
-	err, username := validateToken(ctx, tokenToValidade)
-	if err != nil || username == "" {
-		return nil
+	if err != nil {
+		log.Printf("cannot rehash password of user %d: %v", user.ID, err)
 	}
+}
 
-	user, err := server.store.GetUser(ctx, username)
+// validateToken checks the signature and expiry of a token and returns its
+// claims.
+func validateToken(keyring *token.Keyring, tokenString string) (*MyCustomClaims, error) {
+	claims := &MyCustomClaims{}
+	err := keyring.Parse(tokenString, claims)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-
-	return &UserClaims{
-		UserID:   user.ID,
-		UserName: user.Username,
+	if claims.Purpose != "" {
+		return nil, errWrongTokenPurpose
+	}
+	if claims.UserID == 0 {
+		return nil, errMissingUserID
 	}
 
+	return claims, nil
+}
+
+// getJWKS publishes the public keys that verify our tokens.
+func (server *Server) getJWKS(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, server.keyring.JWKS())
+}
+
+type UserClaims struct {
+	UserID   int32
+	UserName string
+	// Role is the role of the user when the token was issued; tokens issued
+	// before roles existed carry none.
+	Role string
+	// Scopes limit what a request made with an API key may do. They are nil
+	// for access tokens, which may do anything the user can.
+	Scopes []string
+	// LedgerID is the ledger a data request works in and LedgerRole the role
+	// of the user in it. They are set by ledgerMiddleware on data routes.
+	LedgerID   int32
+	LedgerRole string
+}
+
+// hasScope reports whether the claims allow what scope stands for.
+func (claims *UserClaims) hasScope(scope string) bool {
+	return claims.Scopes == nil || contains(claims.Scopes, scope)
 }
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	require.NotEmpty(t, user.Token)
+
+	recorder := ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": user.Username,
+		"password": "wrong-password",
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": "nobody",
+		"password": user.PlainPassword,
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/login", gin.H{}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAuthenticatedRoutesRequireToken(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodGet, "/categories?type=debit", nil, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, "not-a-jwt")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestJWKSAPI(t *testing.T) {
+	ts := newTestServer(t)
+
+	// The test server signs with HS256, whose secret must never be published.
+	recorder := ts.request(t, http.MethodGet, "/.well-known/jwks.json", nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"keys":[]}`, recorder.Body.String())
+}
+
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+	ts := newTestServer(t)
+
+	// Hashes made before argon2id: bcrypt over a SHA-512/256 digest.
+	plain := "legacy-" + util.RandomString(12)
+	digest := sha512.Sum512_256([]byte(plain))
+	legacy, err := bcrypt.GenerateFromPassword(bytes.Trim(digest[:], "\x00"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	user, err := ts.store.CreateUser(context.Background(), db.CreateUserParams{
+		Username: util.RandomString(10),
+		Password: string(legacy),
+		Email:    util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, plain))
+
+	stored, err := ts.store.GetUserById(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(stored.Password, "$argon2id$"))
+
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, plain))
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, "wrong-password"))
+}
+
+func TestLoginThrottleAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	wrong := gin.H{"username": user.Username, "password": "wrong-password"}
+
+	for i := 1; i < 5; i++ {
+		recorder := ts.request(t, http.MethodPost, "/login", wrong, "")
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+		require.Empty(t, recorder.Header().Get("Retry-After"))
+	}
+
+	// The fifth failure holds the next attempt back.
+	recorder := ts.request(t, http.MethodPost, "/login", wrong, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+
+	// Even the right password has to wait, whatever its case.
+	recorder = ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": strings.ToUpper(user.Username),
+		"password": user.PlainPassword,
+	}, "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+
+	require.Equal(t, http.StatusOK, ts.login(t, other.Username, other.PlainPassword))
+}
+
+func TestLoginThrottleIgnoresForgedForwardedForAPI(t *testing.T) {
+	configure := func(proxies ...string) func(*util.Config) {
+		return func(config *util.Config) {
+			config.TrustedProxies = proxies
+			config.LoginThrottle.IPFreeAttempts = 2
+			config.LoginThrottle.IPLockoutAttempts = 3
+		}
+	}
+	loginFrom := func(ts *testServer, remoteAddr, forwardedFor string) int {
+		req, err := http.NewRequest(http.MethodPost, "/login", strings.NewReader(
+			`{"username": "`+util.RandomString(8)+`", "password": "wrong-password"}`,
+		))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = remoteAddr + ":40000"
+		return ts.serve(t, req).Code
+	}
+
+	// A direct client cannot pass for a new IP with every attempt.
+	ts := newTestServerWithConfig(t, configure())
+	require.Equal(t, http.StatusUnauthorized, loginFrom(ts, "10.0.0.9", "203.0.113.1"))
+	require.Equal(t, http.StatusUnauthorized, loginFrom(ts, "10.0.0.9", "203.0.113.2"))
+	require.Equal(t, http.StatusTooManyRequests, loginFrom(ts, "10.0.0.9", "203.0.113.3"))
+
+	// Behind a trusted proxy the header tells the clients apart.
+	ts = newTestServerWithConfig(t, configure("10.0.0.1"))
+	require.Equal(t, http.StatusUnauthorized, loginFrom(ts, "10.0.0.1", "203.0.113.1"))
+	require.Equal(t, http.StatusUnauthorized, loginFrom(ts, "10.0.0.1", "203.0.113.2"))
+	require.Equal(t, http.StatusUnauthorized, loginFrom(ts, "10.0.0.1", "203.0.113.3"))
+}
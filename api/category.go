@@ -1,7 +1,6 @@
 package api
 
 import (
-	"database/sql"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -15,10 +14,7 @@ type createCategoryRequest struct {
 }
 
 func (server *Server) createCategory(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req createCategoryRequest
 	err := ctx.ShouldBindJSON(&req)
@@ -27,11 +23,15 @@ func (server *Server) createCategory(ctx *gin.Context) {
 		return
 	}
 
+	if !server.authorizeLedgerWrite(ctx, userClaims) {
+		return
+	}
+
 	arg := db.CreateCategoryParams{
 		Title:       req.Title,
 		Type:        req.Type,
 		Description: req.Description,
-		UserID:      userClaims.UserID,
+		LedgerID:    userClaims.LedgerID,
 	}
 
 	cat, err := server.store.CreateCategory(ctx, arg)
@@ -48,10 +48,7 @@ type getCategoryRequest struct {
 }
 
 func (server *Server) getCategory(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req getCategoryRequest
 	err := ctx.ShouldBindUri(&req)
@@ -60,28 +57,30 @@ func (server *Server) getCategory(ctx *gin.Context) {
 		return
 	}
 
-	cat, err := server.store.GetCategory(ctx, req.ID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	cat, ok := server.getUserCategory(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
 		return
 	}
 
 	ctx.JSON(http.StatusOK, cat)
 }
 
+// getUserCategory loads a category of the ledger of the request and checks
+// the policy for action. It returns false when a response was written.
+func (server *Server) getUserCategory(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.Category, bool) {
+	cat, err := server.store.GetCategory(ctx, db.GetCategoryParams{
+		ID:       id,
+		LedgerID: userClaims.LedgerID,
+	})
+	return cat, server.authorizeLedgerRow(ctx, userClaims, action, cat.LedgerID, err)
+}
+
 type deleteCategoryRequest struct {
 	ID int32 `uri:"id" binding:"required"`
 }
 
 func (server *Server) deleteCategory(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req deleteCategoryRequest
 	err := ctx.ShouldBindUri(&req)
@@ -90,7 +89,15 @@ func (server *Server) deleteCategory(ctx *gin.Context) {
 		return
 	}
 
-	err = server.store.DeleteCategory(ctx, req.ID)
+	cat, ok := server.getUserCategory(ctx, userClaims, req.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeleteCategory(ctx, db.DeleteCategoryParams{
+		ID:       cat.ID,
+		LedgerID: userClaims.LedgerID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -108,10 +115,7 @@ type updateCategoryRequest struct {
 }
 
 func (server *Server) updateCategory(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var reqUri updateCategoryIdRequest
 
@@ -128,13 +132,19 @@ func (server *Server) updateCategory(ctx *gin.Context) {
 		return
 	}
 
+	cat, ok := server.getUserCategory(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
 	arg := db.UpdateCategoriesParams{
-		ID:          reqUri.ID,
+		ID:          cat.ID,
 		Title:       reqBody.Title,
 		Description: reqBody.Description,
+		LedgerID:    userClaims.LedgerID,
 	}
 
-	cat, err := server.store.UpdateCategories(ctx, arg)
+	cat, err = server.store.UpdateCategories(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
@@ -150,10 +160,7 @@ type listCategoriesRequest struct {
 }
 
 func (server *Server) getCategories(ctx *gin.Context) {
-	userClaims := server.GetTokenInHeaderAndVerify(ctx)
-	if userClaims == nil {
-		return
-	}
+	userClaims := getUserClaims(ctx)
 
 	var req listCategoriesRequest
 	err := ctx.ShouldBindQuery(&req)
@@ -163,7 +170,7 @@ func (server *Server) getCategories(ctx *gin.Context) {
 	}
 
 	arg := db.GetCategoriesParams{
-		UserID:      userClaims.UserID,
+		LedgerID:    userClaims.LedgerID,
 		Type:        req.Type,
 		Title:       req.Title,
 		Description: req.Description,
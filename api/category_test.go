@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	cat := ts.createCategory(t, user, "debit")
+	require.Equal(t, ts.personalLedger(t, user).ID, cat.LedgerID)
+	require.Equal(t, "debit", cat.Type)
+
+	url := fmt.Sprintf("/category/%d", cat.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, cat, decodeBody[db.Category](t, recorder))
+
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"title":       "Groceries",
+		"description": "Supermarket",
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	updated := decodeBody[db.Category](t, recorder)
+	require.Equal(t, "Groceries", updated.Title)
+	require.Equal(t, cat.Type, updated.Type)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestListCategoriesAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	debit := ts.createCategory(t, user, "debit")
+	ts.createCategory(t, user, "debit")
+	ts.createCategory(t, user, "credit")
+
+	recorder := ts.request(t, http.MethodGet, "/categories?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, decodeBody[[]db.Category](t, recorder), 2)
+
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit&title="+debit.Title[2:6], nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	cats := decodeBody[[]db.Category](t, recorder)
+	require.Len(t, cats, 1)
+	require.Equal(t, debit.ID, cats[0].ID)
+
+	recorder = ts.request(t, http.MethodGet, "/categories", nil, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestDeleteCategoryInUseAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+	ts.createAccount(t, user, cat, 100)
+
+	recorder := ts.request(t, http.MethodDelete, fmt.Sprintf("/category/%d", cat.ID), nil, user.Token)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
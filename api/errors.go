@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+var (
+	errUsernameTaken = errors.New("username is already taken")
+	errEmailTaken    = errors.New("email is already in use")
+)
+
+// uniqueViolationErrors explains the unique constraints a client can run
+// into.
+var uniqueViolationErrors = map[string]error{
+	"users_email_key":          errEmailTaken,
+	"users_username_lower_idx": errUsernameTaken,
+}
+
+// storeError writes the response for an error returned by the store: 409 for
+// unique violations, which the request can be fixed for, and 500 otherwise.
+func storeError(ctx *gin.Context, err error) {
+	if constraint, ok := db.UniqueViolation(err); ok {
+		if known, ok := uniqueViolationErrors[constraint]; ok {
+			err = known
+		}
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+}
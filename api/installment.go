@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+// errInstallmentTotalTooLow keeps purchases from being split into installments
+// worth nothing.
+var errInstallmentTotalTooLow = errors.New("total_value must be at least installment_count")
+
+type createInstallmentsRequest struct {
+	Title            string    `json:"title" binding:"required"`
+	Type             string    `json:"type" binding:"required"`
+	Description      string    `json:"description" binding:"required"`
+	CategoryID       int32     `json:"category_id" binding:"required"`
+	TotalValue       int32     `json:"total_value" binding:"required,min=1"`
+	InstallmentCount int32     `json:"installment_count" binding:"required,min=1,max=360"`
+	FirstDate        time.Time `json:"first_date" binding:"required"`
+}
+
+func (server *Server) createInstallments(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req createInstallmentsRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.TotalValue < req.InstallmentCount {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInstallmentTotalTooLow))
+		return
+	}
+
+	cat, ok := server.getUserCategory(ctx, userClaims, req.CategoryID, ActionWrite)
+	if !ok {
+		return
+	}
+	if cat.Type != req.Type {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error:": "Account type is different of category type"})
+		return
+	}
+
+	arg := db.CreateInstallmentsTxParams{
+		LedgerID:         userClaims.LedgerID,
+		CategoryID:       req.CategoryID,
+		Title:            req.Title,
+		Type:             req.Type,
+		Description:      req.Description,
+		TotalValue:       req.TotalValue,
+		InstallmentCount: req.InstallmentCount,
+		FirstDate:        req.FirstDate,
+	}
+
+	result, err := server.store.CreateInstallmentsTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type getInstallmentsRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) getInstallments(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req getInstallmentsRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	group, ok := server.getUserInstallmentGroup(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	accs, err := server.store.GetInstallmentAccounts(ctx, sql.NullInt32{
+		Int32: group.ID,
+		Valid: true,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.InstallmentsTxResult{
+		Group:    group,
+		Accounts: accs,
+	})
+}
+
+// getUserInstallmentGroup loads an installment group of the ledger of the
+// request and checks the policy for action. It returns false when a response
+// was written.
+func (server *Server) getUserInstallmentGroup(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.InstallmentGroup, bool) {
+	group, err := server.store.GetInstallmentGroup(ctx, db.GetInstallmentGroupParams{
+		ID:       id,
+		LedgerID: userClaims.LedgerID,
+	})
+	return group, server.authorizeLedgerRow(ctx, userClaims, action, group.LedgerID, err)
+}
+
+type updateInstallmentsIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type updateInstallmentsRequest struct {
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description" binding:"required"`
+	TotalValue  int32  `json:"total_value" binding:"required,min=1"`
+}
+
+func (server *Server) updateInstallments(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri updateInstallmentsIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody updateInstallmentsRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	group, ok := server.getUserInstallmentGroup(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok {
+		return
+	}
+	if reqBody.TotalValue < group.InstallmentCount {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInstallmentTotalTooLow))
+		return
+	}
+
+	arg := db.UpdateInstallmentsTxParams{
+		ID:          reqUri.ID,
+		Title:       reqBody.Title,
+		Description: reqBody.Description,
+		TotalValue:  reqBody.TotalValue,
+		LedgerID:    userClaims.LedgerID,
+	}
+
+	result, err := server.store.UpdateInstallmentsTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type deleteInstallmentsRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) deleteInstallments(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteInstallmentsRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserInstallmentGroup(ctx, userClaims, req.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeleteInstallmentGroup(ctx, db.DeleteInstallmentGroupParams{
+		ID:       req.ID,
+		LedgerID: userClaims.LedgerID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
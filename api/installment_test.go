@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallmentsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+
+	recorder := ts.request(t, http.MethodPost, "/account/installments", gin.H{
+		"title":             "Laptop",
+		"type":              "debit",
+		"description":       "Store",
+		"category_id":       cat.ID,
+		"total_value":       100000,
+		"installment_count": 12,
+		"first_date":        time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC),
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	created := decodeBody[db.InstallmentsTxResult](t, recorder)
+
+	require.Len(t, created.Accounts, 12)
+	require.Equal(t, "Laptop 3/12", created.Accounts[2].Title)
+	require.Equal(t, int32(8333), created.Accounts[0].Value)
+	require.Equal(t, int32(8337), created.Accounts[11].Value)
+	require.Equal(t, time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC), created.Accounts[1].Date)
+
+	url := fmt.Sprintf("/account/installments/%d", created.Group.ID)
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, decodeBody[db.InstallmentsTxResult](t, recorder).Accounts, 12)
+
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"title":       "Notebook",
+		"description": "Store",
+		"total_value": 1200,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	updated := decodeBody[db.InstallmentsTxResult](t, recorder)
+	require.Equal(t, "Notebook 12/12", updated.Accounts[11].Title)
+	require.Equal(t, int32(100), updated.Accounts[5].Value)
+
+	// Single installments only change through their group.
+	accountURL := fmt.Sprintf("/account/%d", created.Accounts[0].ID)
+	recorder = ts.request(t, http.MethodPut, accountURL, gin.H{
+		"title":       "Laptop 1/12",
+		"description": "Store",
+		"value":       1,
+	}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodDelete, accountURL, nil, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Len(t, decodeBody[db.InstallmentsTxResult](t, recorder).Accounts, 12)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/account/%d", created.Accounts[0].ID), nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestInstallmentsTotalBelowCountAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+	purchase := gin.H{
+		"title":             "Laptop",
+		"type":              "debit",
+		"description":       "Store",
+		"category_id":       cat.ID,
+		"total_value":       3,
+		"installment_count": 12,
+		"first_date":        time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	// Nine of the installments would be worth nothing.
+	recorder := ts.request(t, http.MethodPost, "/account/installments", purchase, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	purchase["total_value"] = 12
+	recorder = ts.request(t, http.MethodPost, "/account/installments", purchase, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	created := decodeBody[db.InstallmentsTxResult](t, recorder)
+	require.Equal(t, int32(1), created.Accounts[11].Value)
+
+	url := fmt.Sprintf("/account/installments/%d", created.Group.ID)
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"title":       "Laptop",
+		"description": "Store",
+		"total_value": 11,
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
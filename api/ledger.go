@@ -0,0 +1,567 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/token"
+)
+
+const (
+	// ledgerIDHeader names the ledger a data request works in. Without it,
+	// requests work in the personal ledger of the user.
+	ledgerIDHeader = "X-Ledger-ID"
+
+	ledgerInvitationSubject = "You were invited to a shared ledger"
+)
+
+var (
+	errInvalidLedgerID  = errors.New("invalid " + ledgerIDHeader + " header")
+	errNotLedgerOwner   = errors.New("only the owner of the ledger may do this")
+	errPersonalLedger   = errors.New("personal ledgers cannot be shared or deleted")
+	errOwnerRole        = errors.New("the role of the owner cannot be changed")
+	errOwnerCannotLeave = errors.New("the owner cannot leave the ledger, delete it instead")
+)
+
+// ledgerMiddleware picks the ledger a data request works in and stores it
+// with the role of the user in the claims. Ledgers the user is not a member
+// of answer 404, like the rows of other users.
+func (server *Server) ledgerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userClaims := getUserClaims(ctx)
+
+		var member db.LedgerMember
+		var err error
+		if header := ctx.GetHeader(ledgerIDHeader); header != "" {
+			id, parseErr := strconv.ParseInt(header, 10, 32)
+			if parseErr != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(errInvalidLedgerID))
+				return
+			}
+			member, err = server.store.GetLedgerMember(ctx, db.GetLedgerMemberParams{
+				LedgerID: int32(id),
+				UserID:   userClaims.UserID,
+			})
+		} else {
+			var ledger db.Ledger
+			ledger, err = server.store.GetPersonalLedger(ctx, userClaims.UserID)
+			member = db.LedgerMember{LedgerID: ledger.ID, UserID: userClaims.UserID, Role: db.LedgerRoleOwner}
+		}
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		userClaims.LedgerID = member.LedgerID
+		userClaims.LedgerRole = member.Role
+		ctx.Next()
+	}
+}
+
+type ledgerResponse struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   int32     `json:"owner_id"`
+	Personal  bool      `json:"personal"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+	// Members is only filled in when a single ledger is requested.
+	Members []db.ListLedgerMembersRow `json:"members,omitempty"`
+}
+
+func newLedgerResponse(ledger db.Ledger, role string) ledgerResponse {
+	return ledgerResponse{
+		ID:        ledger.ID,
+		Name:      ledger.Name,
+		OwnerID:   ledger.OwnerID,
+		Personal:  ledger.Personal,
+		Role:      role,
+		CreatedAt: ledger.CreatedAt,
+	}
+}
+
+type ledgerURIRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+// getMemberLedger loads a ledger the authenticated user is a member of. Other
+// ledgers answer 404; when ownerOnly is set, members other than the owner get
+// 403. It returns false when a response was written.
+func (server *Server) getMemberLedger(ctx *gin.Context, userClaims *UserClaims, id int32, ownerOnly bool) (db.Ledger, db.LedgerMember, bool) {
+	member, err := server.store.GetLedgerMember(ctx, db.GetLedgerMemberParams{
+		LedgerID: id,
+		UserID:   userClaims.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return db.Ledger{}, member, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return db.Ledger{}, member, false
+	}
+	if ownerOnly && member.Role != db.LedgerRoleOwner {
+		ctx.JSON(http.StatusForbidden, errorResponse(errNotLedgerOwner))
+		return db.Ledger{}, member, false
+	}
+
+	ledger, err := server.store.GetLedger(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return ledger, member, false
+	}
+	return ledger, member, true
+}
+
+type ledgerNameRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// createLedger creates a ledger to share, owned by the authenticated user.
+func (server *Server) createLedger(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req ledgerNameRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, err := server.store.CreateLedgerTx(ctx, db.CreateLedgerTxParams{
+		Name:    req.Name,
+		OwnerID: userClaims.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newLedgerResponse(ledger, db.LedgerRoleOwner))
+}
+
+// getLedgers lists the ledgers the authenticated user is a member of, the
+// personal ledger first, with their role in each.
+func (server *Server) getLedgers(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	ledgers, err := server.store.ListUserLedgers(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]ledgerResponse, len(ledgers))
+	for i, ledger := range ledgers {
+		rsp[i] = ledgerResponse{
+			ID:        ledger.ID,
+			Name:      ledger.Name,
+			OwnerID:   ledger.OwnerID,
+			Personal:  ledger.Personal,
+			Role:      ledger.Role,
+			CreatedAt: ledger.CreatedAt,
+		}
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// getLedger shows a ledger with its members.
+func (server *Server) getLedger(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req ledgerURIRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, member, ok := server.getMemberLedger(ctx, userClaims, req.ID, false)
+	if !ok {
+		return
+	}
+
+	members, err := server.store.ListLedgerMembers(ctx, ledger.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := newLedgerResponse(ledger, member.Role)
+	rsp.Members = members
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) updateLedger(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri ledgerURIRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody ledgerNameRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, member, ok := server.getMemberLedger(ctx, userClaims, reqUri.ID, true)
+	if !ok {
+		return
+	}
+
+	ledger, err = server.store.UpdateLedger(ctx, db.UpdateLedgerParams{
+		ID:   ledger.ID,
+		Name: reqBody.Name,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newLedgerResponse(ledger, member.Role))
+}
+
+// deleteLedger removes a shared ledger with all of its data. Personal ledgers
+// only go with their user.
+func (server *Server) deleteLedger(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req ledgerURIRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, req.ID, true)
+	if !ok {
+		return
+	}
+	if ledger.Personal {
+		ctx.JSON(http.StatusConflict, errorResponse(errPersonalLedger))
+		return
+	}
+
+	err = server.store.DeleteLedgerTx(ctx, ledger.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type ledgerInvitationResponse struct {
+	ID        int32     `json:"id"`
+	LedgerID  int32     `json:"ledger_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy *int32    `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newLedgerInvitationResponse(invitation db.LedgerInvitation) ledgerInvitationResponse {
+	rsp := ledgerInvitationResponse{
+		ID:        invitation.ID,
+		LedgerID:  invitation.LedgerID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+	if invitation.InvitedBy.Valid {
+		rsp.InvitedBy = &invitation.InvitedBy.Int32
+	}
+	return rsp
+}
+
+type createLedgerInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+// createLedgerInvitation mails an invitation to join a shared ledger with
+// role. Only the user the email belongs to can accept it.
+func (server *Server) createLedgerInvitation(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri ledgerURIRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody createLedgerInvitationRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, reqUri.ID, true)
+	if !ok {
+		return
+	}
+	if ledger.Personal {
+		ctx.JSON(http.StatusConflict, errorResponse(errPersonalLedger))
+		return
+	}
+
+	invitationToken, err := token.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	invitation, err := server.store.CreateLedgerInvitation(ctx, db.CreateLedgerInvitationParams{
+		LedgerID:  ledger.ID,
+		Email:     reqBody.Email,
+		Role:      reqBody.Role,
+		TokenHash: token.HashOpaqueToken(invitationToken),
+		InvitedBy: sql.NullInt32{Int32: userClaims.UserID, Valid: true},
+		ExpiresAt: time.Now().Add(server.config.LedgerInvitationDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.sendInBackground(mail.Message{
+		To:      invitation.Email,
+		Subject: ledgerInvitationSubject,
+		Body: fmt.Sprintf("Hi,\n\n%s invited you to the ledger %q as %s. Use the link below to join it. It expires in %s.\n\n%s\n",
+			userClaims.UserName, ledger.Name, invitation.Role, server.config.LedgerInvitationDuration,
+			tokenLink(server.config.LedgerInvitationURL, invitationToken)),
+	})
+
+	ctx.JSON(http.StatusOK, newLedgerInvitationResponse(invitation))
+}
+
+// getLedgerInvitations lists the invitations of a ledger that can still be
+// accepted.
+func (server *Server) getLedgerInvitations(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req ledgerURIRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, req.ID, true)
+	if !ok {
+		return
+	}
+
+	invitations, err := server.store.ListLedgerInvitations(ctx, ledger.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]ledgerInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		rsp[i] = newLedgerInvitationResponse(invitation)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type deleteLedgerInvitationRequest struct {
+	ID           int32 `uri:"id" binding:"required"`
+	InvitationID int32 `uri:"invitation_id" binding:"required"`
+}
+
+// deleteLedgerInvitation withdraws an invitation, so its link stops working.
+func (server *Server) deleteLedgerInvitation(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteLedgerInvitationRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, req.ID, true)
+	if !ok {
+		return
+	}
+
+	_, err = server.store.DeleteLedgerInvitation(ctx, db.DeleteLedgerInvitationParams{
+		ID:       req.InvitationID,
+		LedgerID: ledger.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type acceptLedgerInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// acceptLedgerInvitation makes the authenticated user a member of the ledger
+// they were invited to. The invitation must have been sent to their email,
+// which they must have verified, so nobody can take it over by signing up
+// with the address.
+func (server *Server) acceptLedgerInvitation(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req acceptLedgerInvitationRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok {
+		return
+	}
+	if !user.EmailVerifiedAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errEmailNotVerified))
+		return
+	}
+
+	member, err := server.store.AcceptLedgerInvitationTx(ctx, db.AcceptLedgerInvitationTxParams{
+		TokenHash: token.HashOpaqueToken(req.Token),
+		UserID:    userClaims.UserID,
+		Email:     user.Email,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInvitationInvalid):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrInvitationEmailMismatch):
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+		case errors.Is(err, db.ErrAlreadyLedgerMember):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ledger, err := server.store.GetLedger(ctx, member.LedgerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newLedgerResponse(ledger, member.Role))
+}
+
+type ledgerMemberURIRequest struct {
+	ID     int32 `uri:"id" binding:"required"`
+	UserID int32 `uri:"user_id" binding:"required"`
+}
+
+type updateLedgerMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=editor viewer"`
+}
+
+func (server *Server) updateLedgerMember(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri ledgerMemberURIRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody updateLedgerMemberRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, reqUri.ID, true)
+	if !ok {
+		return
+	}
+	if reqUri.UserID == ledger.OwnerID {
+		ctx.JSON(http.StatusConflict, errorResponse(errOwnerRole))
+		return
+	}
+
+	member, err := server.store.UpdateLedgerMemberRole(ctx, db.UpdateLedgerMemberRoleParams{
+		LedgerID: ledger.ID,
+		UserID:   reqUri.UserID,
+		Role:     reqBody.Role,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// removeLedgerMember lets the owner remove a member, and members leave a
+// ledger by removing themselves. What they added stays in the ledger.
+func (server *Server) removeLedgerMember(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req ledgerMemberURIRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	leaving := req.UserID == userClaims.UserID
+	ledger, _, ok := server.getMemberLedger(ctx, userClaims, req.ID, !leaving)
+	if !ok {
+		return
+	}
+	if req.UserID == ledger.OwnerID {
+		ctx.JSON(http.StatusConflict, errorResponse(errOwnerCannotLeave))
+		return
+	}
+
+	_, err = server.store.RemoveLedgerMember(ctx, db.RemoveLedgerMemberParams{
+		LedgerID: ledger.ID,
+		UserID:   req.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
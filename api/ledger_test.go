@@ -0,0 +1,295 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+var invitationLinkPattern = regexp.MustCompile(`https://app\.example\.com/invitation\?\S+`)
+
+func (ts *testServer) personalLedger(t *testing.T, user testUser) db.Ledger {
+	ledger, err := ts.store.GetPersonalLedger(context.Background(), user.ID)
+	require.NoError(t, err)
+	return ledger
+}
+
+// ledgerRequest is request in the ledger ledgerID.
+func (ts *testServer) ledgerRequest(t *testing.T, method, url string, body any, user testUser, ledgerID int32) *httptest.ResponseRecorder {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+user.Token)
+	req.Header.Set(ledgerIDHeader, strconv.Itoa(int(ledgerID)))
+	return ts.serve(t, req)
+}
+
+func (ts *testServer) createLedger(t *testing.T, owner testUser) ledgerResponse {
+	recorder := ts.request(t, http.MethodPost, "/ledgers", gin.H{"name": "Household"}, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[ledgerResponse](t, recorder)
+}
+
+// invite invites user to ledgerID as role and returns the token of the
+// invitation email.
+func (ts *testServer) invite(t *testing.T, owner testUser, ledgerID int32, user testUser, role string) string {
+	recorder := ts.request(t, http.MethodPost, fmt.Sprintf("/ledger/%d/invitations", ledgerID), gin.H{
+		"email": user.Email,
+		"role":  role,
+	}, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	msg := ts.outbox.next(t, user.Email, ledgerInvitationSubject)
+	link, err := url.Parse(invitationLinkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	invitationToken := link.Query().Get("token")
+	require.NotEmpty(t, invitationToken)
+	return invitationToken
+}
+
+func (ts *testServer) acceptInvitation(t *testing.T, user testUser, invitationToken string) *httptest.ResponseRecorder {
+	return ts.request(t, http.MethodPost, "/ledgers/invitations/accept", gin.H{"token": invitationToken}, user.Token)
+}
+
+// createVerifiedUser signs up a user whose email is verified, as invitations
+// can only be accepted by those.
+func (ts *testServer) createVerifiedUser(t *testing.T) testUser {
+	user := ts.createUserAndLogin(t)
+	_, err := ts.store.MarkUserEmailVerified(context.Background(), user.ID)
+	require.NoError(t, err)
+	return user
+}
+
+// shareLedger creates a ledger of owner with member in it as role.
+func (ts *testServer) shareLedger(t *testing.T, owner, member testUser, role string) ledgerResponse {
+	ledger := ts.createLedger(t, owner)
+	recorder := ts.acceptInvitation(t, member, ts.invite(t, owner, ledger.ID, member, role))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return ledger
+}
+
+func TestLedgerInvitationAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	member := ts.createVerifiedUser(t)
+	ledger := ts.createLedger(t, owner)
+	require.False(t, ledger.Personal)
+	require.Equal(t, db.LedgerRoleOwner, ledger.Role)
+
+	invitationToken := ts.invite(t, owner, ledger.ID, member, db.LedgerRoleEditor)
+
+	recorder := ts.request(t, http.MethodGet, fmt.Sprintf("/ledger/%d/invitations", ledger.ID), nil, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	invitations := decodeBody[[]ledgerInvitationResponse](t, recorder)
+	require.Len(t, invitations, 1)
+	require.Equal(t, member.Email, invitations[0].Email)
+	require.Equal(t, owner.ID, *invitations[0].InvitedBy)
+
+	// The invitation is for the email it was sent to only.
+	other := ts.createVerifiedUser(t)
+	recorder = ts.acceptInvitation(t, other, invitationToken)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.acceptInvitation(t, member, invitationToken)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	accepted := decodeBody[ledgerResponse](t, recorder)
+	require.Equal(t, ledger.ID, accepted.ID)
+	require.Equal(t, db.LedgerRoleEditor, accepted.Role)
+
+	recorder = ts.acceptInvitation(t, member, invitationToken)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = ts.acceptInvitation(t, member, "unknown")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/ledgers", nil, member.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	ledgers := decodeBody[[]ledgerResponse](t, recorder)
+	require.Len(t, ledgers, 2)
+	require.True(t, ledgers[0].Personal)
+	require.Equal(t, ledger.ID, ledgers[1].ID)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/ledger/%d", ledger.ID), nil, member.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, decodeBody[ledgerResponse](t, recorder).Members, 2)
+
+	// Inviting an existing member again is useless.
+	recorder = ts.acceptInvitation(t, member, ts.invite(t, owner, ledger.ID, member, db.LedgerRoleViewer))
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestLedgerInvitationRulesAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	unverified := ts.createUserAndLogin(t)
+	ledger := ts.createLedger(t, owner)
+
+	recorder := ts.acceptInvitation(t, unverified, ts.invite(t, owner, ledger.ID, unverified, db.LedgerRoleViewer))
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	personal := ts.personalLedger(t, owner)
+	invite := gin.H{"email": unverified.Email, "role": db.LedgerRoleViewer}
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/ledger/%d/invitations", personal.ID), invite, owner.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	invite["role"] = db.LedgerRoleOwner
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/ledger/%d/invitations", ledger.ID), invite, owner.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Only members see a ledger, and only the owner manages it.
+	invite["role"] = db.LedgerRoleViewer
+	recorder = ts.request(t, http.MethodPost, fmt.Sprintf("/ledger/%d/invitations", ledger.ID), invite, unverified.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestLedgerScopesDataAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	editor := ts.createVerifiedUser(t)
+	outsider := ts.createUserAndLogin(t)
+	ledger := ts.shareLedger(t, owner, editor, db.LedgerRoleEditor)
+
+	recorder := ts.ledgerRequest(t, http.MethodPost, "/category", gin.H{
+		"title":       "Groceries",
+		"type":        "debit",
+		"description": "Shared",
+	}, editor, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	cat := decodeBody[db.Category](t, recorder)
+	require.Equal(t, ledger.ID, cat.LedgerID)
+
+	url := fmt.Sprintf("/category/%d", cat.ID)
+	recorder = ts.ledgerRequest(t, http.MethodGet, url, nil, owner, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// Without the header requests work in the personal ledger.
+	recorder = ts.request(t, http.MethodGet, url, nil, owner.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, editor.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, decodeBody[[]db.Category](t, recorder))
+
+	recorder = ts.ledgerRequest(t, http.MethodGet, url, nil, outsider, ledger.ID)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.ledgerRequest(t, http.MethodGet, "/categories?type=debit", nil, outsider, ts.personalLedger(t, owner).ID)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	req, err := http.NewRequest(http.MethodGet, "/categories?type=debit", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+owner.Token)
+	req.Header.Set(ledgerIDHeader, "shared")
+	require.Equal(t, http.StatusBadRequest, ts.serve(t, req).Code)
+}
+
+func TestLedgerViewerAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	viewer := ts.createVerifiedUser(t)
+	ledger := ts.shareLedger(t, owner, viewer, db.LedgerRoleViewer)
+
+	recorder := ts.ledgerRequest(t, http.MethodPost, "/category", gin.H{
+		"title":       "Rent",
+		"type":        "debit",
+		"description": "Flat",
+	}, owner, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	cat := decodeBody[db.Category](t, recorder)
+
+	url := fmt.Sprintf("/category/%d", cat.ID)
+	recorder = ts.ledgerRequest(t, http.MethodGet, url, nil, viewer, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.ledgerRequest(t, http.MethodDelete, url, nil, viewer, ledger.ID)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.ledgerRequest(t, http.MethodPost, "/category", gin.H{
+		"title":       "Food",
+		"type":        "debit",
+		"description": "Lunch",
+	}, viewer, ledger.ID)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.ledgerRequest(t, http.MethodPost, "/account", gin.H{
+		"title":       "May",
+		"type":        "debit",
+		"description": "Rent",
+		"category_id": cat.ID,
+		"date":        "2023-05-10T00:00:00Z",
+		"value":       1000,
+	}, viewer, ledger.ID)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Promoted to editor, the member may change the ledger.
+	memberURL := fmt.Sprintf("/ledger/%d/members/%d", ledger.ID, viewer.ID)
+	recorder = ts.request(t, http.MethodPut, memberURL, gin.H{"role": db.LedgerRoleEditor}, viewer.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.request(t, http.MethodPut, memberURL, gin.H{"role": db.LedgerRoleEditor}, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.ledgerRequest(t, http.MethodDelete, url, nil, viewer, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+}
+
+func TestLedgerMembersAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	member := ts.createVerifiedUser(t)
+	ledger := ts.shareLedger(t, owner, member, db.LedgerRoleEditor)
+
+	ownerURL := fmt.Sprintf("/ledger/%d/members/%d", ledger.ID, owner.ID)
+	recorder := ts.request(t, http.MethodPut, ownerURL, gin.H{"role": db.LedgerRoleViewer}, owner.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodDelete, ownerURL, nil, owner.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodDelete, ownerURL, nil, member.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Members leave by removing themselves.
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/ledger/%d/members/%d", ledger.ID, member.ID), nil, member.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.ledgerRequest(t, http.MethodGet, "/categories?type=debit", nil, member, ledger.ID)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/ledger/%d", ledger.ID), nil, member.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestDeleteLedgerAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	member := ts.createVerifiedUser(t)
+	ledger := ts.shareLedger(t, owner, member, db.LedgerRoleEditor)
+
+	recorder := ts.ledgerRequest(t, http.MethodPost, "/category", gin.H{
+		"title":       "Trips",
+		"type":        "debit",
+		"description": "Holidays",
+	}, member, ledger.ID)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	cat := decodeBody[db.Category](t, recorder)
+
+	ledgerURL := fmt.Sprintf("/ledger/%d", ledger.ID)
+	recorder = ts.request(t, http.MethodDelete, ledgerURL, nil, member.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.request(t, http.MethodDelete, fmt.Sprintf("/ledger/%d", ts.personalLedger(t, owner).ID), nil, owner.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, ledgerURL, nil, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	_, err := ts.store.GetCategory(context.Background(), db.GetCategoryParams{ID: cat.ID, LedgerID: ledger.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	recorder = ts.ledgerRequest(t, http.MethodGet, "/categories?type=debit", nil, member, ledger.ID)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
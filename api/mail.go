@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/url"
+
+	"github.com/methyago/gofinance-backend/mail"
+)
+
+// sendInBackground sends msg without making the request wait for the mail
+// server. Failures are only logged: the user can ask for the email again.
+func (server *Server) sendInBackground(msg mail.Message) {
+	go func() {
+		err := server.mailer.Send(context.Background(), msg)
+		if err != nil {
+			log.Printf("cannot send %q email: %v", msg.Subject, err)
+		}
+	}()
+}
+
+// tokenLink points to a page of the client with tok as its token query
+// parameter, or is the bare token when no page is configured.
+func tokenLink(page, tok string) string {
+	link, err := url.Parse(page)
+	if err != nil || page == "" {
+		return tok
+	}
+	query := link.Query()
+	query.Set("token", tok)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
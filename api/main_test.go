@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/password"
+	"github.com/methyago/gofinance-backend/throttle"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+type testServer struct {
+	server Server
+	store  *db.MemStore
+	outbox *testMailer
+}
+
+// testMailer collects the messages the server sends.
+type testMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// take removes and returns the first message sent to with subject.
+func (m *testMailer) take(to, subject string) (mail.Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, msg := range m.messages {
+		if msg.To == to && msg.Subject == subject {
+			m.messages = append(m.messages[:i], m.messages[i+1:]...)
+			return msg, true
+		}
+	}
+	return mail.Message{}, false
+}
+
+// next waits for a message sent to with subject. Emails go out in the
+// background, after the response was written.
+func (m *testMailer) next(t *testing.T, to, subject string) mail.Message {
+	var msg mail.Message
+	require.Eventually(t, func() bool {
+		var ok bool
+		msg, ok = m.take(to, subject)
+		return ok
+	}, time.Second, 5*time.Millisecond, "no %q email was sent to %s", subject, to)
+	return msg
+}
+
+func newTestServer(t *testing.T) *testServer {
+	return newTestServerWithPolicy(t, OwnerPolicy{})
+}
+
+func newTestServerWithPolicy(t *testing.T, policy Policy) *testServer {
+	return newTestServerWith(t, policy, func(*util.Config) {})
+}
+
+// newTestServerWithConfig lets configure change the test configuration
+// before the server is created.
+func newTestServerWithConfig(t *testing.T, configure func(*util.Config)) *testServer {
+	return newTestServerWith(t, OwnerPolicy{}, configure)
+}
+
+func newTestServerWith(t *testing.T, policy Policy, configure func(*util.Config)) *testServer {
+	config := util.Config{
+		Token: util.TokenConfig{
+			Algorithm:       token.AlgorithmHS256,
+			KeyID:           "test",
+			Secret:          util.RandomString(32),
+			Duration:        time.Minute,
+			RefreshDuration: time.Hour,
+		},
+		PasswordResetURL:      "https://app.example.com/reset",
+		PasswordResetDuration: time.Hour,
+
+		EmailVerificationPolicy:   util.EmailVerificationNone,
+		EmailVerificationURL:      "https://app.example.com/verify",
+		EmailVerificationDuration: time.Hour,
+
+		TOTPIssuer:       "GoFinance",
+		MFATokenDuration: time.Minute,
+
+		LedgerInvitationURL:      "https://app.example.com/invitation",
+		LedgerInvitationDuration: time.Hour,
+
+		Password: util.PasswordConfig{Memory: 1024, Iterations: 1, Parallelism: 1},
+		LoginThrottle: util.ThrottleConfig{
+			Store:             throttle.StoreMemory,
+			FreeAttempts:      5,
+			LockoutAttempts:   10,
+			IPFreeAttempts:    50,
+			IPLockoutAttempts: 100,
+			BaseDelay:         time.Second,
+			LockoutDuration:   15 * time.Minute,
+			ResetAfter:        time.Hour,
+		},
+	}
+	configure(&config)
+	keyring, err := token.LoadKeyring(config.Token)
+	require.NoError(t, err)
+
+	// Cheap parameters keep the many logins of the tests fast.
+	passwords, err := password.NewHasher(password.ParamsFromConfig(config.Password))
+	require.NoError(t, err)
+
+	store := db.NewMemStore()
+	logins, err := throttle.New(config.LoginThrottle, store)
+	require.NoError(t, err)
+
+	outbox := &testMailer{}
+	server, err := newServer(config, store, keyring, passwords, logins, outbox, policy)
+	require.NoError(t, err)
+	return &testServer{
+		server: server,
+		store:  store,
+		outbox: outbox,
+	}
+}
+
+// request sends body as JSON (when not nil) with token as bearer credentials
+// (when not empty) and records the response.
+func (ts *testServer) request(t *testing.T, method, url string, body any, token string) *httptest.ResponseRecorder {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return ts.serve(t, req)
+}
+
+// serve records the response to req. Every response of the test suite passes
+// through here and is checked for credentials.
+func (ts *testServer) serve(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	ts.server.router.ServeHTTP(recorder, req)
+	require.Empty(t, credentialFields(recorder.Body.Bytes()), "%s %s leaks credentials", req.Method, req.URL)
+	return recorder
+}
+
+// credentialFields returns the path of every field of a JSON body whose name
+// mentions a password.
+func credentialFields(body []byte) []string {
+	var value any
+	if json.Unmarshal(body, &value) != nil {
+		return nil
+	}
+
+	var fields []string
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, child := range value {
+				if strings.Contains(strings.ToLower(key), "password") {
+					fields = append(fields, path+key)
+				}
+				walk(path+key+".", child)
+			}
+		case []any:
+			for _, child := range value {
+				walk(path, child)
+			}
+		}
+	}
+	walk("", value)
+	return fields
+}
+
+func decodeBody[T any](t *testing.T, recorder *httptest.ResponseRecorder) T {
+	var value T
+	err := json.Unmarshal(recorder.Body.Bytes(), &value)
+	require.NoError(t, err, recorder.Body.String())
+	return value
+}
+
+type testUser struct {
+	db.User
+	PlainPassword string
+	Token         string
+	RefreshToken  string
+}
+
+// createUserAndLogin signs a random user up and logs them in through the API.
+func (ts *testServer) createUserAndLogin(t *testing.T) testUser {
+	password := util.RandomString(12)
+	recorder := ts.request(t, http.MethodPost, "/user", gin.H{
+		"username": util.RandomString(8),
+		"password": password,
+		"email":    util.RandomEmail(),
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	user := decodeBody[db.User](t, recorder)
+
+	return ts.loginAs(t, testUser{User: user, PlainPassword: password})
+}
+
+// loginAs logs user in again, picking up changes to the user such as a new
+// role in the claims of the token.
+func (ts *testServer) loginAs(t *testing.T, user testUser) testUser {
+	recorder := ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": user.Username,
+		"password": user.PlainPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	login := decodeBody[loginResponseStruct](t, recorder)
+
+	user.Token = login.Token
+	user.RefreshToken = login.RefreshToken
+	return user
+}
+
+func (ts *testServer) createCategory(t *testing.T, user testUser, categoryType string) db.Category {
+	recorder := ts.request(t, http.MethodPost, "/category", gin.H{
+		"title":       util.RandomString(10),
+		"type":        categoryType,
+		"description": util.RandomString(20),
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[db.Category](t, recorder)
+}
+
+func (ts *testServer) createWallet(t *testing.T, user testUser, openingBalance int32) db.Wallet {
+	recorder := ts.request(t, http.MethodPost, "/wallet", gin.H{
+		"name":            util.RandomString(8),
+		"type":            "checking",
+		"currency":        "BRL",
+		"opening_balance": openingBalance,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[db.Wallet](t, recorder)
+}
@@ -0,0 +1,428 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/totp"
+)
+
+const (
+	// mfaTokenPurpose marks the challenge tokens login hands out in place of
+	// access tokens when a second factor is needed.
+	mfaTokenPurpose = "mfa"
+	// totpSkew is how many steps a code may be off, either way.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+)
+
+var (
+	errTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	errTOTPNotEnrolled    = errors.New("no TOTP secret is waiting for confirmation")
+	errTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	errInvalidMFACode     = errors.New("invalid authentication code")
+	errInvalidMFAToken    = errors.New("invalid or expired MFA token")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+type mfaChallengeResponse struct {
+	MFARequired       bool      `json:"mfa_required"`
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// mfaChallenge signs the token that proves user got the password right, to
+// be exchanged together with a code at /login/mfa.
+func (server *Server) mfaChallenge(user db.User) (mfaChallengeResponse, error) {
+	expirationTime := time.Now().Add(server.config.MFATokenDuration)
+	claims := &MyCustomClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Purpose:  mfaTokenPurpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+
+	mfaToken, err := server.keyring.Sign(claims)
+	if err != nil {
+		return mfaChallengeResponse{}, err
+	}
+	return mfaChallengeResponse{
+		MFARequired:       true,
+		MFAToken:          mfaToken,
+		MFATokenExpiresAt: expirationTime,
+	}, nil
+}
+
+// totpEnabled reports whether user has confirmed a TOTP secret.
+func (server *Server) totpEnabled(ctx *gin.Context, userID int32) (bool, error) {
+	secret, err := server.store.GetTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return secret.ConfirmedAt.Valid, nil
+}
+
+// checkSecondFactor accepts either a TOTP code, each of which works once, or
+// an unused recovery code, which is used up.
+func (server *Server) checkSecondFactor(ctx *gin.Context, userID int32, code, recoveryCode string) (bool, error) {
+	if recoveryCode != "" {
+		_, err := server.store.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(recoveryCode),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	secret, err := server.store.GetTOTP(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil || !secret.ConfirmedAt.Valid {
+		return false, err
+	}
+	step, ok := totp.Validate(secret.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return false, nil
+	}
+	_, err = server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+		UserID:       userID,
+		LastUsedStep: step,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// confirmSecondFactor is checkSecondFactor before a sensitive change. It
+// returns false when a response was written.
+func (server *Server) confirmSecondFactor(ctx *gin.Context, user db.User, code, recoveryCode string) bool {
+	ok, err := server.checkSecondFactor(ctx, user.ID, code, recoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !ok {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidMFACode))
+		return false
+	}
+	return true
+}
+
+// newRecoveryCodes returns recoveryCodeCount random codes, formatted to be
+// written down, and the hashes to store.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		_, err = rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes, which are easily mistyped.
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return token.HashOpaqueToken(code)
+}
+
+type loginMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// loginMFA finishes a login that needs a second factor. Wrong codes count
+// as failed logins of the user, so they are throttled like passwords.
+func (server *Server) loginMFA(ctx *gin.Context) {
+	var req loginMFARequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	claims, err := validateMFAToken(server.keyring, req.MFAToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFAToken))
+		return
+	}
+	user, err := server.store.GetUserById(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusUnauthorized, errorResponse(errInvalidMFAToken))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountDisabled))
+		return
+	}
+
+	wait, err := server.logins.Check(ctx, user.Username, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if wait > 0 {
+		setRetryAfter(ctx, wait)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return
+	}
+
+	ok, err := server.checkSecondFactor(ctx, user.ID, req.Code, req.RecoveryCode)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if !ok {
+		server.loginFailed(ctx, user.Username)
+		return
+	}
+	if req.RecoveryCode != "" {
+		log.Printf("audit: user %d logged in with a recovery code", user.ID)
+	}
+
+	err = server.logins.Success(ctx, user.Username)
+	if err != nil {
+		log.Printf("cannot reset failed logins of user %d: %v", user.ID, err)
+	}
+
+	result, err := server.startSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validateMFAToken is validateToken for challenge tokens.
+func validateMFAToken(keyring *token.Keyring, tokenString string) (*MyCustomClaims, error) {
+	claims := &MyCustomClaims{}
+	err := keyring.Parse(tokenString, claims)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != mfaTokenPurpose {
+		return nil, errWrongTokenPurpose
+	}
+	if claims.UserID == 0 {
+		return nil, errMissingUserID
+	}
+	return claims, nil
+}
+
+type enrollTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type enrollTOTPResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// enrollTOTP gives the user a new secret to add to an authenticator app.
+// It only takes effect once confirmed with a code; until then enrolling
+// again replaces it.
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	var req enrollTOTPRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.Password) {
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	_, err = server.store.CreateTOTP(ctx, db.CreateTOTPParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err != nil {
+		// The upsert leaves confirmed secrets alone.
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errTOTPAlreadyEnabled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(server.config.TOTPIssuer, user.Username, secret),
+	})
+}
+
+type confirmTOTPRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type recoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP turns two-factor authentication on once the user proves the
+// app generates the right codes, and hands out the recovery codes. They are
+// only shown this once.
+func (server *Server) confirmTOTP(ctx *gin.Context) {
+	var req confirmTOTPRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	userID := getUserClaims(ctx).UserID
+	secret, err := server.store.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errTOTPNotEnrolled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if secret.ConfirmedAt.Valid {
+		ctx.JSON(http.StatusConflict, errorResponse(errTOTPAlreadyEnabled))
+		return
+	}
+
+	step, ok := totp.Validate(secret.Secret, req.Code, time.Now(), totpSkew)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, errorResponse(errInvalidMFACode))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	_, err = server.store.ConfirmTOTPTx(ctx, db.ConfirmTOTPTxParams{
+		UserID:             userID,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	})
+	if err != nil {
+		// Confirmed concurrently, or re-enrolled in between.
+		if errors.Is(err, sql.ErrNoRows) {
+			ctx.JSON(http.StatusConflict, errorResponse(errTOTPNotEnrolled))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	log.Printf("audit: user %d enabled two-factor authentication", userID)
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+type secondFactorRequest struct {
+	Password     string `json:"password" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// requireSecondFactor binds a secondFactorRequest and checks both factors of
+// the current user, who must have two-factor authentication enabled. It
+// returns false when a response was written.
+func (server *Server) requireSecondFactor(ctx *gin.Context) (db.User, bool) {
+	var req secondFactorRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return db.User{}, false
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.Password) {
+		return user, false
+	}
+	enabled, err := server.totpEnabled(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	if !enabled {
+		ctx.JSON(http.StatusConflict, errorResponse(errTOTPNotEnabled))
+		return user, false
+	}
+	return user, server.confirmSecondFactor(ctx, user, req.Code, req.RecoveryCode)
+}
+
+// regenerateRecoveryCodes replaces every recovery code of the user.
+func (server *Server) regenerateRecoveryCodes(ctx *gin.Context) {
+	user, ok := server.requireSecondFactor(ctx)
+	if !ok {
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	err = server.store.ReplaceRecoveryCodesTx(ctx, db.ReplaceRecoveryCodesTxParams{
+		UserID:     user.ID,
+		CodeHashes: hashes,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	log.Printf("audit: user %d regenerated their recovery codes", user.ID)
+	ctx.JSON(http.StatusOK, recoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// disableTOTP turns two-factor authentication off.
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	user, ok := server.requireSecondFactor(ctx)
+	if !ok {
+		return
+	}
+
+	err := server.store.DisableTOTPTx(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	log.Printf("audit: user %d disabled two-factor authentication", user.ID)
+	ctx.JSON(http.StatusOK, true)
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/methyago/gofinance-backend/totp"
+	"github.com/stretchr/testify/require"
+)
+
+// totpCode returns the code of secret offset steps from now. Every code is
+// accepted once, so tests move on to the next step for the next code.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	require.NoError(t, err)
+	return code
+}
+
+// enableTOTP turns two-factor authentication on for user and returns the
+// secret and recovery codes.
+func (ts *testServer) enableTOTP(t *testing.T, user testUser) (string, []string) {
+	recorder := ts.request(t, http.MethodPost, "/me/totp", gin.H{"password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	enrollment := decodeBody[enrollTOTPResponse](t, recorder)
+	require.True(t, strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/GoFinance:"+user.Username+"?"))
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp/confirm", gin.H{"code": totpCode(t, enrollment.Secret, 0)}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	codes := decodeBody[recoveryCodesResponse](t, recorder).RecoveryCodes
+	require.Len(t, codes, recoveryCodeCount)
+
+	return enrollment.Secret, codes
+}
+
+// mfaChallenge logs user in with their password and returns the challenge
+// token.
+func (ts *testServer) mfaChallenge(t *testing.T, user testUser) string {
+	recorder := ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": user.Username,
+		"password": user.PlainPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	challenge := decodeBody[mfaChallengeResponse](t, recorder)
+	require.True(t, challenge.MFARequired)
+	require.NotEmpty(t, challenge.MFAToken)
+	return challenge.MFAToken
+}
+
+func TestEnrollTOTPAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodPost, "/me/totp", gin.H{"password": "wrong-password"}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp/confirm", gin.H{"code": "123456"}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp", gin.H{"password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	secret := decodeBody[enrollTOTPResponse](t, recorder).Secret
+
+	// Until confirmed, logins do not ask for a code.
+	ts.loginAs(t, user)
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp/confirm", gin.H{"code": totpCode(t, secret, 5)}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp/confirm", gin.H{"code": totpCode(t, secret, 0)}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/me/totp", gin.H{"password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestLoginMFAAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	secret, _ := ts.enableTOTP(t, user)
+
+	mfaToken := ts.mfaChallenge(t, user)
+
+	// The challenge is not an access token.
+	recorder := ts.request(t, http.MethodGet, "/me", nil, mfaToken)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Nor is an access token a challenge.
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token": user.Token,
+		"code":      totpCode(t, secret, 1),
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// The code the secret was confirmed with cannot be used again.
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token": mfaToken,
+		"code":      totpCode(t, secret, 0),
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{"mfa_token": mfaToken}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token": mfaToken,
+		"code":      totpCode(t, secret, 1),
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	result := decodeBody[loginResponseStruct](t, recorder)
+	require.Equal(t, user.ID, result.UserID)
+
+	recorder = ts.request(t, http.MethodGet, "/me", nil, result.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRecoveryCodesAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	secret, codes := ts.enableTOTP(t, user)
+
+	// Recovery codes work once, however they are typed.
+	mfaToken := ts.mfaChallenge(t, user)
+	recorder := ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token":     mfaToken,
+		"recovery_code": strings.ToUpper(strings.ReplaceAll(codes[0], "-", " ")),
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token":     mfaToken,
+		"recovery_code": codes[0],
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// New codes replace the old ones.
+	recorder = ts.request(t, http.MethodPost, "/me/totp/recovery-codes", gin.H{
+		"password": user.PlainPassword,
+		"code":     totpCode(t, secret, 1),
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	newCodes := decodeBody[recoveryCodesResponse](t, recorder).RecoveryCodes
+	require.Len(t, newCodes, recoveryCodeCount)
+
+	recorder = ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token":     mfaToken,
+		"recovery_code": codes[1],
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Turning 2FA off takes the password and a second factor.
+	recorder = ts.request(t, http.MethodDelete, "/me/totp", gin.H{
+		"password":      "wrong-password",
+		"recovery_code": newCodes[0],
+	}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, "/me/totp", gin.H{
+		"password":      user.PlainPassword,
+		"recovery_code": newCodes[0],
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, "/me/totp", gin.H{
+		"password":      user.PlainPassword,
+		"recovery_code": newCodes[1],
+	}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	ts.loginAs(t, user)
+}
+
+func TestMFACodesAreThrottled(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	secret, _ := ts.enableTOTP(t, user)
+	mfaToken := ts.mfaChallenge(t, user)
+
+	for i := 0; i < 5; i++ {
+		recorder := ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+			"mfa_token": mfaToken,
+			"code":      totpCode(t, secret, 10),
+		}, "")
+		require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	}
+
+	// Even a good code waits, as does the password.
+	require.Equal(t, http.StatusTooManyRequests, ts.login(t, user.Username, user.PlainPassword))
+	recorder := ts.request(t, http.MethodPost, "/login/mfa", gin.H{
+		"mfa_token": mfaToken,
+		"code":      totpCode(t, secret, 1),
+	}, "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
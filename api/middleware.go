@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/methyago/gofinance-backend/token"
+)
+
+const (
+	authorizationHeaderKey  = "Authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationClaimsKey  = "authorization_claims"
+)
+
+// authMiddleware verifies the bearer token once per request and stores its
+// claims in the context, where getUserClaims finds them. Requests without a
+// valid token are aborted with 401.
+func authMiddleware(keyring *token.Keyring) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		credentials, ok := bearerCredentials(ctx)
+		if !ok {
+			abortUnauthorized(ctx)
+			return
+		}
+
+		claims, err := validateToken(keyring, credentials)
+		if err != nil {
+			abortUnauthorized(ctx)
+			return
+		}
+
+		ctx.Set(authorizationClaimsKey, &UserClaims{
+			UserID:   claims.UserID,
+			UserName: claims.Username,
+			Role:     claims.Role,
+		})
+		ctx.Next()
+	}
+}
+
+// dataAuthMiddleware is authMiddleware for the finance data routes, which
+// also take API keys, within the scopes of the key.
+func (server *Server) dataAuthMiddleware() gin.HandlerFunc {
+	tokenAuth := authMiddleware(server.keyring)
+	return func(ctx *gin.Context) {
+		credentials, ok := bearerCredentials(ctx)
+		if ok && strings.HasPrefix(credentials, apiKeyPrefix) {
+			server.authenticateAPIKey(ctx, credentials)
+			return
+		}
+		tokenAuth(ctx)
+	}
+}
+
+// bearerCredentials returns the credentials of a bearer Authorization header.
+func bearerCredentials(ctx *gin.Context) (string, bool) {
+	fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+	if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer {
+		return "", false
+	}
+	return fields[1], true
+}
+
+func abortUnauthorized(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+}
+
+// getUserClaims returns the claims of the authenticated user. It must only be
+// called from handlers behind authMiddleware.
+func getUserClaims(ctx *gin.Context) *UserClaims {
+	return ctx.MustGet(authorizationClaimsKey).(*UserClaims)
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, key any, claims *MyCustomClaims) string {
+	tok := jwt.NewWithClaims(method, claims)
+	tok.Header["kid"] = kid
+	signed, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	secret := []byte(util.RandomString(32))
+	key, err := token.NewHMACKey("test", secret)
+	require.NoError(t, err)
+	keyring, err := token.NewKeyring(key)
+	require.NoError(t, err)
+
+	valid := &MyCustomClaims{
+		UserID:   7,
+		Username: "alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	expired := &MyCustomClaims{
+		UserID:   7,
+		Username: "alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}
+	noUserID := &MyCustomClaims{
+		Username: "alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"Valid", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, "test", secret, valid), http.StatusOK},
+		{"LowercaseScheme", "bearer " + signTestToken(t, jwt.SigningMethodHS256, "test", secret, valid), http.StatusOK},
+		{"NoHeader", "", http.StatusUnauthorized},
+		{"NoToken", "Bearer", http.StatusUnauthorized},
+		{"WrongScheme", "Basic " + signTestToken(t, jwt.SigningMethodHS256, "test", secret, valid), http.StatusUnauthorized},
+		{"Expired", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, "test", secret, expired), http.StatusUnauthorized},
+		{"WrongKey", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, "test", []byte("other"), valid), http.StatusUnauthorized},
+		{"UnknownKeyID", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, "other", secret, valid), http.StatusUnauthorized},
+		{"WrongAlgorithm", "Bearer " + signTestToken(t, jwt.SigningMethodHS512, "test", secret, valid), http.StatusUnauthorized},
+		{"Unsigned", "Bearer " + signTestToken(t, jwt.SigningMethodNone, "test", jwt.UnsafeAllowNoneSignatureType, valid), http.StatusUnauthorized},
+		{"NoUserID", "Bearer " + signTestToken(t, jwt.SigningMethodHS256, "test", secret, noUserID), http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/auth", authMiddleware(keyring), func(ctx *gin.Context) {
+				ctx.JSON(http.StatusOK, getUserClaims(ctx))
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/auth", nil)
+			require.NoError(t, err)
+			if tc.header != "" {
+				req.Header.Set(authorizationHeaderKey, tc.header)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			require.Equal(t, tc.code, recorder.Code)
+
+			if tc.code == http.StatusOK {
+				claims := decodeBody[UserClaims](t, recorder)
+				require.Equal(t, int32(7), claims.UserID)
+				require.Equal(t, "alice", claims.UserName)
+			} else {
+				require.JSONEq(t, `{"error":"Unauthorized"}`, recorder.Body.String())
+			}
+		})
+	}
+}
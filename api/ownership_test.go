@@ -0,0 +1,184 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// ownedResources creates one of every kind of resource for owner and returns
+// the url of each.
+func (ts *testServer) ownedResources(t *testing.T, owner testUser) map[string]string {
+	cat := ts.createCategory(t, owner, "debit")
+	acc := ts.createAccount(t, owner, cat, 100)
+	from := ts.createWallet(t, owner, 1000)
+	to := ts.createWallet(t, owner, 0)
+	transfer := ts.createTransfer(t, owner, from, to, 100)
+
+	recorder := ts.request(t, http.MethodPost, "/recurrence", gin.H{
+		"title":       "Rent",
+		"type":        "debit",
+		"description": "Flat",
+		"category_id": cat.ID,
+		"value":       1000,
+		"frequency":   "monthly",
+		"start_date":  time.Now().AddDate(1, 0, 0),
+	}, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	rec := decodeBody[db.Recurrence](t, recorder)
+
+	recorder = ts.request(t, http.MethodPost, "/account/installments", gin.H{
+		"title":             "Laptop",
+		"type":              "debit",
+		"description":       "Store",
+		"category_id":       cat.ID,
+		"total_value":       1200,
+		"installment_count": 3,
+		"first_date":        time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	group := decodeBody[db.InstallmentsTxResult](t, recorder).Group
+
+	return map[string]string{
+		"category":     fmt.Sprintf("/category/%d", cat.ID),
+		"account":      fmt.Sprintf("/account/%d", acc.ID),
+		"wallet":       fmt.Sprintf("/wallet/%d", from.ID),
+		"transfer":     fmt.Sprintf("/transfer/%d", transfer.Transfer.ID),
+		"recurrence":   fmt.Sprintf("/recurrence/%d", rec.ID),
+		"installments": fmt.Sprintf("/account/installments/%d", group.ID),
+	}
+}
+
+func TestCrossTenantAccessAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	intruder := ts.createUserAndLogin(t)
+	urls := ts.ownedResources(t, owner)
+
+	bodies := map[string]gin.H{
+		"category":     {"title": "Hacked", "description": "Hacked"},
+		"account":      {"title": "Hacked", "description": "Hacked", "value": 1},
+		"wallet":       {"name": "Hacked", "type": "cash", "currency": "BRL"},
+		"transfer":     {"description": "Hacked", "value": 1, "date": time.Now()},
+		"recurrence":   {"title": "Hacked", "description": "Hacked", "value": 1},
+		"installments": {"title": "Hacked", "description": "Hacked", "total_value": 1},
+	}
+
+	for name, url := range urls {
+		t.Run(name, func(t *testing.T) {
+			recorder := ts.request(t, http.MethodGet, url, nil, intruder.Token)
+			require.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+			recorder = ts.request(t, http.MethodPut, url, bodies[name], intruder.Token)
+			require.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+			recorder = ts.request(t, http.MethodDelete, url, nil, intruder.Token)
+			require.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+
+			// The owner still sees the row untouched.
+			recorder = ts.request(t, http.MethodGet, url, nil, owner.Token)
+			require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+			require.NotContains(t, recorder.Body.String(), "Hacked")
+		})
+	}
+
+	recorder := ts.request(t, http.MethodGet, urls["recurrence"]+"/preview", nil, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, urls["wallet"]+"/balance", nil, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestCrossTenantReferencesAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	intruder := ts.createUserAndLogin(t)
+	ownerCat := ts.createCategory(t, owner, "debit")
+	ownerWallet := ts.createWallet(t, owner, 1000)
+	intruderCat := ts.createCategory(t, intruder, "debit")
+	intruderWallet := ts.createWallet(t, intruder, 1000)
+
+	account := gin.H{
+		"title":       "Lunch",
+		"type":        "debit",
+		"description": "Restaurant",
+		"date":        time.Now(),
+		"value":       100,
+	}
+
+	account["category_id"] = ownerCat.ID
+	recorder := ts.request(t, http.MethodPost, "/account", account, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	account["category_id"] = intruderCat.ID
+	account["wallet_id"] = ownerWallet.ID
+	recorder = ts.request(t, http.MethodPost, "/account", account, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/transfer", gin.H{
+		"from_wallet_id": ownerWallet.ID,
+		"to_wallet_id":   intruderWallet.ID,
+		"value":          100,
+		"date":           time.Now(),
+	}, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/recurrence", gin.H{
+		"title":       "Rent",
+		"type":        "debit",
+		"description": "Flat",
+		"category_id": ownerCat.ID,
+		"value":       1000,
+		"frequency":   "monthly",
+		"start_date":  time.Now(),
+	}, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// The account belongs to whoever holds the token, not to the owner of
+	// the category.
+	acc := ts.createAccount(t, intruder, intruderCat, 100)
+	require.Equal(t, ts.personalLedger(t, intruder).ID, acc.LedgerID)
+
+	recorder = ts.request(t, http.MethodGet, "/accounts?type=debit", nil, owner.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, decodeBody[[]db.GetAccountsRow](t, recorder))
+}
+
+type readOnlyPolicy struct{}
+
+func (readOnlyPolicy) Authorize(claims *UserClaims, action Action, ownerID int32) bool {
+	return action == ActionRead && claims.UserID == ownerID
+}
+
+func (readOnlyPolicy) AuthorizeLedger(claims *UserClaims, action Action, ledgerID int32) bool {
+	return action == ActionRead && claims.LedgerID == ledgerID
+}
+
+func TestPolicyIsConsultedAPI(t *testing.T) {
+	ts := newTestServerWithPolicy(t, readOnlyPolicy{})
+	user := ts.createUserAndLogin(t)
+	wallet := ts.createWallet(t, user, 100)
+
+	url := fmt.Sprintf("/wallet/%d", wallet.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Ledger data the policy lets the user read but not change answers 403.
+	recorder = ts.request(t, http.MethodGet, "/categories?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPost, "/category", gin.H{
+		"title":       "Food",
+		"type":        "debit",
+		"description": "Groceries",
+	}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
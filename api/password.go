@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/validation"
+)
+
+const passwordResetSubject = "Reset your password"
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword emails a single-use reset link to the owner of the address.
+// The response is the same whether or not the address belongs to a user, and
+// the email is sent in the background so the timing does not tell either.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("cannot look up user for password reset: %v", err)
+		}
+		ctx.JSON(http.StatusOK, true)
+		return
+	}
+
+	resetToken, err := token.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	_, err = server.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: token.HashOpaqueToken(resetToken),
+		ExpiresAt: time.Now().Add(server.config.PasswordResetDuration),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.sendInBackground(mail.Message{
+		To:      user.Email,
+		Subject: passwordResetSubject,
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you did not ask for this, you can ignore this email.\n",
+			user.Username, server.config.PasswordResetDuration, tokenLink(server.config.PasswordResetURL, resetToken)),
+	})
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// resetPassword sets a new password with a token from forgotPassword and logs
+// the user out everywhere.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	tokenHash := token.HashOpaqueToken(req.Token)
+
+	// The new password must not contain the username or email of the owner
+	// of the token. ResetPasswordTx checks the token again, atomically.
+	user, err := server.getResetTokenUser(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, db.ErrResetTokenInvalid) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	err = validation.Password(req.Password, user.Username, user.Email)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	passwordHashed, err := server.passwords.Hash(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		TokenHash: tokenHash,
+		Password:  passwordHashed,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrResetTokenInvalid) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+// getResetTokenUser returns the owner of a reset token, or
+// db.ErrResetTokenInvalid when the token cannot be used.
+func (server *Server) getResetTokenUser(ctx *gin.Context, tokenHash string) (db.User, error) {
+	resetToken, err := server.store.GetPasswordResetTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.User{}, db.ErrResetTokenInvalid
+		}
+		return db.User{}, err
+	}
+	if resetToken.UsedAt.Valid || !time.Now().Before(resetToken.ExpiresAt) {
+		return db.User{}, db.ErrResetTokenInvalid
+	}
+
+	user, err := server.store.GetUserById(ctx, resetToken.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return user, db.ErrResetTokenInvalid
+	}
+	return user, err
+}
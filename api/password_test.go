@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+var resetLinkPattern = regexp.MustCompile(`https://app\.example\.com/reset\?\S+`)
+
+// forgotPassword requests a reset for user and returns the token from the
+// email that was sent.
+func (ts *testServer) forgotPassword(t *testing.T, user testUser) string {
+	recorder := ts.request(t, http.MethodPost, "/password/forgot", gin.H{"email": user.Email}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	msg := ts.outbox.next(t, user.Email, passwordResetSubject)
+	link, err := url.Parse(resetLinkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	resetToken := link.Query().Get("token")
+	require.NotEmpty(t, resetToken)
+	return resetToken
+}
+
+func (ts *testServer) login(t *testing.T, username, password string) int {
+	recorder := ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": username,
+		"password": password,
+	}, "")
+	return recorder.Code
+}
+
+func TestPasswordResetAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	resetToken := ts.forgotPassword(t, user)
+
+	newPassword := util.RandomString(12)
+	recorder := ts.request(t, http.MethodPost, "/password/reset", gin.H{
+		"token":    resetToken,
+		"password": newPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, user.PlainPassword))
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, newPassword))
+
+	// Sessions opened with the old password are gone.
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// The token is single use.
+	recorder = ts.request(t, http.MethodPost, "/password/reset", gin.H{
+		"token":    resetToken,
+		"password": util.RandomString(12),
+	}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, newPassword))
+}
+
+func TestPasswordResetRejectsRelatedPasswordAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	resetToken := ts.forgotPassword(t, user)
+
+	for _, password := range []string{user.Username + "2024!", user.Email} {
+		recorder := ts.request(t, http.MethodPost, "/password/reset", gin.H{"token": resetToken, "password": password}, "")
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, password))
+	}
+
+	// A rejected password does not use the token up.
+	recorder := ts.request(t, http.MethodPost, "/password/reset", gin.H{"token": resetToken, "password": util.RandomString(12)}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestPasswordResetInvalidatesOtherTokensAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	first := ts.forgotPassword(t, user)
+	second := ts.forgotPassword(t, user)
+
+	recorder := ts.request(t, http.MethodPost, "/password/reset", gin.H{"token": second, "password": util.RandomString(12)}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = ts.request(t, http.MethodPost, "/password/reset", gin.H{"token": first, "password": util.RandomString(12)}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestExpiredPasswordResetAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	resetToken, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+	_, err = ts.store.CreatePasswordResetToken(context.Background(), db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: token.HashOpaqueToken(resetToken),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	recorder := ts.request(t, http.MethodPost, "/password/reset", gin.H{"token": resetToken, "password": util.RandomString(12)}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, user.PlainPassword))
+}
+
+func TestForgotPasswordUnknownEmailAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	known := ts.request(t, http.MethodPost, "/password/forgot", gin.H{"email": user.Email}, "")
+	ts.outbox.next(t, user.Email, passwordResetSubject)
+	email := util.RandomEmail()
+	unknown := ts.request(t, http.MethodPost, "/password/forgot", gin.H{"email": email}, "")
+
+	require.Equal(t, known.Code, unknown.Code)
+	require.Equal(t, known.Body.String(), unknown.Body.String())
+	time.Sleep(50 * time.Millisecond)
+	_, sent := ts.outbox.take(email, passwordResetSubject)
+	require.False(t, sent)
+
+	recorder := ts.request(t, http.MethodPost, "/password/forgot", gin.H{"email": "not an email"}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
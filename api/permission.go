@@ -0,0 +1,74 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+// Permission is something only some roles may do.
+type Permission string
+
+const (
+	PermissionViewUsers      Permission = "users:view"
+	PermissionDisableUsers   Permission = "users:disable"
+	PermissionResetPasswords Permission = "users:reset_password"
+	PermissionManageRoles    Permission = "users:manage_roles"
+	PermissionViewStats      Permission = "stats:view"
+	PermissionViewAuditLog   Permission = "audit:view"
+)
+
+// rolePermissions grants permissions to roles. Regular users have none;
+// they can only act on their own data.
+var rolePermissions = map[string][]Permission{
+	db.RoleAdmin: {
+		PermissionViewUsers,
+		PermissionDisableUsers,
+		PermissionResetPasswords,
+		PermissionManageRoles,
+		PermissionViewStats,
+		PermissionViewAuditLog,
+	},
+	db.RoleSupport: {
+		PermissionViewUsers,
+		PermissionResetPasswords,
+		PermissionViewStats,
+	},
+}
+
+var errForbidden = errors.New("you do not have permission to do this")
+
+func hasPermission(role string, permission Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// requirePermission lets the request through when the authenticated user has
+// permission. The role is read from the database rather than the token, so
+// a demoted or disabled user loses access right away.
+func (server *Server) requirePermission(permission Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := server.store.GetUserById(ctx, getUserClaims(ctx).UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				abortUnauthorized(ctx)
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if user.DisabledAt.Valid || !hasPermission(user.Role, permission) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errForbidden))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+var errLedgerReadOnly = errors.New("your role in this ledger does not allow changes")
+
+// Action is what a handler is about to do with a resource.
+type Action int
+
+const (
+	ActionRead Action = iota
+	ActionWrite
+)
+
+// Policy decides whether the authenticated user may perform an action on a
+// resource. Queries are already scoped to the user's own rows or to the ledger
+// of the request; the policy is the single place to change who may do what.
+type Policy interface {
+	// Authorize decides for resources owned by ownerID, such as wallets.
+	Authorize(claims *UserClaims, action Action, ownerID int32) bool
+	// AuthorizeLedger decides for resources of the ledger ledgerID, such as
+	// categories and accounts.
+	AuthorizeLedger(claims *UserClaims, action Action, ledgerID int32) bool
+}
+
+// OwnerPolicy lets users act on resources they own, and on the data of the
+// ledger of the request as far as their role in it allows: viewers may only
+// read, owners and editors may also write.
+type OwnerPolicy struct{}
+
+func (OwnerPolicy) Authorize(claims *UserClaims, action Action, ownerID int32) bool {
+	return claims.UserID == ownerID
+}
+
+func (OwnerPolicy) AuthorizeLedger(claims *UserClaims, action Action, ledgerID int32) bool {
+	if claims.LedgerID != ledgerID {
+		return false
+	}
+	switch claims.LedgerRole {
+	case db.LedgerRoleOwner, db.LedgerRoleEditor:
+		return true
+	case db.LedgerRoleViewer:
+		return action == ActionRead
+	}
+	return false
+}
+
+// authorizeRow finishes a user scoped lookup. It answers 404 when the row does
+// not exist or the policy denies access, so the ids of other users cannot be
+// probed, and 500 on any other error. It returns false when a response was
+// written.
+func (server *Server) authorizeRow(ctx *gin.Context, userClaims *UserClaims, action Action, ownerID int32, err error) bool {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !server.policy.Authorize(userClaims, action, ownerID) {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return false
+	}
+	return true
+}
+
+// authorizeLedgerRow finishes a ledger scoped lookup like authorizeRow. When
+// the policy denies action but would allow reading the row, the user can see
+// it anyway, so the answer is 403 instead of 404.
+func (server *Server) authorizeLedgerRow(ctx *gin.Context, userClaims *UserClaims, action Action, ledgerID int32, err error) bool {
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if !server.policy.AuthorizeLedger(userClaims, action, ledgerID) {
+		if server.policy.AuthorizeLedger(userClaims, ActionRead, ledgerID) {
+			ctx.JSON(http.StatusForbidden, errorResponse(errLedgerReadOnly))
+			return false
+		}
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return false
+	}
+	return true
+}
+
+// authorizeLedgerWrite checks that the authenticated user may add data to the
+// ledger of the request. It returns false when a response was written.
+func (server *Server) authorizeLedgerWrite(ctx *gin.Context, userClaims *UserClaims) bool {
+	return server.authorizeLedgerRow(ctx, userClaims, ActionWrite, userClaims.LedgerID, nil)
+}
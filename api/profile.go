@@ -0,0 +1,230 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/validation"
+)
+
+var errWrongPassword = errors.New("current password is wrong")
+
+// getCurrentUser loads the authenticated user. It returns false when a
+// response was written, which is 401 for users deleted since the token was
+// issued.
+func (server *Server) getCurrentUser(ctx *gin.Context) (db.User, bool) {
+	user, err := server.store.GetUserById(ctx, getUserClaims(ctx).UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			abortUnauthorized(ctx)
+			return user, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return user, false
+	}
+	return user, true
+}
+
+// confirmPassword checks password against the one of user before a sensitive
+// change. Wrong passwords count as failed logins, so a stolen access token
+// does not buy unlimited guesses. It returns false when a response was
+// written.
+func (server *Server) confirmPassword(ctx *gin.Context, user db.User, password string) bool {
+	wait, err := server.logins.Check(ctx, user.Username, ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if wait > 0 {
+		setRetryAfter(ctx, wait)
+		ctx.JSON(http.StatusTooManyRequests, errorResponse(errTooManyLoginAttempts))
+		return false
+	}
+
+	_, err = server.passwords.Verify(password, user.Password)
+	if err != nil {
+		wait, err := server.logins.Failure(ctx, user.Username, ctx.ClientIP())
+		if err != nil {
+			log.Printf("cannot record failed password confirmation: %v", err)
+		}
+		if wait > 0 {
+			setRetryAfter(ctx, wait)
+		}
+		ctx.JSON(http.StatusForbidden, errorResponse(errWrongPassword))
+		return false
+	}
+
+	err = server.logins.Success(ctx, user.Username)
+	if err != nil {
+		log.Printf("cannot reset failed logins of user %d: %v", user.ID, err)
+	}
+	return true
+}
+
+func (server *Server) getProfile(ctx *gin.Context) {
+	user, ok := server.getCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// changePassword sets a new password and logs every device out. The caller
+// gets a fresh pair of tokens in place of the revoked ones.
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.CurrentPassword) {
+		return
+	}
+	err = validation.Password(req.NewPassword, user.Username, user.Email)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	passwordHashed, err := server.passwords.Hash(req.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err = server.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		UserID:   user.ID,
+		Password: passwordHashed,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result, err := server.startSession(ctx, user)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+type changeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+// changeEmail replaces the email of the user and sends a verification link
+// to the new address.
+func (server *Server) changeEmail(ctx *gin.Context) {
+	var req changeEmailRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil {
+		err = validation.Email(req.Email)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.Password) {
+		return
+	}
+	if user.Email == req.Email {
+		ctx.JSON(http.StatusOK, newUserResponse(user))
+		return
+	}
+
+	user, err = server.store.ChangeEmailTx(ctx, db.ChangeEmailTxParams{
+		UserID: user.ID,
+		Email:  req.Email,
+	})
+	if err != nil {
+		storeError(ctx, err)
+		return
+	}
+
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		log.Printf("cannot send verification email to user %d: %v", user.ID, err)
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type changeUsernameRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// changeUsername renames the user. Access tokens issued before keep the old
+// name in their claims until they expire.
+func (server *Server) changeUsername(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req changeUsernameRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err == nil {
+		err = validation.Username(req.Username)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUsername(ctx, db.UpdateUsernameParams{
+		ID:       userClaims.UserID,
+		Username: req.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			abortUnauthorized(ctx)
+			return
+		}
+		storeError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type deleteProfileRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// deleteProfile removes the user together with all of their categories,
+// accounts and other finance data.
+func (server *Server) deleteProfile(ctx *gin.Context) {
+	var req deleteProfileRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, ok := server.getCurrentUser(ctx)
+	if !ok || !server.confirmPassword(ctx, user, req.Password) {
+		return
+	}
+
+	err = server.store.DeleteUserTx(ctx, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProfileAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodGet, "/me", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "password")
+	profile := decodeBody[userResponse](t, recorder)
+	require.Equal(t, user.ID, profile.ID)
+	require.Equal(t, user.Username, profile.Username)
+	require.Equal(t, user.Email, profile.Email)
+	require.Nil(t, profile.EmailVerifiedAt)
+
+	recorder = ts.request(t, http.MethodGet, "/me", nil, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	newPassword := util.RandomString(12)
+
+	recorder := ts.request(t, http.MethodPut, "/me/password", gin.H{
+		"current_password": util.RandomString(12),
+		"new_password":     newPassword,
+	}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPut, "/me/password", gin.H{
+		"current_password": user.PlainPassword,
+		"new_password":     "12345678",
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodPut, "/me/password", gin.H{
+		"current_password": user.PlainPassword,
+		"new_password":     newPassword,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	fresh := decodeBody[loginResponseStruct](t, recorder)
+
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, user.PlainPassword))
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, newPassword))
+
+	// Other sessions are revoked, the one handed back keeps working.
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	_, code = ts.refresh(t, fresh.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestConfirmPasswordThrottleAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	wrong := gin.H{"current_password": "wrong-password", "new_password": util.RandomString(12)}
+
+	for i := 1; i < 5; i++ {
+		recorder := ts.request(t, http.MethodPut, "/me/password", wrong, user.Token)
+		require.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Empty(t, recorder.Header().Get("Retry-After"))
+	}
+
+	// Guesses through any endpoint add up with failed logins.
+	recorder := ts.request(t, http.MethodDelete, "/me", gin.H{"password": "wrong-password"}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+
+	recorder = ts.request(t, http.MethodPut, "/me/password", gin.H{
+		"current_password": user.PlainPassword,
+		"new_password":     util.RandomString(12),
+	}, user.Token)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	require.Equal(t, http.StatusTooManyRequests, ts.login(t, user.Username, user.PlainPassword))
+
+	recorder = ts.request(t, http.MethodPut, "/me/email", gin.H{"email": util.RandomEmail(), "password": other.PlainPassword}, other.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestChangeEmailAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	oldToken := ts.verificationToken(t, user.Email)
+
+	recorder := ts.request(t, http.MethodPut, "/me/email", gin.H{"email": other.Email, "password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodPut, "/me/email", gin.H{"email": util.RandomEmail(), "password": "wrong"}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	recorder = ts.request(t, http.MethodPut, "/me/email", gin.H{"email": "not an email", "password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	email := util.RandomEmail()
+	recorder = ts.request(t, http.MethodPut, "/me/email", gin.H{"email": email, "password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	profile := decodeBody[userResponse](t, recorder)
+	require.Equal(t, email, profile.Email)
+	require.Nil(t, profile.EmailVerifiedAt)
+
+	// Only the link sent to the new address verifies it.
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token="+oldToken, nil, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token="+ts.verificationToken(t, email), nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/me", nil, user.Token)
+	require.NotNil(t, decodeBody[userResponse](t, recorder).EmailVerifiedAt)
+}
+
+func TestChangeUsernameAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodPut, "/me/username", gin.H{"username": other.Username}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodPut, "/me/username", gin.H{"username": "no spaces"}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Keeping the current name is not a conflict.
+	recorder = ts.request(t, http.MethodPut, "/me/username", gin.H{"username": user.Username}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	username := util.RandomString(8)
+	recorder = ts.request(t, http.MethodPut, "/me/username", gin.H{"username": username}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, username, decodeBody[userResponse](t, recorder).Username)
+
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, user.PlainPassword))
+	require.Equal(t, http.StatusOK, ts.login(t, username, user.PlainPassword))
+}
+
+func TestDeleteProfileAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	deleted := ts.ownedResources(t, user)
+	kept := ts.ownedResources(t, other)
+
+	recorder := ts.request(t, http.MethodDelete, "/me", gin.H{"password": "wrong"}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, "/me", gin.H{"password": user.PlainPassword}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	_, err := ts.store.GetUserById(context.Background(), user.ID)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, user.PlainPassword))
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	recorder = ts.request(t, http.MethodGet, "/me", nil, user.Token)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	for kind, url := range deleted {
+		recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+		require.Equal(t, http.StatusNotFound, recorder.Code, kind)
+	}
+
+	// Data of other users is untouched.
+	for kind, url := range kept {
+		recorder = ts.request(t, http.MethodGet, url, nil, other.Token)
+		require.Equal(t, http.StatusOK, recorder.Code, kind)
+	}
+}
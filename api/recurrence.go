@@ -0,0 +1,274 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/recurring"
+)
+
+type createRecurrenceRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Type        string     `json:"type" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	CategoryID  int32      `json:"category_id" binding:"required"`
+	Value       int32      `json:"value" binding:"required"`
+	Frequency   string     `json:"frequency" binding:"required,oneof=daily weekly monthly yearly"`
+	Interval    int32      `json:"interval" binding:"omitempty,min=1"`
+	StartDate   time.Time  `json:"start_date" binding:"required"`
+	EndDate     *time.Time `json:"end_date"`
+	Occurrences *int32     `json:"occurrences" binding:"omitempty,min=1"`
+}
+
+func (server *Server) createRecurrence(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req createRecurrenceRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cat, ok := server.getUserCategory(ctx, userClaims, req.CategoryID, ActionWrite)
+	if !ok {
+		return
+	}
+	if cat.Type != req.Type {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error:": "Recurrence type is different of category type"})
+		return
+	}
+
+	interval := req.Interval
+	if interval == 0 {
+		interval = 1
+	}
+
+	arg := db.CreateRecurrenceParams{
+		LedgerID:    userClaims.LedgerID,
+		CategoryID:  req.CategoryID,
+		Title:       req.Title,
+		Type:        req.Type,
+		Description: req.Description,
+		Value:       req.Value,
+		Frequency:   req.Frequency,
+		Interval:    interval,
+		StartDate:   req.StartDate,
+		EndDate:     nullTime(req.EndDate),
+		Occurrences: nullInt32(req.Occurrences),
+	}
+
+	rec, err := server.store.CreateRecurrence(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rec)
+}
+
+type getRecurrenceRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) getRecurrence(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req getRecurrenceRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rec, ok := server.getUserRecurrence(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rec)
+}
+
+// getUserRecurrence loads a recurrence rule of the ledger of the request and
+// checks the policy for action. It returns false when a response was written.
+func (server *Server) getUserRecurrence(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.Recurrence, bool) {
+	rec, err := server.store.GetRecurrence(ctx, db.GetRecurrenceParams{
+		ID:       id,
+		LedgerID: userClaims.LedgerID,
+	})
+	return rec, server.authorizeLedgerRow(ctx, userClaims, action, rec.LedgerID, err)
+}
+
+func (server *Server) getRecurrences(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	recs, err := server.store.GetRecurrences(ctx, userClaims.LedgerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recs)
+}
+
+type updateRecurrenceIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type updateRecurrenceRequest struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description" binding:"required"`
+	Value       int32      `json:"value" binding:"required"`
+	EndDate     *time.Time `json:"end_date"`
+	Occurrences *int32     `json:"occurrences" binding:"omitempty,min=1"`
+}
+
+func (server *Server) updateRecurrence(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri updateRecurrenceIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody updateRecurrenceRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rec, ok := server.getUserRecurrence(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateRecurrenceParams{
+		ID:          rec.ID,
+		Title:       reqBody.Title,
+		Description: reqBody.Description,
+		Value:       reqBody.Value,
+		EndDate:     nullTime(reqBody.EndDate),
+		Occurrences: nullInt32(reqBody.Occurrences),
+		LedgerID:    userClaims.LedgerID,
+	}
+
+	rec, err = server.store.UpdateRecurrence(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rec)
+}
+
+type deleteRecurrenceRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) deleteRecurrence(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteRecurrenceRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserRecurrence(ctx, userClaims, req.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeleteRecurrence(ctx, db.DeleteRecurrenceParams{
+		ID:       req.ID,
+		LedgerID: userClaims.LedgerID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type previewRecurrenceIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type previewRecurrenceRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=366"`
+}
+
+type recurrenceOccurrence struct {
+	Date        time.Time `json:"date"`
+	Title       string    `json:"title"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Value       int32     `json:"value"`
+}
+
+func (server *Server) previewRecurrence(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri previewRecurrenceIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req previewRecurrenceRequest
+	err = ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 12
+	}
+
+	rec, ok := server.getUserRecurrence(ctx, userClaims, reqUri.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	// Anything on or before materialized_until already exists as an account.
+	from := recurring.Day(time.Now())
+	if rec.MaterializedUntil.Valid && !rec.MaterializedUntil.Time.Before(from) {
+		from = rec.MaterializedUntil.Time.AddDate(0, 0, 1)
+	}
+
+	occurrences := []recurrenceOccurrence{}
+	for _, date := range recurring.Occurrences(rec, from, time.Time{}, req.Limit) {
+		occurrences = append(occurrences, recurrenceOccurrence{
+			Date:        date,
+			Title:       rec.Title,
+			Type:        rec.Type,
+			Description: rec.Description,
+			Value:       rec.Value,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, occurrences)
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func nullInt32(i *int32) sql.NullInt32 {
+	if i == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *i, Valid: true}
+}
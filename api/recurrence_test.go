@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/recurring"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrenceAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+
+	start := recurring.Day(time.Now()).AddDate(0, 0, -14)
+	recorder := ts.request(t, http.MethodPost, "/recurrence", gin.H{
+		"title":       "Rent",
+		"type":        "debit",
+		"description": "Apartment",
+		"category_id": cat.ID,
+		"value":       1500,
+		"frequency":   "weekly",
+		"start_date":  start,
+		"occurrences": 6,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	rec := decodeBody[db.Recurrence](t, recorder)
+	require.Equal(t, int32(1), rec.Interval)
+
+	url := fmt.Sprintf("/recurrence/%d", rec.ID)
+	recorder = ts.request(t, http.MethodGet, url+"/preview?limit=10", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	preview := decodeBody[[]recurrenceOccurrence](t, recorder)
+	require.Len(t, preview, 4)
+
+	materializer := recurring.NewMaterializer(ts.store, time.Hour)
+	require.NoError(t, materializer.Run(context.Background(), time.Now()))
+	require.NoError(t, materializer.Run(context.Background(), time.Now()))
+
+	recorder = ts.request(t, http.MethodGet, "/accounts?type=debit", nil, user.Token)
+	require.Len(t, decodeBody[[]db.GetAccountsRow](t, recorder), 3)
+
+	recorder = ts.request(t, http.MethodGet, url+"/preview", nil, user.Token)
+	preview = decodeBody[[]recurrenceOccurrence](t, recorder)
+	require.Len(t, preview, 3)
+	require.True(t, preview[0].Date.After(recurring.Day(time.Now())))
+
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"title":       "Rent",
+		"description": "Apartment",
+		"value":       1600,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.False(t, decodeBody[db.Recurrence](t, recorder).Occurrences.Valid)
+
+	recorder = ts.request(t, http.MethodGet, "/recurrences", nil, user.Token)
+	require.Len(t, decodeBody[[]db.Recurrence](t, recorder), 1)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/accounts?type=debit", nil, user.Token)
+	require.Len(t, decodeBody[[]db.GetAccountsRow](t, recorder), 3)
+}
+
+func TestCreateRecurrenceValidationAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	cat := ts.createCategory(t, user, "debit")
+
+	recorder := ts.request(t, http.MethodPost, "/recurrence", gin.H{
+		"title":       "Rent",
+		"type":        "debit",
+		"description": "Apartment",
+		"category_id": cat.ID,
+		"value":       1500,
+		"frequency":   "hourly",
+		"start_date":  time.Now(),
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,270 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+// Granularities of the period reports, named like the date_trunc units.
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+	GranularityYear  = "year"
+)
+
+// maxReportPeriods bounds how many periods a report may have, so a long range
+// with a fine granularity cannot make the database generate huge series.
+const maxReportPeriods = 1000
+
+// reportPeriodLength is the shortest each period can be, which makes
+// checkPeriods overestimate the number of periods rather than miss any.
+var reportPeriodLength = map[string]time.Duration{
+	GranularityDay:   24 * time.Hour,
+	GranularityWeek:  7 * 24 * time.Hour,
+	GranularityMonth: 28 * 24 * time.Hour,
+	GranularityYear:  365 * 24 * time.Hour,
+}
+
+var (
+	errReportRange    = errors.New("to must not be before from")
+	errReportTooLarge = errors.New("too many periods, pick a shorter range or a coarser granularity")
+)
+
+// reportRangeRequest is the date range of a report. Both days are included.
+type reportRangeRequest struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// checkRange answers 400 when the range is reversed. It returns false when a
+// response was written.
+func (req reportRangeRequest) checkRange(ctx *gin.Context) bool {
+	if req.To.Before(req.From) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errReportRange))
+		return false
+	}
+	return true
+}
+
+// periodReportRequest is the range of a report split into periods.
+type periodReportRequest struct {
+	reportRangeRequest
+	Granularity string `form:"granularity" binding:"required,oneof=day week month year"`
+}
+
+// checkPeriods answers 400 when the range is reversed or has more than
+// maxReportPeriods periods. It returns false when a response was written.
+func (req periodReportRequest) checkPeriods(ctx *gin.Context) bool {
+	if !req.checkRange(ctx) {
+		return false
+	}
+	if req.To.Sub(req.From)/reportPeriodLength[req.Granularity] >= maxReportPeriods {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errReportTooLarge))
+		return false
+	}
+	return true
+}
+
+type accountsSummaryResponse struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+	Income      int64     `json:"income"`
+	Expense     int64     `json:"expense"`
+	Net         int64     `json:"net"`
+	// Periods has one entry per period of the range, including those
+	// without accounts.
+	Periods []db.GetAccountsSummaryRow `json:"periods"`
+}
+
+// getAccountsSummary reports income, expense and net of the ledger per period
+// of a date range. Transfers between wallets are neither.
+func (server *Server) getAccountsSummary(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req periodReportRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !req.checkPeriods(ctx) {
+		return
+	}
+
+	periods, err := server.store.GetAccountsSummary(ctx, db.GetAccountsSummaryParams{
+		Granularity: req.Granularity,
+		StartDate:   req.From,
+		EndDate:     req.To,
+		LedgerID:    userClaims.LedgerID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := accountsSummaryResponse{
+		From:        req.From,
+		To:          req.To,
+		Granularity: req.Granularity,
+		Periods:     periods,
+	}
+	for _, period := range periods {
+		rsp.Income += period.Income
+		rsp.Expense += period.Expense
+		rsp.Net += period.Net
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type accountGraphRequest struct {
+	periodReportRequest
+	CategoryID int32 `form:"category_id"`
+	WalletID   int32 `form:"wallet_id"`
+}
+
+type accountGraphResponse struct {
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	Granularity string    `json:"granularity"`
+	// Points has one entry per period of the range. The cumulative values
+	// add up the periods from the start of the range.
+	Points []db.GetAccountGraphRow `json:"points"`
+}
+
+// getAccountGraph returns the time series of income, expense and balance of
+// the ledger for charts, optionally of a single category or wallet.
+func (server *Server) getAccountGraph(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req accountGraphRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !req.checkPeriods(ctx) {
+		return
+	}
+
+	arg := db.GetAccountGraphParams{
+		Granularity: req.Granularity,
+		StartDate:   req.From,
+		EndDate:     req.To,
+		LedgerID:    userClaims.LedgerID,
+	}
+	if req.CategoryID > 0 {
+		cat, ok := server.getUserCategory(ctx, userClaims, req.CategoryID, ActionRead)
+		if !ok {
+			return
+		}
+		arg.CategoryID = sql.NullInt32{Int32: cat.ID, Valid: true}
+	}
+	if req.WalletID > 0 {
+		wallet, ok := server.getUserWallet(ctx, userClaims, req.WalletID, ActionRead)
+		if !ok {
+			return
+		}
+		arg.WalletID = sql.NullInt32{Int32: wallet.ID, Valid: true}
+	}
+
+	points, err := server.store.GetAccountGraph(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountGraphResponse{
+		From:        req.From,
+		To:          req.To,
+		Granularity: req.Granularity,
+		Points:      points,
+	})
+}
+
+type categoryBreakdownRequest struct {
+	reportRangeRequest
+	Type string `form:"type" binding:"required,oneof=credit debit"`
+}
+
+type categoryBreakdownEntry struct {
+	// CategoryID and CategoryTitle are null for accounts without a category.
+	CategoryID    *int32  `json:"category_id"`
+	CategoryTitle *string `json:"category_title"`
+	Total         int64   `json:"total"`
+	Count         int64   `json:"count"`
+	// Share is the percentage of the total of the report.
+	Share   float64 `json:"share"`
+	Average float64 `json:"average"`
+}
+
+func newCategoryBreakdownEntry(row db.GetCategoryBreakdownRow) categoryBreakdownEntry {
+	entry := categoryBreakdownEntry{
+		Total:   row.Total,
+		Count:   row.Count,
+		Share:   row.Share,
+		Average: row.Average,
+	}
+	if row.CategoryID.Valid {
+		entry.CategoryID = &row.CategoryID.Int32
+	}
+	if row.CategoryTitle.Valid {
+		entry.CategoryTitle = &row.CategoryTitle.String
+	}
+	return entry
+}
+
+type categoryBreakdownResponse struct {
+	From       time.Time                `json:"from"`
+	To         time.Time                `json:"to"`
+	Type       string                   `json:"type"`
+	Total      int64                    `json:"total"`
+	Count      int64                    `json:"count"`
+	Categories []categoryBreakdownEntry `json:"categories"`
+}
+
+// getCategoryBreakdown reports the accounts of a type in a date range per
+// category, the largest total first.
+func (server *Server) getCategoryBreakdown(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req categoryBreakdownRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if !req.checkRange(ctx) {
+		return
+	}
+
+	rows, err := server.store.GetCategoryBreakdown(ctx, db.GetCategoryBreakdownParams{
+		LedgerID:  userClaims.LedgerID,
+		Type:      req.Type,
+		StartDate: req.From,
+		EndDate:   req.To,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := categoryBreakdownResponse{
+		From:       req.From,
+		To:         req.To,
+		Type:       req.Type,
+		Categories: make([]categoryBreakdownEntry, len(rows)),
+	}
+	for i, row := range rows {
+		rsp.Categories[i] = newCategoryBreakdownEntry(row)
+		rsp.Total += row.Total
+		rsp.Count += row.Count
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
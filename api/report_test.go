@@ -0,0 +1,174 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAccountsSummaryAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	income := ts.createCategory(t, user, "credit")
+	expense := ts.createCategory(t, user, "debit")
+
+	ts.createAccountOn(t, user, income, 1000, date(2023, time.May, 10))
+	ts.createAccountOn(t, user, expense, 300, date(2023, time.May, 20))
+	ts.createAccountOn(t, user, expense, 70, date(2023, time.June, 5))
+	ts.createAccountOn(t, user, expense, 50, date(2023, time.March, 1))
+	ts.createTransfer(t, user, ts.createWallet(t, user, 500), ts.createWallet(t, user, 0), 200)
+
+	recorder := ts.request(t, http.MethodGet, "/account/summary?from=2023-03-15&to=2023-06-10&granularity=month", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	summary := decodeBody[accountsSummaryResponse](t, recorder)
+	require.Equal(t, int64(1000), summary.Income)
+	require.Equal(t, int64(370), summary.Expense)
+	require.Equal(t, int64(630), summary.Net)
+
+	require.Len(t, summary.Periods, 4)
+	require.True(t, date(2023, time.March, 1).Equal(summary.Periods[0].Period))
+	require.Zero(t, summary.Periods[0].Expense)
+	require.Zero(t, summary.Periods[1].Income)
+	require.Equal(t, int64(1000), summary.Periods[2].Income)
+	require.Equal(t, int64(300), summary.Periods[2].Expense)
+	require.Equal(t, int64(700), summary.Periods[2].Net)
+	require.Equal(t, int64(-70), summary.Periods[3].Net)
+
+	// Weeks start on Monday; 2023-05-10 is a Wednesday.
+	recorder = ts.request(t, http.MethodGet, "/account/summary?from=2023-05-10&to=2023-05-21&granularity=week", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	summary = decodeBody[accountsSummaryResponse](t, recorder)
+	require.Len(t, summary.Periods, 2)
+	require.True(t, date(2023, time.May, 8).Equal(summary.Periods[0].Period))
+	require.Equal(t, int64(1000), summary.Periods[0].Income)
+	require.Equal(t, int64(300), summary.Periods[1].Expense)
+}
+
+func TestAccountsSummaryRangeAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	for _, query := range []string{
+		"from=2023-06-01&to=2023-05-01&granularity=month",
+		"from=2023-05-01&to=2023-06-01&granularity=quarter",
+		"from=2023-05-01&granularity=day",
+		"from=2020-01-01&to=2023-12-31&granularity=day",
+	} {
+		recorder := ts.request(t, http.MethodGet, "/account/summary?"+query, nil, user.Token)
+		require.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+
+	recorder := ts.request(t, http.MethodGet, "/account/summary?from=2020-01-01&to=2023-12-31&granularity=year", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	summary := decodeBody[accountsSummaryResponse](t, recorder)
+	require.Len(t, summary.Periods, 4)
+	require.Zero(t, summary.Net)
+}
+
+func TestCategoryBreakdownAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	food := ts.createCategory(t, user, "debit")
+	rent := ts.createCategory(t, user, "debit")
+	salary := ts.createCategory(t, user, "credit")
+
+	ts.createAccountOn(t, user, food, 100, date(2023, time.May, 2))
+	ts.createAccountOn(t, user, food, 200, date(2023, time.May, 12))
+	ts.createAccountOn(t, user, food, 33, date(2023, time.May, 31))
+	ts.createAccountOn(t, user, food, 500, date(2023, time.June, 1))
+	ts.createAccountOn(t, user, rent, 667, date(2023, time.May, 5))
+	ts.createAccountOn(t, user, salary, 3000, date(2023, time.May, 5))
+	ts.createTransfer(t, user, ts.createWallet(t, user, 500), ts.createWallet(t, user, 0), 200)
+
+	recorder := ts.request(t, http.MethodGet, "/account/breakdown?from=2023-05-01&to=2023-05-31&type=debit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	breakdown := decodeBody[categoryBreakdownResponse](t, recorder)
+	require.Equal(t, int64(1000), breakdown.Total)
+	require.Equal(t, int64(4), breakdown.Count)
+	require.Len(t, breakdown.Categories, 2)
+
+	largest := breakdown.Categories[0]
+	require.Equal(t, rent.ID, *largest.CategoryID)
+	require.Equal(t, rent.Title, *largest.CategoryTitle)
+	require.Equal(t, int64(667), largest.Total)
+	require.Equal(t, 66.7, largest.Share)
+	require.Equal(t, 667.0, largest.Average)
+
+	second := breakdown.Categories[1]
+	require.Equal(t, food.ID, *second.CategoryID)
+	require.Equal(t, int64(333), second.Total)
+	require.Equal(t, int64(3), second.Count)
+	require.Equal(t, 33.3, second.Share)
+	require.Equal(t, 111.0, second.Average)
+
+	recorder = ts.request(t, http.MethodGet, "/account/breakdown?from=2024-01-01&to=2024-01-31&type=credit", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Empty(t, decodeBody[categoryBreakdownResponse](t, recorder).Categories)
+
+	recorder = ts.request(t, http.MethodGet, "/account/breakdown?from=2023-05-01&to=2023-05-31&type=transfer", nil, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestAccountGraphAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+	salary := ts.createCategory(t, user, "credit")
+	food := ts.createCategory(t, user, "debit")
+	wallet := ts.createWallet(t, user, 0)
+
+	ts.createAccountOn(t, user, salary, 1000, date(2023, time.March, 5))
+	ts.createAccountOn(t, user, food, 300, date(2023, time.March, 20))
+	ts.createAccountOn(t, user, food, 200, date(2023, time.May, 8))
+	recorder := ts.request(t, http.MethodPost, "/account", gin.H{
+		"title":       "Dinner",
+		"type":        "debit",
+		"description": "Card",
+		"category_id": food.ID,
+		"date":        date(2023, time.May, 9),
+		"value":       50,
+		"wallet_id":   wallet.ID,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = ts.request(t, http.MethodGet, "/account/graph?from=2023-03-01&to=2023-05-31&granularity=month", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	points := decodeBody[accountGraphResponse](t, recorder).Points
+	require.Len(t, points, 3)
+	require.Equal(t, int64(700), points[0].Balance)
+	require.Zero(t, points[1].Balance)
+	require.Equal(t, int64(700), points[1].CumulativeBalance)
+	require.Equal(t, int64(250), points[2].Expense)
+	require.Equal(t, int64(1000), points[2].CumulativeIncome)
+	require.Equal(t, int64(550), points[2].CumulativeExpense)
+	require.Equal(t, int64(450), points[2].CumulativeBalance)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/account/graph?from=2023-03-01&to=2023-05-31&granularity=month&category_id=%d", food.ID), nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	points = decodeBody[accountGraphResponse](t, recorder).Points
+	require.Zero(t, points[2].CumulativeIncome)
+	require.Equal(t, int64(-550), points[2].CumulativeBalance)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/account/graph?from=2023-03-01&to=2023-05-31&granularity=month&wallet_id=%d", wallet.ID), nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	points = decodeBody[accountGraphResponse](t, recorder).Points
+	require.Equal(t, int64(50), points[2].CumulativeExpense)
+	require.Zero(t, points[0].Expense)
+
+	// Categories and wallets of other users are not found.
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/account/graph?from=2023-03-01&to=2023-05-31&granularity=month&category_id=%d", food.ID), nil, other.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/account/graph?from=2023-03-01&to=2023-05-31&granularity=month&wallet_id=%d", wallet.ID), nil, other.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/account/graph?type=debit", nil, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
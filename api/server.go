@@ -1,20 +1,33 @@
 package api
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/password"
+	"github.com/methyago/gofinance-backend/throttle"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
 )
 
 type Server struct {
-	store  *db.SQLStore
-	router *gin.Engine
+	config    util.Config
+	store     db.Store
+	keyring   *token.Keyring
+	passwords *password.Hasher
+	logins    *throttle.Limiter
+	mailer    mail.Mailer
+	policy    Policy
+	router    *gin.Engine
 }
 
 func CORSConfig() gin.HandlerFunc {
 	return func(context *gin.Context) {
 		context.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		context.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		context.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Ledger-ID")
 		context.Writer.Header().Set("Access-Control-Allow-Methods", "POST, DELETE, GET, PUT")
 
 		if context.Request.Method == "OPTIONS" {
@@ -26,34 +39,151 @@ func CORSConfig() gin.HandlerFunc {
 	}
 }
 
-func NewServer(store *db.SQLStore) Server {
-	server := &Server{store: store}
+func NewServer(config util.Config, store db.Store) (Server, error) {
+	keyring, err := token.LoadKeyring(config.Token)
+	if err != nil {
+		return Server{}, fmt.Errorf("cannot load token keys: %w", err)
+	}
+	passwords, err := password.NewHasher(password.ParamsFromConfig(config.Password))
+	if err != nil {
+		return Server{}, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+	logins, err := throttle.New(config.LoginThrottle, store)
+	if err != nil {
+		return Server{}, fmt.Errorf("cannot create login throttle: %w", err)
+	}
+	mailer, err := mail.New(config.Mail)
+	if err != nil {
+		return Server{}, fmt.Errorf("cannot create mailer: %w", err)
+	}
+	return newServer(config, store, keyring, passwords, logins, mailer, OwnerPolicy{})
+}
+
+func newServer(config util.Config, store db.Store, keyring *token.Keyring, passwords *password.Hasher, logins *throttle.Limiter, mailer mail.Mailer, policy Policy) (Server, error) {
+	server := &Server{
+		config:    config,
+		store:     store,
+		keyring:   keyring,
+		passwords: passwords,
+		logins:    logins,
+		mailer:    mailer,
+		policy:    policy,
+	}
 	router := gin.Default()
+	// The client IP keys the login throttle and is recorded with sessions,
+	// so X-Forwarded-For only counts when a trusted proxy sent it.
+	err := router.SetTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return Server{}, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	router.Use(CORSConfig())
 
 	router.POST("/user", server.createUser)
-	router.GET("/user/:username", server.getUser)
-	router.GET("/user/id/:id", server.getUserById)
-
-	router.POST("/category", server.createCategory)
-	router.GET("/category/:id", server.getCategory)
-	router.GET("/categories", server.getCategories)
-	router.DELETE("/category/:id", server.deleteCategory)
-	router.PUT("/category/:id", server.updateCategory)
-
-	router.POST("/account", server.createAccount)
-	router.GET("/account/:id", server.getAccount)
-	router.GET("/accounts", server.getAccounts)
-	router.DELETE("/account/:id", server.deleteAccount)
-	router.PUT("/account/:id", server.updateAccount)
-
-	router.GET("/account/graph", server.getAccountGraph)
-	router.GET("/account/reports", server.getAccountsReports)
-
 	router.POST("/login", server.login)
+	router.POST("/login/mfa", server.loginMFA)
+	router.POST("/token/refresh", server.refreshToken)
+	router.POST("/password/forgot", server.forgotPassword)
+	router.POST("/password/reset", server.resetPassword)
+	router.GET("/user/verify", server.verifyEmail)
+	router.POST("/user/verify/resend", server.resendVerification)
+	router.GET("/.well-known/jwks.json", server.getJWKS)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.keyring))
+	authRoutes.POST("/logout", server.logout)
+	authRoutes.POST("/logout/all", server.logoutAll)
+	authRoutes.GET("/sessions", server.getSessions)
+	authRoutes.DELETE("/session/:id", server.deleteSession)
+
+	authRoutes.GET("/me", server.getProfile)
+	authRoutes.PUT("/me/password", server.changePassword)
+	authRoutes.PUT("/me/email", server.changeEmail)
+	authRoutes.PUT("/me/username", server.changeUsername)
+	authRoutes.DELETE("/me", server.deleteProfile)
+
+	authRoutes.POST("/me/totp", server.enrollTOTP)
+	authRoutes.POST("/me/totp/confirm", server.confirmTOTP)
+	authRoutes.DELETE("/me/totp", server.disableTOTP)
+	authRoutes.POST("/me/totp/recovery-codes", server.regenerateRecoveryCodes)
+
+	authRoutes.GET("/user/:username", server.getUser)
+	authRoutes.GET("/user/id/:id", server.getUserById)
+
+	authRoutes.POST("/me/api-keys", server.createAPIKey)
+	authRoutes.GET("/me/api-keys", server.getAPIKeys)
+	authRoutes.DELETE("/me/api-keys/:id", server.revokeAPIKey)
+
+	authRoutes.POST("/ledgers", server.createLedger)
+	authRoutes.GET("/ledgers", server.getLedgers)
+	authRoutes.POST("/ledgers/invitations/accept", server.acceptLedgerInvitation)
+	authRoutes.GET("/ledger/:id", server.getLedger)
+	authRoutes.PUT("/ledger/:id", server.updateLedger)
+	authRoutes.DELETE("/ledger/:id", server.deleteLedger)
+	authRoutes.POST("/ledger/:id/invitations", server.createLedgerInvitation)
+	authRoutes.GET("/ledger/:id/invitations", server.getLedgerInvitations)
+	authRoutes.DELETE("/ledger/:id/invitations/:invitation_id", server.deleteLedgerInvitation)
+	authRoutes.PUT("/ledger/:id/members/:user_id", server.updateLedgerMember)
+	authRoutes.DELETE("/ledger/:id/members/:user_id", server.removeLedgerMember)
+
+	// Finance data of users with an unverified email may be read-only,
+	// depending on the verification policy. API keys only reach these
+	// routes, never the account management above. Requests work in the
+	// ledger named by the X-Ledger-ID header, the personal one by default.
+	dataRoutes := router.Group("/").Use(server.dataAuthMiddleware(), server.verifiedEmailMiddleware(), server.ledgerMiddleware())
+	dataRoutes.POST("/category", server.createCategory)
+	dataRoutes.GET("/category/:id", server.getCategory)
+	dataRoutes.GET("/categories", server.getCategories)
+	dataRoutes.DELETE("/category/:id", server.deleteCategory)
+	dataRoutes.PUT("/category/:id", server.updateCategory)
+
+	dataRoutes.POST("/account", server.createAccount)
+	dataRoutes.GET("/account/:id", server.getAccount)
+	dataRoutes.GET("/accounts", server.getAccounts)
+	dataRoutes.DELETE("/account/:id", server.deleteAccount)
+	dataRoutes.PUT("/account/:id", server.updateAccount)
+
+	dataRoutes.GET("/account/graph", server.getAccountGraph)
+	dataRoutes.GET("/account/reports", server.getAccountsReports)
+	dataRoutes.GET("/account/summary", server.getAccountsSummary)
+	dataRoutes.GET("/account/breakdown", server.getCategoryBreakdown)
+
+	dataRoutes.POST("/account/installments", server.createInstallments)
+	dataRoutes.GET("/account/installments/:id", server.getInstallments)
+	dataRoutes.DELETE("/account/installments/:id", server.deleteInstallments)
+	dataRoutes.PUT("/account/installments/:id", server.updateInstallments)
+
+	dataRoutes.POST("/wallet", server.createWallet)
+	dataRoutes.GET("/wallet/:id", server.getWallet)
+	dataRoutes.GET("/wallet/:id/balance", server.getWalletBalance)
+	dataRoutes.GET("/wallets", server.getWallets)
+	dataRoutes.DELETE("/wallet/:id", server.deleteWallet)
+	dataRoutes.PUT("/wallet/:id", server.updateWallet)
+
+	dataRoutes.POST("/transfer", server.createTransfer)
+	dataRoutes.GET("/transfer/:id", server.getTransfer)
+	dataRoutes.GET("/transfers", server.getTransfers)
+	dataRoutes.DELETE("/transfer/:id", server.deleteTransfer)
+	dataRoutes.PUT("/transfer/:id", server.updateTransfer)
+
+	dataRoutes.POST("/recurrence", server.createRecurrence)
+	dataRoutes.GET("/recurrence/:id", server.getRecurrence)
+	dataRoutes.GET("/recurrence/:id/preview", server.previewRecurrence)
+	dataRoutes.GET("/recurrences", server.getRecurrences)
+	dataRoutes.DELETE("/recurrence/:id", server.deleteRecurrence)
+	dataRoutes.PUT("/recurrence/:id", server.updateRecurrence)
+
+	// Every request to the admin API is audited, including denied ones.
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.keyring), server.auditMiddleware())
+	adminRoutes.GET("/users", server.requirePermission(PermissionViewUsers), server.listUsers)
+	adminRoutes.GET("/users/:id", server.requirePermission(PermissionViewUsers), server.getAdminUser)
+	adminRoutes.POST("/users/:id/disable", server.requirePermission(PermissionDisableUsers), server.disableUser)
+	adminRoutes.POST("/users/:id/enable", server.requirePermission(PermissionDisableUsers), server.enableUser)
+	adminRoutes.POST("/users/:id/password-reset", server.requirePermission(PermissionResetPasswords), server.forcePasswordReset)
+	adminRoutes.PUT("/users/:id/role", server.requirePermission(PermissionManageRoles), server.setUserRole)
+	adminRoutes.GET("/stats", server.requirePermission(PermissionViewStats), server.getSystemStats)
+	adminRoutes.GET("/audit-logs", server.requirePermission(PermissionViewAuditLog), server.getAuditLogs)
 
 	server.router = router
-	return *server
+	return *server, nil
 
 }
 
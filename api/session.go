@@ -0,0 +1,238 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+)
+
+// startSession opens a new session family for user and issues its first pair
+// of tokens.
+func (server *Server) startSession(ctx *gin.Context, user db.User) (loginResponseStruct, error) {
+	familyID, err := token.NewOpaqueToken()
+	if err != nil {
+		return loginResponseStruct{}, err
+	}
+	refreshToken, err := token.NewOpaqueToken()
+	if err != nil {
+		return loginResponseStruct{}, err
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: token.HashOpaqueToken(refreshToken),
+		ExpiresAt: time.Now().Add(server.config.Token.RefreshDuration),
+		UserAgent: ctx.Request.UserAgent(),
+		ClientIp:  ctx.ClientIP(),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return loginResponseStruct{}, err
+	}
+
+	return server.tokenResponse(user, refreshToken, session)
+}
+
+// tokenResponse signs a new access token for user and pairs it with the
+// refresh token of session.
+func (server *Server) tokenResponse(user db.User, refreshToken string, session db.Session) (loginResponseStruct, error) {
+	expirationTime := time.Now().Add(server.config.Token.Duration)
+	claims := &MyCustomClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+		},
+	}
+
+	accessToken, err := server.keyring.Sign(claims)
+	if err != nil {
+		return loginResponseStruct{}, err
+	}
+
+	return loginResponseStruct{
+		UserID:                user.ID,
+		UserName:              user.Username,
+		Token:                 accessToken,
+		TokenExpiresAt:        expirationTime,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (server *Server) refreshToken(ctx *gin.Context) {
+	var req refreshTokenRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	refreshToken, err := token.NewOpaqueToken()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.RotateSessionTx(ctx, db.RotateSessionTxParams{
+		TokenHash:    token.HashOpaqueToken(req.RefreshToken),
+		NewTokenHash: token.HashOpaqueToken(refreshToken),
+		NewExpiresAt: time.Now().Add(server.config.Token.RefreshDuration),
+		UserAgent:    ctx.Request.UserAgent(),
+		ClientIp:     ctx.ClientIP(),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotFound) || errors.Is(err, db.ErrSessionExpired) ||
+			errors.Is(err, db.ErrSessionRevoked) || errors.Is(err, db.ErrSessionReused) {
+			abortUnauthorized(ctx)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserById(ctx, session.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if user.DisabledAt.Valid {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountDisabled))
+		return
+	}
+
+	result, err := server.tokenResponse(user, refreshToken, session)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// logout revokes the session the refresh token belongs to. Access tokens
+// already issued stay valid until they expire, which is why they are short
+// lived.
+func (server *Server) logout(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req logoutRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSessionByTokenHash(ctx, token.HashOpaqueToken(req.RefreshToken))
+	if err == nil && session.UserID == userClaims.UserID {
+		err = server.store.RevokeSessionFamily(ctx, session.FamilyID)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+// logoutAll revokes every session of the authenticated user.
+func (server *Server) logoutAll(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	err := server.store.RevokeUserSessions(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+// sessionResponse describes a logged in device. It leaves out the token hash
+// and the family id.
+type sessionResponse struct {
+	ID         int32     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	ClientIP   string    `json:"client_ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		ClientIP:   session.ClientIp,
+		CreatedAt:  session.StartedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+	}
+}
+
+// getSessions lists the devices the authenticated user is logged in on, most
+// recently used first.
+func (server *Server) getSessions(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	sessions, err := server.store.ListActiveSessions(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	result := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, newSessionResponse(session))
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+type deleteSessionRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+// deleteSession logs a single device out by revoking its session family.
+// Like logout, access tokens it already holds keep working until they
+// expire, at most one access token lifetime later.
+func (server *Server) deleteSession(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteSessionRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	session, err := server.store.GetSession(ctx, db.GetSessionParams{
+		ID:     req.ID,
+		UserID: userClaims.UserID,
+	})
+	if !server.authorizeRow(ctx, userClaims, ActionWrite, session.UserID, err) {
+		return
+	}
+
+	err = server.store.RevokeSessionFamily(ctx, session.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
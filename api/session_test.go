@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) refresh(t *testing.T, refreshToken string) (loginResponseStruct, int) {
+	recorder := ts.request(t, http.MethodPost, "/token/refresh", gin.H{"refresh_token": refreshToken}, "")
+	if recorder.Code != http.StatusOK {
+		return loginResponseStruct{}, recorder.Code
+	}
+	return decodeBody[loginResponseStruct](t, recorder), recorder.Code
+}
+
+func TestRefreshTokenAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	require.NotEmpty(t, user.RefreshToken)
+
+	rotated, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, user.ID, rotated.UserID)
+	require.NotEmpty(t, rotated.Token)
+	require.NotEqual(t, user.RefreshToken, rotated.RefreshToken)
+	require.True(t, rotated.RefreshTokenExpiresAt.After(rotated.TokenExpiresAt))
+
+	recorder := ts.request(t, http.MethodGet, "/wallets", nil, rotated.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	again, code := ts.refresh(t, rotated.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+
+	_, code = ts.refresh(t, "not-a-refresh-token")
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	recorder = ts.request(t, http.MethodPost, "/token/refresh", gin.H{}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	_, code = ts.refresh(t, again.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestRefreshTokenReuseRevokesFamilyAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+
+	rotated, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+
+	// Replaying the already exchanged token revokes the whole family, so the
+	// token the legitimate client holds stops working too.
+	_, code = ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	_, code = ts.refresh(t, rotated.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	// Other sessions are not affected.
+	_, code = ts.refresh(t, other.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestExpiredRefreshTokenAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	refreshToken, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+	_, err = ts.store.CreateSession(context.Background(), db.CreateSessionParams{
+		UserID:    user.ID,
+		FamilyID:  "expired",
+		TokenHash: token.HashOpaqueToken(refreshToken),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, code := ts.refresh(t, refreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestLogoutAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	intruder := ts.createUserAndLogin(t)
+
+	// Another user cannot end the session with a stolen refresh token.
+	recorder := ts.request(t, http.MethodPost, "/logout", gin.H{"refresh_token": user.RefreshToken}, intruder.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	rotated, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+
+	recorder = ts.request(t, http.MethodPost, "/logout", gin.H{"refresh_token": rotated.RefreshToken}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	_, code = ts.refresh(t, rotated.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	recorder = ts.request(t, http.MethodPost, "/logout", gin.H{"refresh_token": rotated.RefreshToken}, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestLogoutAllAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodPost, "/login", gin.H{
+		"username": user.Username,
+		"password": user.PlainPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	second := decodeBody[loginResponseStruct](t, recorder)
+
+	recorder = ts.request(t, http.MethodPost, "/logout/all", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	_, code := ts.refresh(t, user.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	_, code = ts.refresh(t, second.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+	_, code = ts.refresh(t, other.RefreshToken)
+	require.Equal(t, http.StatusOK, code)
+}
+
+// requestFrom sends a public request from a device identified by userAgent
+// and ip.
+func (ts *testServer) requestFrom(t *testing.T, url string, body any, userAgent, ip string) loginResponseStruct {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.RemoteAddr = ip + ":40000"
+
+	recorder := ts.serve(t, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	return decodeBody[loginResponseStruct](t, recorder)
+}
+
+func TestSessionsAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+
+	credentials := gin.H{"username": user.Username, "password": user.PlainPassword}
+	phone := ts.requestFrom(t, "/login", credentials, "phone", "10.0.0.2")
+	laptop := ts.requestFrom(t, "/login", credentials, "laptop", "10.0.0.3")
+
+	// Rotating keeps a single entry per device and records where it was
+	// last used from.
+	ts.requestFrom(t, "/token/refresh", gin.H{"refresh_token": laptop.RefreshToken}, "laptop", "10.0.0.4")
+
+	recorder := ts.request(t, http.MethodGet, "/sessions", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotContains(t, recorder.Body.String(), "token_hash")
+	require.NotContains(t, recorder.Body.String(), "family_id")
+	sessions := decodeBody[[]sessionResponse](t, recorder)
+	require.Len(t, sessions, 3)
+	require.Equal(t, "laptop", sessions[0].UserAgent)
+	require.Equal(t, "10.0.0.4", sessions[0].ClientIP)
+	require.True(t, sessions[0].CreatedAt.Before(sessions[0].LastUsedAt))
+	require.Equal(t, "phone", sessions[1].UserAgent)
+	require.Equal(t, "10.0.0.2", sessions[1].ClientIP)
+
+	// Sessions of another user cannot be revoked.
+	url := fmt.Sprintf("/session/%d", sessions[1].ID)
+	recorder = ts.request(t, http.MethodDelete, url, nil, other.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	_, code := ts.refresh(t, phone.RefreshToken)
+	require.Equal(t, http.StatusUnauthorized, code)
+
+	recorder = ts.request(t, http.MethodGet, "/sessions", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	sessions = decodeBody[[]sessionResponse](t, recorder)
+	require.Len(t, sessions, 2)
+	for _, session := range sessions {
+		require.NotEqual(t, "phone", session.UserAgent)
+	}
+
+	recorder = ts.request(t, http.MethodGet, "/sessions", nil, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
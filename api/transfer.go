@@ -0,0 +1,183 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+type createTransferRequest struct {
+	FromWalletID int32     `json:"from_wallet_id" binding:"required"`
+	ToWalletID   int32     `json:"to_wallet_id" binding:"required,nefield=FromWalletID"`
+	Description  string    `json:"description"`
+	Value        int32     `json:"value" binding:"required,min=1"`
+	Date         time.Time `json:"date" binding:"required"`
+}
+
+func (server *Server) createTransfer(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req createTransferRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, ok := server.getUserWallet(ctx, userClaims, req.FromWalletID, ActionWrite)
+	if !ok {
+		return
+	}
+	to, ok := server.getUserWallet(ctx, userClaims, req.ToWalletID, ActionWrite)
+	if !ok {
+		return
+	}
+	if from.Currency != to.Currency {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error:": "Wallets have different currencies"})
+		return
+	}
+
+	arg := db.TransferTxParams{
+		UserID:       userClaims.UserID,
+		FromWalletID: req.FromWalletID,
+		ToWalletID:   req.ToWalletID,
+		Description:  req.Description,
+		Value:        req.Value,
+		Date:         req.Date,
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// getUserTransfer loads a transfer of the authenticated user with its accounts
+// and checks the policy for action. It returns false when a response was
+// written.
+func (server *Server) getUserTransfer(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.TransferTxResult, bool) {
+	result, err := server.store.GetTransferDetails(ctx, db.GetTransferParams{
+		ID:     id,
+		UserID: userClaims.UserID,
+	})
+	return result, server.authorizeRow(ctx, userClaims, action, result.Transfer.UserID, err)
+}
+
+type getTransferRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req getTransferRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, ok := server.getUserTransfer(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) getTransfers(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	transfers, err := server.store.GetTransfers(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+type updateTransferIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type updateTransferRequest struct {
+	Description string    `json:"description"`
+	Value       int32     `json:"value" binding:"required,min=1"`
+	Date        time.Time `json:"date" binding:"required"`
+}
+
+func (server *Server) updateTransfer(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri updateTransferIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody updateTransferRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserTransfer(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateTransferTxParams{
+		ID:          reqUri.ID,
+		Description: reqBody.Description,
+		Value:       reqBody.Value,
+		Date:        reqBody.Date,
+		UserID:      userClaims.UserID,
+	}
+
+	result, err := server.store.UpdateTransferTx(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+type deleteTransferRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) deleteTransfer(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteTransferRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserTransfer(ctx, userClaims, req.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeleteTransfer(ctx, db.DeleteTransferParams{
+		ID:     req.ID,
+		UserID: userClaims.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
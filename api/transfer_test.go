@@ -0,0 +1,91 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func (ts *testServer) createTransfer(t *testing.T, user testUser, from, to db.Wallet, value int32) db.TransferTxResult {
+	recorder := ts.request(t, http.MethodPost, "/transfer", gin.H{
+		"from_wallet_id": from.ID,
+		"to_wallet_id":   to.ID,
+		"description":    "savings",
+		"value":          value,
+		"date":           time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC),
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	return decodeBody[db.TransferTxResult](t, recorder)
+}
+
+func TestCreateTransferAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	from := ts.createWallet(t, user, 1000)
+	to := ts.createWallet(t, user, 0)
+
+	result := ts.createTransfer(t, user, from, to, 300)
+	require.Equal(t, from.ID, result.FromAccount.WalletID.Int32)
+	require.Equal(t, to.ID, result.ToAccount.WalletID.Int32)
+	require.Equal(t, "debit", result.FromAccount.Type)
+	require.Equal(t, "credit", result.ToAccount.Type)
+
+	recorder := ts.request(t, http.MethodGet, fmt.Sprintf("/wallet/%d/balance", from.ID), nil, user.Token)
+	require.Equal(t, int64(700), decodeBody[db.GetWalletBalanceRow](t, recorder).Balance)
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/wallet/%d/balance", to.ID), nil, user.Token)
+	require.Equal(t, int64(300), decodeBody[db.GetWalletBalanceRow](t, recorder).Balance)
+
+	recorder = ts.request(t, http.MethodGet, "/account/reports?type=debit", nil, user.Token)
+	require.Equal(t, int64(0), decodeBody[int64](t, recorder))
+	recorder = ts.request(t, http.MethodGet, "/account/graph?from=2023-05-01&to=2023-05-31&granularity=month", nil, user.Token)
+	require.Zero(t, decodeBody[accountGraphResponse](t, recorder).Points[0].CumulativeIncome)
+
+	recorder = ts.request(t, http.MethodPost, "/transfer", gin.H{
+		"from_wallet_id": from.ID,
+		"to_wallet_id":   from.ID,
+		"value":          10,
+		"date":           time.Now(),
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestUpdateDeleteTransferAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	from := ts.createWallet(t, user, 1000)
+	to := ts.createWallet(t, user, 0)
+	created := ts.createTransfer(t, user, from, to, 300)
+
+	url := fmt.Sprintf("/transfer/%d", created.Transfer.ID)
+	recorder := ts.request(t, http.MethodPut, url, gin.H{
+		"description": "rent reserve",
+		"value":       450,
+		"date":        time.Date(2023, time.May, 2, 0, 0, 0, 0, time.UTC),
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	updated := decodeBody[db.TransferTxResult](t, recorder)
+	require.Equal(t, int32(450), updated.FromAccount.Value)
+	require.Equal(t, int32(450), updated.ToAccount.Value)
+
+	legURL := fmt.Sprintf("/account/%d", created.FromAccount.ID)
+	recorder = ts.request(t, http.MethodDelete, legURL, nil, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	recorder = ts.request(t, http.MethodPut, legURL, gin.H{"title": "x", "value": 1}, user.Token)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/transfers", nil, user.Token)
+	require.Len(t, decodeBody[[]db.Transfer](t, recorder), 1)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, legURL, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
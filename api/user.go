@@ -1,54 +1,115 @@
 package api
 
 import (
-	"bytes"
-	"crypto/sha512"
 	"database/sql"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/methyago/gofinance-backend/db/sqlc"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/methyago/gofinance-backend/validation"
 )
 
+// userResponse is the only shape a user is sent to clients in. It leaves out
+// the password hash and anything else that is a credential; handlers must
+// never serialize db.User itself.
+type userResponse struct {
+	ID              int32      `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func newUserResponse(user db.User) userResponse {
+	rsp := userResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+	}
+	if user.EmailVerifiedAt.Valid {
+		rsp.EmailVerifiedAt = &user.EmailVerifiedAt.Time
+	}
+	return rsp
+}
+
 type createUserRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
-	Email    string `json:"email" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+}
+
+func validateNewUser(req createUserRequest) error {
+	err := validation.Username(req.Username)
+	if err != nil {
+		return err
+	}
+	err = validation.Email(req.Email)
+	if err != nil {
+		return err
+	}
+	return validation.Password(req.Password, req.Username, req.Email)
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
 	err := ctx.ShouldBindJSON(&req)
+	if err == nil {
+		err = validateNewUser(req)
+	}
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	hashedInput := sha512.Sum512_256([]byte(req.Password))
-	trimmedHash := bytes.Trim(hashedInput[:], "\x00")
-	preparedPassword := string(trimmedHash)
-
-	passwordHashInBytes, err := bcrypt.GenerateFromPassword([]byte(preparedPassword), bcrypt.DefaultCost)
+	passwordHashed, err := server.passwords.Hash(req.Password)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	var passwordHashed = string(passwordHashInBytes)
 	arg := db.CreateUserParams{
 		Username: req.Username,
 		Password: passwordHashed,
 		Email:    req.Email,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		storeError(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
+	// The account exists either way; a lost email can be sent again.
+	err = server.sendVerificationEmail(ctx, user)
+	if err != nil {
+		log.Printf("cannot send verification email to user %d: %v", user.ID, err)
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// canViewUser checks that the authenticated user may look up the user with
+// id: only themselves, unless their current role may view users. The role of
+// the token is not trusted, as it outlives demotions. Denials answer 404 like
+// unknown users. It returns false when a response was written.
+func (server *Server) canViewUser(ctx *gin.Context, id int32) bool {
+	if getUserClaims(ctx).UserID == id {
+		return true
+	}
+	user, ok := server.getCurrentUser(ctx)
+	if !ok {
+		return false
+	}
+	if user.DisabledAt.Valid || !hasPermission(user.Role, PermissionViewUsers) {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return false
+	}
+	return true
 }
 
 type getUserRequest struct {
@@ -72,8 +133,11 @@ func (server *Server) getUser(ctx *gin.Context) {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if !server.canViewUser(ctx, user.ID) {
+		return
+	}
 
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
 type getUserByIdRequest struct {
@@ -88,6 +152,11 @@ func (server *Server) getUserById(ctx *gin.Context) {
 		return
 	}
 
+	// Other users are not even looked up, so their ids cannot be probed.
+	if !server.canViewUser(ctx, req.ID) {
+		return
+	}
+
 	user, err := server.store.GetUserById(ctx, req.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -98,5 +167,5 @@ func (server *Server) getUserById(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
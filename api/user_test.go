@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateUserAPI(t *testing.T) {
+	ts := newTestServer(t)
+
+	arg := gin.H{
+		"username": util.RandomString(8),
+		"password": util.RandomString(12),
+		"email":    util.RandomEmail(),
+	}
+	recorder := ts.request(t, http.MethodPost, "/user", arg, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	user := decodeBody[userResponse](t, recorder)
+	require.Equal(t, arg["username"], user.Username)
+	require.Equal(t, arg["email"], user.Email)
+	require.Equal(t, db.RoleUser, user.Role)
+
+	recorder = ts.request(t, http.MethodPost, "/user", gin.H{"username": "missing"}, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGetUserAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	recorder := ts.request(t, http.MethodGet, "/user/"+user.Username, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, user.ID, decodeBody[userResponse](t, recorder).ID)
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/user/id/%d", user.ID), nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, user.Username, decodeBody[userResponse](t, recorder).Username)
+
+	recorder = ts.request(t, http.MethodGet, "/user/"+util.RandomString(9), nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/user/id/9999", nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/"+user.Username, nil, "")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
+
+func TestGetOtherUserAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	other := ts.createUserAndLogin(t)
+
+	// Other users look exactly like users that do not exist.
+	recorder := ts.request(t, http.MethodGet, "/user/"+other.Username, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	missing := ts.request(t, http.MethodGet, "/user/"+util.RandomString(9), nil, user.Token)
+	require.Equal(t, missing.Body.String(), recorder.Body.String())
+
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/user/id/%d", other.ID), nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	_, err := ts.store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{ID: user.ID, Role: db.RoleAdmin})
+	require.NoError(t, err)
+	admin := ts.loginAs(t, user)
+
+	recorder = ts.request(t, http.MethodGet, "/user/"+other.Username, nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, other.ID, decodeBody[userResponse](t, recorder).ID)
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/user/id/%d", other.ID), nil, admin.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/id/9999", nil, admin.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestGetOtherUserAfterDemotionAPI(t *testing.T) {
+	ts := newTestServer(t)
+	support := ts.createStaffAndLogin(t, db.RoleSupport)
+	other := ts.createUserAndLogin(t)
+	url := fmt.Sprintf("/user/id/%d", other.ID)
+
+	recorder := ts.request(t, http.MethodGet, url, nil, support.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// The role in the token no longer counts once it was taken away.
+	_, err := ts.store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{ID: support.ID, Role: db.RoleUser})
+	require.NoError(t, err)
+	recorder = ts.request(t, http.MethodGet, url, nil, support.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/"+other.Username, nil, support.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Neither does it for disabled staff.
+	_, err = ts.store.UpdateUserRole(context.Background(), db.UpdateUserRoleParams{ID: support.ID, Role: db.RoleSupport})
+	require.NoError(t, err)
+	_, err = ts.store.DisableUser(context.Background(), support.ID)
+	require.NoError(t, err)
+	recorder = ts.request(t, http.MethodGet, url, nil, support.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// Everyone may still look up themselves.
+	recorder = ts.request(t, http.MethodGet, fmt.Sprintf("/user/id/%d", other.ID), nil, other.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestCredentialFields(t *testing.T) {
+	// The guard every test response goes through would catch db.User.
+	data, err := json.Marshal(gin.H{"users": []db.User{{ID: 1, Password: "hash"}}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"users.password"}, credentialFields(data))
+
+	data, err = json.Marshal(newUserResponse(db.User{ID: 1, Password: "hash"}))
+	require.NoError(t, err)
+	require.Empty(t, credentialFields(data))
+	require.NotContains(t, string(data), "hash")
+}
+
+func TestCreateUserValidationAPI(t *testing.T) {
+	ts := newTestServer(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+	}{
+		{"ShortUsername", gin.H{"username": "ab", "password": util.RandomString(12), "email": util.RandomEmail()}},
+		{"UsernameCharset", gin.H{"username": "bob smith", "password": util.RandomString(12), "email": util.RandomEmail()}},
+		{"InvalidEmail", gin.H{"username": util.RandomString(8), "password": util.RandomString(12), "email": "bob@localhost"}},
+		{"ShortPassword", gin.H{"username": util.RandomString(8), "password": "short", "email": util.RandomEmail()}},
+		{"CommonPassword", gin.H{"username": util.RandomString(8), "password": "password1", "email": util.RandomEmail()}},
+		{"PasswordWithUsername", gin.H{"username": "robert", "password": "robert-2024!", "email": util.RandomEmail()}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := ts.request(t, http.MethodPost, "/user", tc.body, "")
+			require.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+		})
+	}
+}
+
+func TestCreateUserConflictAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	// Usernames differing only in case belong to the same user.
+	recorder := ts.request(t, http.MethodPost, "/user", gin.H{
+		"username": strings.ToUpper(user.Username),
+		"password": util.RandomString(12),
+		"email":    util.RandomEmail(),
+	}, "")
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Contains(t, recorder.Body.String(), errUsernameTaken.Error())
+
+	recorder = ts.request(t, http.MethodPost, "/user", gin.H{
+		"username": util.RandomString(8),
+		"password": util.RandomString(12),
+		"email":    user.Email,
+	}, "")
+	require.Equal(t, http.StatusConflict, recorder.Code)
+	require.Contains(t, recorder.Body.String(), errEmailTaken.Error())
+
+	require.Equal(t, http.StatusOK, ts.login(t, strings.ToUpper(user.Username), user.PlainPassword))
+}
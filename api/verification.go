@@ -0,0 +1,127 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/mail"
+	"github.com/methyago/gofinance-backend/token"
+	"github.com/methyago/gofinance-backend/util"
+)
+
+const verifyEmailSubject = "Confirm your email address"
+
+var errEmailNotVerified = errors.New("email address not verified")
+
+// sendVerificationEmail issues a verification token for the email of user
+// and mails it.
+func (server *Server) sendVerificationEmail(ctx *gin.Context, user db.User) error {
+	verificationToken, err := token.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	_, err = server.store.CreateEmailVerificationToken(ctx, db.CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: token.HashOpaqueToken(verificationToken),
+		ExpiresAt: time.Now().Add(server.config.EmailVerificationDuration),
+	})
+	if err != nil {
+		return err
+	}
+
+	server.sendInBackground(mail.Message{
+		To:      user.Email,
+		Subject: verifyEmailSubject,
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to confirm your email address. It expires in %s.\n\n%s\n",
+			user.Username, server.config.EmailVerificationDuration, tokenLink(server.config.EmailVerificationURL, verificationToken)),
+	})
+	return nil
+}
+
+type verifyEmailRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	err := ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, err = server.store.VerifyEmailTx(ctx, token.HashOpaqueToken(req.Token))
+	if err != nil {
+		if errors.Is(err, db.ErrVerificationTokenInvalid) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// resendVerification mails a new verification link. Like forgotPassword it
+// answers the same way whether or not the address belongs to a user.
+func (server *Server) resendVerification(ctx *gin.Context) {
+	var req resendVerificationRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err == nil && !user.EmailVerifiedAt.Valid {
+		err = server.sendVerificationEmail(ctx, user)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.Printf("cannot resend verification email: %v", err)
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+// verifiedEmailMiddleware rejects requests that change data from users whose
+// email is not verified, when the policy asks for it.
+func (server *Server) verifiedEmailMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if server.config.EmailVerificationPolicy != util.EmailVerificationWrites {
+			ctx.Next()
+			return
+		}
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			ctx.Next()
+			return
+		}
+
+		user, err := server.store.GetUserById(ctx, getUserClaims(ctx).UserID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				abortUnauthorized(ctx)
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		if !user.EmailVerifiedAt.Valid {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(errEmailNotVerified))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+var verifyLinkPattern = regexp.MustCompile(`https://app\.example\.com/verify\?\S+`)
+
+// signUp creates a user without logging in and returns the token of the
+// verification email.
+func (ts *testServer) signUp(t *testing.T) (testUser, string) {
+	password := util.RandomString(12)
+	recorder := ts.request(t, http.MethodPost, "/user", gin.H{
+		"username": util.RandomString(8),
+		"password": password,
+		"email":    util.RandomEmail(),
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	user := decodeBody[db.User](t, recorder)
+	require.False(t, user.EmailVerifiedAt.Valid)
+
+	return testUser{User: user, PlainPassword: password}, ts.verificationToken(t, user.Email)
+}
+
+func (ts *testServer) verificationToken(t *testing.T, email string) string {
+	msg := ts.outbox.next(t, email, verifyEmailSubject)
+	link, err := url.Parse(verifyLinkPattern.FindString(msg.Body))
+	require.NoError(t, err)
+	verificationToken := link.Query().Get("token")
+	require.NotEmpty(t, verificationToken)
+	return verificationToken
+}
+
+func TestVerifyEmailAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user, verificationToken := ts.signUp(t)
+
+	recorder := ts.request(t, http.MethodGet, "/user/verify?token="+url.QueryEscape(verificationToken), nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	stored, err := ts.store.GetUserById(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, stored.EmailVerifiedAt.Valid)
+
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token="+url.QueryEscape(verificationToken), nil, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token=unknown", nil, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder = ts.request(t, http.MethodGet, "/user/verify", nil, "")
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestResendVerificationAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user, first := ts.signUp(t)
+
+	recorder := ts.request(t, http.MethodPost, "/user/verify/resend", gin.H{"email": user.Email}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	second := ts.verificationToken(t, user.Email)
+	require.NotEqual(t, first, second)
+
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token="+url.QueryEscape(second), nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Verified users and unknown addresses get the same answer and no email.
+	for _, email := range []string{user.Email, util.RandomEmail()} {
+		recorder = ts.request(t, http.MethodPost, "/user/verify/resend", gin.H{"email": email}, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+		time.Sleep(50 * time.Millisecond)
+		_, sent := ts.outbox.take(email, verifyEmailSubject)
+		require.False(t, sent)
+	}
+}
+
+func TestEmailVerificationLoginPolicyAPI(t *testing.T) {
+	ts := newTestServerWithConfig(t, func(config *util.Config) {
+		config.EmailVerificationPolicy = util.EmailVerificationLogin
+	})
+	user, verificationToken := ts.signUp(t)
+
+	require.Equal(t, http.StatusForbidden, ts.login(t, user.Username, user.PlainPassword))
+	// A wrong password is still only unauthorized.
+	require.Equal(t, http.StatusUnauthorized, ts.login(t, user.Username, util.RandomString(12)))
+
+	recorder := ts.request(t, http.MethodGet, "/user/verify?token="+url.QueryEscape(verificationToken), nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, http.StatusOK, ts.login(t, user.Username, user.PlainPassword))
+}
+
+func TestEmailVerificationWritesPolicyAPI(t *testing.T) {
+	ts := newTestServerWithConfig(t, func(config *util.Config) {
+		config.EmailVerificationPolicy = util.EmailVerificationWrites
+	})
+	user := ts.createUserAndLogin(t)
+	verificationToken := ts.verificationToken(t, user.Email)
+
+	recorder := ts.request(t, http.MethodGet, "/wallets", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	recorder = ts.request(t, http.MethodPost, "/wallet", gin.H{
+		"name":     util.RandomString(8),
+		"type":     "checking",
+		"currency": "BRL",
+	}, user.Token)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// Managing the account itself is still allowed.
+	recorder = ts.request(t, http.MethodPost, "/logout/all", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/user/verify?token="+url.QueryEscape(verificationToken), nil, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	ts.createWallet(t, user, 0)
+}
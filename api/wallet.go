@@ -0,0 +1,217 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+type createWalletRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Type           string `json:"type" binding:"required,oneof=checking savings credit_card cash investment"`
+	Currency       string `json:"currency" binding:"required,len=3,uppercase"`
+	OpeningBalance int32  `json:"opening_balance"`
+}
+
+func (server *Server) createWallet(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req createWalletRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateWalletParams{
+		UserID:         userClaims.UserID,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
+		OpeningBalance: req.OpeningBalance,
+	}
+
+	wallet, err := server.store.CreateWallet(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, wallet)
+}
+
+// getUserWallet loads a wallet of the authenticated user and checks the
+// policy for action. It returns false when a response was written.
+func (server *Server) getUserWallet(ctx *gin.Context, userClaims *UserClaims, id int32, action Action) (db.Wallet, bool) {
+	wallet, err := server.store.GetWallet(ctx, db.GetWalletParams{
+		ID:     id,
+		UserID: userClaims.UserID,
+	})
+	return wallet, server.authorizeRow(ctx, userClaims, action, wallet.UserID, err)
+}
+
+type getWalletRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) getWallet(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req getWalletRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	wallet, ok := server.getUserWallet(ctx, userClaims, req.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, wallet)
+}
+
+func (server *Server) getWallets(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	wallets, err := server.store.GetWallets(ctx, userClaims.UserID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, wallets)
+}
+
+type updateWalletIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type updateWalletRequest struct {
+	Name           string `json:"name" binding:"required"`
+	Type           string `json:"type" binding:"required,oneof=checking savings credit_card cash investment"`
+	Currency       string `json:"currency" binding:"required,len=3,uppercase"`
+	OpeningBalance int32  `json:"opening_balance"`
+}
+
+func (server *Server) updateWallet(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri updateWalletIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var reqBody updateWalletRequest
+	err = ctx.ShouldBindJSON(&reqBody)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserWallet(ctx, userClaims, reqUri.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateWalletParams{
+		ID:             reqUri.ID,
+		Name:           reqBody.Name,
+		Type:           reqBody.Type,
+		Currency:       reqBody.Currency,
+		OpeningBalance: reqBody.OpeningBalance,
+		UserID:         userClaims.UserID,
+	}
+
+	wallet, err := server.store.UpdateWallet(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, wallet)
+}
+
+type deleteWalletRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+func (server *Server) deleteWallet(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var req deleteWalletRequest
+	err := ctx.ShouldBindUri(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, ok := server.getUserWallet(ctx, userClaims, req.ID, ActionWrite)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeleteWallet(ctx, db.DeleteWalletParams{
+		ID:     req.ID,
+		UserID: userClaims.UserID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, true)
+}
+
+type getWalletBalanceIdRequest struct {
+	ID int32 `uri:"id" binding:"required"`
+}
+
+type getWalletBalanceRequest struct {
+	Date time.Time `form:"date" time_format:"2006-01-02"`
+}
+
+func (server *Server) getWalletBalance(ctx *gin.Context) {
+	userClaims := getUserClaims(ctx)
+
+	var reqUri getWalletBalanceIdRequest
+	err := ctx.ShouldBindUri(&reqUri)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getWalletBalanceRequest
+	err = ctx.ShouldBindQuery(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Date.IsZero() {
+		req.Date = time.Now()
+	}
+
+	_, ok := server.getUserWallet(ctx, userClaims, reqUri.ID, ActionRead)
+	if !ok {
+		return
+	}
+
+	arg := db.GetWalletBalanceParams{
+		WalletID: reqUri.ID,
+		UserID:   userClaims.UserID,
+		Date:     req.Date,
+	}
+
+	balance, err := server.store.GetWalletBalance(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance)
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+
+	wallet := ts.createWallet(t, user, 1000)
+	require.Equal(t, user.ID, wallet.UserID)
+	require.Equal(t, int32(1000), wallet.OpeningBalance)
+
+	url := fmt.Sprintf("/wallet/%d", wallet.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, wallet.Name, decodeBody[db.Wallet](t, recorder).Name)
+
+	recorder = ts.request(t, http.MethodPut, url, gin.H{
+		"name":            "Savings",
+		"type":            "savings",
+		"currency":        "USD",
+		"opening_balance": 10,
+	}, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "Savings", decodeBody[db.Wallet](t, recorder).Name)
+
+	recorder = ts.request(t, http.MethodPost, "/wallet", gin.H{
+		"name":     "Bad",
+		"type":     "piggy_bank",
+		"currency": "BRL",
+	}, user.Token)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, "/wallets", nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, decodeBody[[]db.Wallet](t, recorder), 1)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestWalletBalanceAPI(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUserAndLogin(t)
+	wallet := ts.createWallet(t, user, 1000)
+	expense := ts.createCategory(t, user, "debit")
+	income := ts.createCategory(t, user, "credit")
+
+	entries := []struct {
+		cat   db.Category
+		value int32
+		date  time.Time
+	}{
+		{income, 500, time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{expense, 200, time.Date(2023, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{expense, 100, time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, entry := range entries {
+		recorder := ts.request(t, http.MethodPost, "/account", gin.H{
+			"title":       "entry",
+			"type":        entry.cat.Type,
+			"description": "entry",
+			"category_id": entry.cat.ID,
+			"date":        entry.date,
+			"value":       entry.value,
+			"wallet_id":   wallet.ID,
+		}, user.Token)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	url := fmt.Sprintf("/wallet/%d/balance?date=2023-03-31", wallet.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	balance := decodeBody[db.GetWalletBalanceRow](t, recorder)
+	require.Equal(t, int64(500), balance.Income)
+	require.Equal(t, int64(200), balance.Expense)
+	require.Equal(t, int64(1300), balance.Balance)
+
+	url = fmt.Sprintf("/wallet/%d/balance", wallet.ID)
+	recorder = ts.request(t, http.MethodGet, url, nil, user.Token)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, int64(1200), decodeBody[db.GetWalletBalanceRow](t, recorder).Balance)
+}
+
+func TestWalletOwnedByAnotherUserAPI(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUserAndLogin(t)
+	intruder := ts.createUserAndLogin(t)
+	wallet := ts.createWallet(t, owner, 1000)
+
+	url := fmt.Sprintf("/wallet/%d", wallet.ID)
+	recorder := ts.request(t, http.MethodGet, url, nil, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodGet, url+"/balance", nil, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = ts.request(t, http.MethodDelete, url, nil, intruder.Token)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "recurrence_id";
+DROP TABLE IF EXISTS "recurrences";
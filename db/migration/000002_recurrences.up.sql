@@ -0,0 +1,26 @@
+CREATE TABLE "recurrences" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "user_id" int NOT NULL,
+    "category_id" int NOT NULL,
+    "title" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    "description" varchar NOT NULL,
+    "value" integer NOT NULL,
+    "frequency" varchar NOT NULL,
+    "interval" integer NOT NULL DEFAULT 1,
+    "start_date" date NOT NULL,
+    "end_date" date,
+    "occurrences" integer,
+    "materialized_until" date,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("frequency" IN ('daily', 'weekly', 'monthly', 'yearly')),
+    CHECK ("interval" > 0)
+);
+
+ALTER TABLE "recurrences" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "recurrences" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id");
+
+ALTER TABLE "accounts" ADD COLUMN "recurrence_id" int;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("recurrence_id") REFERENCES "recurrences" ("id") ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX ON "accounts" ("recurrence_id", "date");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "installment_number";
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "installment_group_id";
+DROP TABLE IF EXISTS "installment_groups";
@@ -0,0 +1,22 @@
+CREATE TABLE "installment_groups" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "user_id" int NOT NULL,
+    "category_id" int NOT NULL,
+    "title" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    "description" varchar NOT NULL,
+    "total_value" integer NOT NULL,
+    "installment_count" integer NOT NULL,
+    "first_date" date NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("installment_count" > 0)
+);
+
+ALTER TABLE "installment_groups" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "installment_groups" ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id");
+
+ALTER TABLE "accounts" ADD COLUMN "installment_group_id" int;
+ALTER TABLE "accounts" ADD COLUMN "installment_number" int;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("installment_group_id") REFERENCES "installment_groups" ("id") ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX ON "accounts" ("installment_group_id", "installment_number");
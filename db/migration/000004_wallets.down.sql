@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "wallet_id";
+DROP TABLE IF EXISTS "wallets";
@@ -0,0 +1,16 @@
+CREATE TABLE "wallets" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "user_id" int NOT NULL,
+    "name" varchar NOT NULL,
+    "type" varchar NOT NULL,
+    "currency" varchar(3) NOT NULL,
+    "opening_balance" integer NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "wallets" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "accounts" ADD COLUMN "wallet_id" int;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id") ON DELETE SET NULL;
+
+CREATE INDEX ON "accounts" ("wallet_id", "date");
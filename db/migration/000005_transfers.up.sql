@@ -0,0 +1,22 @@
+CREATE TABLE "transfers" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "user_id" int NOT NULL,
+    "from_wallet_id" int NOT NULL,
+    "to_wallet_id" int NOT NULL,
+    "description" varchar NOT NULL,
+    "value" integer NOT NULL,
+    "date" date NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("from_wallet_id" <> "to_wallet_id"),
+    CHECK ("value" > 0)
+);
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "transfers" ADD FOREIGN KEY ("from_wallet_id") REFERENCES "wallets" ("id") ON DELETE CASCADE;
+ALTER TABLE "transfers" ADD FOREIGN KEY ("to_wallet_id") REFERENCES "wallets" ("id") ON DELETE CASCADE;
+
+-- Transfer legs are not income or expense, so they carry no category.
+ALTER TABLE "accounts" ALTER COLUMN "category_id" DROP NOT NULL;
+
+ALTER TABLE "accounts" ADD COLUMN "transfer_id" int;
+ALTER TABLE "accounts" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id") ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "started_at";
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "last_used_at";
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "client_ip";
+ALTER TABLE IF EXISTS "sessions" DROP COLUMN IF EXISTS "user_agent";
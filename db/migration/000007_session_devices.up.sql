@@ -0,0 +1,10 @@
+ALTER TABLE "sessions" ADD COLUMN "user_agent" varchar NOT NULL DEFAULT '';
+ALTER TABLE "sessions" ADD COLUMN "client_ip" varchar NOT NULL DEFAULT '';
+ALTER TABLE "sessions" ADD COLUMN "last_used_at" timestamptz NOT NULL DEFAULT (now());
+
+-- started_at is carried over on every rotation, so it keeps the time the
+-- user logged in while created_at is the time of the last refresh.
+ALTER TABLE "sessions" ADD COLUMN "started_at" timestamptz NOT NULL DEFAULT (now());
+UPDATE "sessions" s SET "started_at" = (
+    SELECT min(f."created_at") FROM "sessions" f WHERE f."family_id" = s."family_id"
+), "last_used_at" = s."created_at";
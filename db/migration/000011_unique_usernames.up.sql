@@ -0,0 +1,40 @@
+-- Usernames are unique regardless of case. Existing duplicates keep the name
+-- for the oldest user; the others get their id appended, the name cut short
+-- so the result stays within the 30 bytes signup allows, and a counter added
+-- should that still clash with another username.
+DO $$
+DECLARE
+    dup record;
+    base varchar;
+    suffix varchar;
+    attempt int;
+BEGIN
+    FOR dup IN
+        SELECT u."id", u."username" FROM "users" u
+         WHERE EXISTS (
+            SELECT 1 FROM "users" o
+             WHERE lower(o."username") = lower(u."username") AND o."id" < u."id"
+         )
+         ORDER BY u."id"
+    LOOP
+        attempt := 0;
+        LOOP
+            suffix := '_' || dup."id";
+            IF attempt > 0 THEN
+                suffix := suffix || '_' || attempt;
+            END IF;
+            base := dup."username";
+            WHILE octet_length(base || suffix) > 30 LOOP
+                base := left(base, -1);
+            END LOOP;
+            EXIT WHEN NOT EXISTS (
+                SELECT 1 FROM "users" WHERE lower("username") = lower(base || suffix)
+            );
+            attempt := attempt + 1;
+        END LOOP;
+
+        UPDATE "users" SET "username" = base || suffix WHERE "id" = dup."id";
+    END LOOP;
+END $$;
+
+CREATE UNIQUE INDEX "users_username_lower_idx" ON "users" (lower("username"));
@@ -0,0 +1,7 @@
+CREATE TABLE "login_attempts" (
+    "key" varchar PRIMARY KEY NOT NULL,
+    "failures" int NOT NULL,
+    "last_failed_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "login_attempts" ("last_failed_at");
@@ -0,0 +1,16 @@
+CREATE TABLE "api_keys" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "user_id" int NOT NULL,
+    "name" varchar NOT NULL,
+    "key_hash" varchar UNIQUE NOT NULL,
+    "prefix" varchar NOT NULL,
+    "scopes" varchar[] NOT NULL,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "api_keys" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "api_keys" ("user_id");
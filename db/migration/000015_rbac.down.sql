@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "audit_logs";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "disabled_at";
+
+UPDATE "users" SET "role" = 'user' WHERE "role" = 'support';
+ALTER TABLE "users" DROP CONSTRAINT "users_role_check";
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin'));
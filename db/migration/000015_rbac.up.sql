@@ -0,0 +1,21 @@
+ALTER TABLE "users" DROP CONSTRAINT "users_role_check";
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('user', 'admin', 'support'));
+
+ALTER TABLE "users" ADD COLUMN "disabled_at" timestamptz;
+
+CREATE TABLE "audit_logs" (
+    "id" bigserial PRIMARY KEY NOT NULL,
+    "actor_id" int,
+    "action" varchar NOT NULL,
+    "target_user_id" int,
+    "status" int NOT NULL,
+    "details" varchar NOT NULL DEFAULT '',
+    "client_ip" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("actor_id") REFERENCES "users" ("id") ON DELETE SET NULL;
+ALTER TABLE "audit_logs" ADD FOREIGN KEY ("target_user_id") REFERENCES "users" ("id") ON DELETE SET NULL;
+
+CREATE INDEX ON "audit_logs" ("actor_id");
+CREATE INDEX ON "audit_logs" ("target_user_id");
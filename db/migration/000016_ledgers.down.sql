@@ -0,0 +1,29 @@
+-- Rows of shared ledgers go back to the owner of the ledger.
+ALTER TABLE "categories" ADD COLUMN "user_id" int;
+ALTER TABLE "accounts" ADD COLUMN "user_id" int;
+ALTER TABLE "recurrences" ADD COLUMN "user_id" int;
+ALTER TABLE "installment_groups" ADD COLUMN "user_id" int;
+
+UPDATE "categories" t SET "user_id" = l."owner_id" FROM "ledgers" l WHERE l."id" = t."ledger_id";
+UPDATE "accounts" t SET "user_id" = l."owner_id" FROM "ledgers" l WHERE l."id" = t."ledger_id";
+UPDATE "recurrences" t SET "user_id" = l."owner_id" FROM "ledgers" l WHERE l."id" = t."ledger_id";
+UPDATE "installment_groups" t SET "user_id" = l."owner_id" FROM "ledgers" l WHERE l."id" = t."ledger_id";
+
+ALTER TABLE "categories" ALTER COLUMN "user_id" SET NOT NULL;
+ALTER TABLE "accounts" ALTER COLUMN "user_id" SET NOT NULL;
+ALTER TABLE "recurrences" ALTER COLUMN "user_id" SET NOT NULL;
+ALTER TABLE "installment_groups" ALTER COLUMN "user_id" SET NOT NULL;
+
+ALTER TABLE "categories" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "accounts" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "recurrences" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+ALTER TABLE "installment_groups" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "categories" DROP COLUMN "ledger_id";
+ALTER TABLE "accounts" DROP COLUMN "ledger_id";
+ALTER TABLE "recurrences" DROP COLUMN "ledger_id";
+ALTER TABLE "installment_groups" DROP COLUMN "ledger_id";
+
+DROP TABLE IF EXISTS "ledger_invitations";
+DROP TABLE IF EXISTS "ledger_members";
+DROP TABLE IF EXISTS "ledgers";
@@ -0,0 +1,84 @@
+-- Categories, accounts, recurrences and installments belong to a ledger that
+-- several users can share, instead of to a single user. Every user gets a
+-- personal ledger, which their existing data moves into. Wallets and
+-- transfers stay with their user; transfer legs live in the personal ledger.
+CREATE TABLE "ledgers" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "name" varchar NOT NULL,
+    "owner_id" int NOT NULL,
+    "personal" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "ledgers" ADD FOREIGN KEY ("owner_id") REFERENCES "users" ("id");
+
+CREATE INDEX ON "ledgers" ("owner_id");
+CREATE UNIQUE INDEX "ledgers_personal_idx" ON "ledgers" ("owner_id") WHERE "personal";
+
+CREATE TABLE "ledger_members" (
+    "ledger_id" int NOT NULL,
+    "user_id" int NOT NULL,
+    "role" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("ledger_id", "user_id"),
+    CHECK ("role" IN ('owner', 'editor', 'viewer'))
+);
+
+ALTER TABLE "ledger_members" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id") ON DELETE CASCADE;
+ALTER TABLE "ledger_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "ledger_members" ("user_id");
+
+CREATE TABLE "ledger_invitations" (
+    "id" serial PRIMARY KEY NOT NULL,
+    "ledger_id" int NOT NULL,
+    "email" varchar NOT NULL,
+    "role" varchar NOT NULL,
+    "token_hash" varchar UNIQUE NOT NULL,
+    "invited_by" int,
+    "expires_at" timestamptz NOT NULL,
+    "accepted_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("role" IN ('editor', 'viewer'))
+);
+
+ALTER TABLE "ledger_invitations" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id") ON DELETE CASCADE;
+ALTER TABLE "ledger_invitations" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id") ON DELETE SET NULL;
+
+CREATE INDEX ON "ledger_invitations" ("ledger_id");
+
+INSERT INTO "ledgers" ("name", "owner_id", "personal")
+SELECT 'Personal', "id", true FROM "users";
+
+INSERT INTO "ledger_members" ("ledger_id", "user_id", "role")
+SELECT "id", "owner_id", 'owner' FROM "ledgers";
+
+ALTER TABLE "categories" ADD COLUMN "ledger_id" int;
+ALTER TABLE "accounts" ADD COLUMN "ledger_id" int;
+ALTER TABLE "recurrences" ADD COLUMN "ledger_id" int;
+ALTER TABLE "installment_groups" ADD COLUMN "ledger_id" int;
+
+UPDATE "categories" t SET "ledger_id" = l."id" FROM "ledgers" l WHERE l."owner_id" = t."user_id" AND l."personal";
+UPDATE "accounts" t SET "ledger_id" = l."id" FROM "ledgers" l WHERE l."owner_id" = t."user_id" AND l."personal";
+UPDATE "recurrences" t SET "ledger_id" = l."id" FROM "ledgers" l WHERE l."owner_id" = t."user_id" AND l."personal";
+UPDATE "installment_groups" t SET "ledger_id" = l."id" FROM "ledgers" l WHERE l."owner_id" = t."user_id" AND l."personal";
+
+ALTER TABLE "categories" ALTER COLUMN "ledger_id" SET NOT NULL;
+ALTER TABLE "accounts" ALTER COLUMN "ledger_id" SET NOT NULL;
+ALTER TABLE "recurrences" ALTER COLUMN "ledger_id" SET NOT NULL;
+ALTER TABLE "installment_groups" ALTER COLUMN "ledger_id" SET NOT NULL;
+
+ALTER TABLE "categories" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id");
+ALTER TABLE "accounts" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id");
+ALTER TABLE "recurrences" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id");
+ALTER TABLE "installment_groups" ADD FOREIGN KEY ("ledger_id") REFERENCES "ledgers" ("id");
+
+CREATE INDEX ON "categories" ("ledger_id");
+CREATE INDEX ON "accounts" ("ledger_id", "date");
+CREATE INDEX ON "recurrences" ("ledger_id");
+CREATE INDEX ON "installment_groups" ("ledger_id");
+
+ALTER TABLE "categories" DROP COLUMN "user_id";
+ALTER TABLE "accounts" DROP COLUMN "user_id";
+ALTER TABLE "recurrences" DROP COLUMN "user_id";
+ALTER TABLE "installment_groups" DROP COLUMN "user_id";
@@ -1,42 +1,117 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-    user_id,
+    ledger_id,
     category_id,
     title,
     type,
     description,
     date,
-    value
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
+    value,
+    wallet_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetAccount :one
-SELECT * FROM accounts WHERE id = $1 LIMIT 1;
+SELECT * FROM accounts WHERE id = $1 AND ledger_id = $2 LIMIT 1;
 
 -- name: GetAccounts :many
-SELECT a.id, a.user_id, 
+SELECT a.id, a.ledger_id, 
        a.title, a.type, a.description, 
        a.value, a.date, a.created_at, 
+       a.wallet_id,
        c.title as category_title
   FROM accounts a
   LEFT JOIN categories c on c.id = a.category_id
- WHERE a.user_id = @user_id 
+ WHERE a.ledger_id = @ledger_id
    AND a.type = @type
-   AND a.category_id = COALESCE(sqlc.narg('category_id'), a.category_id)
+   AND (sqlc.narg('category_id')::int IS NULL OR a.category_id = sqlc.narg('category_id'))
    AND (UPPER(a.title) LIKE CONCAT('%', UPPER(@title::text), '%'))
    AND (UPPER(a.description) LIKE CONCAT('%', UPPER(@description::text), '%'))
    AND a.date = COALESCE(sqlc.narg('date'), a.date);
 
 -- name: GetAccountsReports :one
-SELECT SUM(value) AS sum_value FROM accounts 
-WHERE user_id = $1 AND type = $2;
+SELECT COALESCE(SUM(value), 0)::bigint AS sum_value FROM accounts 
+WHERE ledger_id = $1 AND type = $2 AND transfer_id IS NULL;
 
--- name: GetAccountGraph :one
-SELECT COUNT(*) FROM accounts
-WHERE user_id = $1 and type = $2;
+-- name: GetAccountGraph :many
+-- Like GetAccountsSummary, optionally for one category or wallet, with
+-- running totals from the start of the range next to the values of each
+-- period. Balance is income minus expense.
+WITH periods AS (
+  SELECT p.period,
+         COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+         COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense
+    FROM generate_series(
+           date_trunc(@granularity::text, @start_date::timestamp),
+           date_trunc(@granularity::text, @end_date::timestamp),
+           ('1 ' || @granularity::text)::interval
+         ) AS p(period)
+    LEFT JOIN accounts a
+      ON a.ledger_id = @ledger_id
+     AND a.transfer_id IS NULL
+     AND a.date BETWEEN @start_date::timestamp AND @end_date::timestamp
+     AND date_trunc(@granularity::text, a.date::timestamp) = p.period
+     AND (sqlc.narg('category_id')::int IS NULL OR a.category_id = sqlc.narg('category_id'))
+     AND (sqlc.narg('wallet_id')::int IS NULL OR a.wallet_id = sqlc.narg('wallet_id'))
+   GROUP BY p.period
+)
+SELECT period::date AS period,
+       income,
+       expense,
+       (income - expense)::bigint AS balance,
+       (SUM(income) OVER w)::bigint AS cumulative_income,
+       (SUM(expense) OVER w)::bigint AS cumulative_expense,
+       (SUM(income - expense) OVER w)::bigint AS cumulative_balance
+  FROM periods
+WINDOW w AS (ORDER BY period)
+ ORDER BY period;
+
+-- name: GetAccountsSummary :many
+-- One row per period of @granularity (day, week, month or year) between
+-- @start_date and @end_date, periods without accounts included with zeros.
+-- Transfer legs only move money between wallets and are left out.
+SELECT p.period::date AS period,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense,
+       (COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)
+         - COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0))::bigint AS net
+  FROM generate_series(
+         date_trunc(@granularity::text, @start_date::timestamp),
+         date_trunc(@granularity::text, @end_date::timestamp),
+         ('1 ' || @granularity::text)::interval
+       ) AS p(period)
+  LEFT JOIN accounts a
+    ON a.ledger_id = @ledger_id
+   AND a.transfer_id IS NULL
+   AND a.date BETWEEN @start_date::timestamp AND @end_date::timestamp
+   AND date_trunc(@granularity::text, a.date::timestamp) = p.period
+ GROUP BY p.period
+ ORDER BY p.period;
+
+-- name: GetCategoryBreakdown :many
+-- Totals of the accounts of @type between @start_date and @end_date per
+-- category, largest first. Share is the percentage of the total of all
+-- categories, average the mean value per account. Transfer legs are left out.
+SELECT a.category_id,
+       c.title AS category_title,
+       SUM(a.value)::bigint AS total,
+       COUNT(*)::bigint AS count,
+       COALESCE(ROUND(SUM(a.value) * 100.0 / NULLIF(SUM(SUM(a.value)) OVER (), 0), 2), 0)::float8 AS share,
+       ROUND(AVG(a.value), 2)::float8 AS average
+  FROM accounts a
+  LEFT JOIN categories c ON c.id = a.category_id
+ WHERE a.ledger_id = @ledger_id
+   AND a.type = @type
+   AND a.transfer_id IS NULL
+   AND a.date BETWEEN @start_date::date AND @end_date::date
+ GROUP BY a.category_id, c.title
+ ORDER BY total DESC, c.title, a.category_id;
 
 -- name: UpdateAccounts :one
-UPDATE accounts SET title = $2, description = $3, value = $4 WHERE id = $1 RETURNING *;
+UPDATE accounts SET title = $2, description = $3, value = $4 WHERE id = $1 AND ledger_id = $5 RETURNING *;
 
 -- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1;
\ No newline at end of file
+DELETE FROM accounts WHERE id = $1 AND ledger_id = $2;
+
+-- name: DeleteLedgerAccounts :exec
+DELETE FROM accounts WHERE ledger_id = $1;
@@ -0,0 +1,35 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    actor_id,
+    action,
+    target_user_id,
+    status,
+    details,
+    client_ip
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_logs
+ WHERE sqlc.narg(target_user_id)::int IS NULL
+    OR target_user_id = sqlc.narg(target_user_id)
+ ORDER BY id DESC
+ LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: GetSystemStats :one
+SELECT
+    (SELECT count(*) FROM users) AS users,
+    (SELECT count(*) FROM users WHERE email_verified_at IS NOT NULL) AS verified_users,
+    (SELECT count(*) FROM users WHERE disabled_at IS NOT NULL) AS disabled_users,
+    (SELECT count(*) FROM user_totp WHERE confirmed_at IS NOT NULL) AS two_factor_users,
+    (SELECT count(*) FROM sessions WHERE revoked_at IS NULL AND expires_at > now()) AS active_sessions,
+    (SELECT count(*) FROM categories) AS categories,
+    (SELECT count(*) FROM accounts) AS accounts,
+    (SELECT count(*) FROM wallets) AS wallets,
+    (SELECT count(*) FROM transfers) AS transfers,
+    (SELECT count(*) FROM recurrences) AS recurrences;
+
+-- name: CountUsersByRole :many
+SELECT role, count(*) AS users FROM users
+ GROUP BY role
+ ORDER BY role;
@@ -1,6 +1,6 @@
 -- name: CreateCategory :one
 INSERT INTO categories (
-    user_id,
+    ledger_id,
     title,
     type,
     description
@@ -8,17 +8,20 @@ INSERT INTO categories (
 RETURNING *;
 
 -- name: GetCategory :one
-SELECT * FROM categories WHERE id = $1 LIMIT 1;
+SELECT * FROM categories WHERE id = $1 AND ledger_id = $2 LIMIT 1;
 
 -- name: GetCategories :many
 SELECT * FROM categories 
- WHERE user_id = @user_id
+ WHERE ledger_id = @ledger_id
    AND type = @type
    AND (UPPER(title) LIKE CONCAT('%', UPPER(@title::text), '%'))
    AND (UPPER(description) LIKE CONCAT('%', UPPER(@description::text), '%'));
 
 -- name: UpdateCategories :one
-UPDATE categories SET title = $2, description = $3 WHERE id = $1 RETURNING *;
+UPDATE categories SET title = $2, description = $3 WHERE id = $1 AND ledger_id = $4 RETURNING *;
 
 -- name: DeleteCategory :exec
-DELETE FROM categories WHERE id = $1;
\ No newline at end of file
+DELETE FROM categories WHERE id = $1 AND ledger_id = $2;
+
+-- name: DeleteLedgerCategories :exec
+DELETE FROM categories WHERE ledger_id = $1;
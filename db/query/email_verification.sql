@@ -0,0 +1,14 @@
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetEmailVerificationTokenByHash :one
+SELECT * FROM email_verification_tokens WHERE token_hash = $1 LIMIT 1 FOR UPDATE;
+
+-- name: MarkEmailVerificationTokensUsed :exec
+UPDATE email_verification_tokens SET used_at = now()
+ WHERE user_id = $1 AND used_at IS NULL;
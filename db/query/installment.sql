@@ -0,0 +1,46 @@
+-- name: CreateInstallmentGroup :one
+INSERT INTO installment_groups (
+    ledger_id,
+    category_id,
+    title,
+    type,
+    description,
+    total_value,
+    installment_count,
+    first_date
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetInstallmentGroup :one
+SELECT * FROM installment_groups WHERE id = $1 AND ledger_id = $2 LIMIT 1;
+
+-- name: UpdateInstallmentGroup :one
+UPDATE installment_groups
+   SET title = $2, description = $3, total_value = $4
+ WHERE id = $1 AND ledger_id = $5
+RETURNING *;
+
+-- name: DeleteInstallmentGroup :exec
+DELETE FROM installment_groups WHERE id = $1 AND ledger_id = $2;
+
+-- name: CreateInstallmentAccount :one
+INSERT INTO accounts (
+    ledger_id,
+    category_id,
+    installment_group_id,
+    installment_number,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetInstallmentAccounts :many
+SELECT * FROM accounts
+ WHERE installment_group_id = $1
+ ORDER BY installment_number;
+
+-- name: DeleteLedgerInstallmentGroups :exec
+DELETE FROM installment_groups WHERE ledger_id = $1;
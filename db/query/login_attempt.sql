@@ -0,0 +1,23 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts WHERE key = $1 LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (
+    key,
+    failures,
+    last_failed_at
+) VALUES (sqlc.arg(key), 1, sqlc.arg(failed_at))
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_attempts.last_failed_at < sqlc.arg(reset_before) THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failed_at = EXCLUDED.last_failed_at
+RETURNING *;
+
+-- name: DeleteLoginAttempt :one
+DELETE FROM login_attempts WHERE key = $1
+RETURNING *;
+
+-- name: DeleteStaleLoginAttempts :exec
+DELETE FROM login_attempts WHERE last_failed_at < $1;
@@ -0,0 +1,56 @@
+-- name: CreateRecurrence :one
+INSERT INTO recurrences (
+    ledger_id,
+    category_id,
+    title,
+    type,
+    description,
+    value,
+    frequency,
+    interval,
+    start_date,
+    end_date,
+    occurrences
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetRecurrence :one
+SELECT * FROM recurrences WHERE id = $1 AND ledger_id = $2 LIMIT 1;
+
+-- name: GetRecurrences :many
+SELECT * FROM recurrences
+ WHERE ledger_id = $1
+ ORDER BY start_date, id;
+
+-- name: GetPendingRecurrences :many
+SELECT * FROM recurrences
+ WHERE start_date <= @until::date
+   AND (materialized_until IS NULL OR materialized_until < @until::date);
+
+-- name: UpdateRecurrence :one
+UPDATE recurrences
+   SET title = $2, description = $3, value = $4, end_date = $5, occurrences = $6
+ WHERE id = $1 AND ledger_id = $7
+RETURNING *;
+
+-- name: SetRecurrenceMaterializedUntil :exec
+UPDATE recurrences SET materialized_until = $2 WHERE id = $1;
+
+-- name: DeleteRecurrence :exec
+DELETE FROM recurrences WHERE id = $1 AND ledger_id = $2;
+
+-- name: CreateRecurrenceAccount :execrows
+INSERT INTO accounts (
+    ledger_id,
+    category_id,
+    recurrence_id,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (recurrence_id, date) DO NOTHING;
+
+-- name: DeleteLedgerRecurrences :exec
+DELETE FROM recurrences WHERE ledger_id = $1;
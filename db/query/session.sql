@@ -0,0 +1,36 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+    user_id,
+    family_id,
+    token_hash,
+    expires_at,
+    user_agent,
+    client_ip,
+    started_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetSessionByTokenHash :one
+SELECT * FROM sessions WHERE token_hash = $1 LIMIT 1 FOR UPDATE;
+
+-- name: MarkSessionRotated :exec
+UPDATE sessions SET rotated_at = now() WHERE id = $1;
+
+-- name: RevokeSessionFamily :exec
+UPDATE sessions SET revoked_at = now()
+ WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = now()
+ WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+ WHERE user_id = $1
+   AND rotated_at IS NULL
+   AND revoked_at IS NULL
+   AND expires_at > now()
+ ORDER BY last_used_at DESC, id DESC;
@@ -0,0 +1,45 @@
+-- name: CreateTOTP :one
+INSERT INTO user_totp (
+    user_id,
+    secret
+) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = EXCLUDED.secret,
+    last_used_step = 0,
+    created_at = now()
+  WHERE user_totp.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTP :one
+SELECT * FROM user_totp WHERE user_id = $1 LIMIT 1;
+
+-- name: ConfirmTOTP :one
+UPDATE user_totp SET confirmed_at = now(), last_used_step = $2
+ WHERE user_id = $1 AND confirmed_at IS NULL
+RETURNING *;
+
+-- name: UseTOTPStep :one
+UPDATE user_totp SET last_used_step = $2
+ WHERE user_id = $1 AND last_used_step < $2
+RETURNING *;
+
+-- name: DeleteTOTP :exec
+DELETE FROM user_totp WHERE user_id = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+) VALUES ($1, $2)
+RETURNING *;
+
+-- name: UseRecoveryCode :one
+UPDATE recovery_codes SET used_at = now()
+ WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING *;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT count(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes WHERE user_id = $1;
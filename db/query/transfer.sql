@@ -0,0 +1,59 @@
+-- name: CreateTransfer :one
+INSERT INTO transfers (
+    user_id,
+    from_wallet_id,
+    to_wallet_id,
+    description,
+    value,
+    date
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetTransfer :one
+SELECT * FROM transfers WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetTransfers :many
+SELECT * FROM transfers
+ WHERE user_id = $1
+ ORDER BY date DESC, id DESC;
+
+-- name: UpdateTransfer :one
+UPDATE transfers
+   SET description = $2, value = $3, date = $4
+ WHERE id = $1 AND user_id = $5
+RETURNING *;
+
+-- name: DeleteTransfer :exec
+DELETE FROM transfers WHERE id = $1 AND user_id = $2;
+
+-- name: CreateTransferAccount :one
+-- Wallets are personal, so the legs of a transfer go to the personal ledger
+-- of its user.
+INSERT INTO accounts (
+    ledger_id,
+    wallet_id,
+    transfer_id,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES (
+    (SELECT id FROM ledgers WHERE owner_id = @user_id AND personal),
+    @wallet_id, @transfer_id, @title, @type, @description, @date, @value
+)
+RETURNING *;
+
+-- name: GetTransferAccounts :many
+SELECT * FROM accounts
+ WHERE transfer_id = $1
+ ORDER BY id;
+
+-- name: UpdateTransferAccounts :many
+UPDATE accounts
+   SET description = $2, value = $3, date = $4
+ WHERE transfer_id = $1
+RETURNING *;
+
+-- name: DeleteUserTransfers :exec
+DELETE FROM transfers WHERE user_id = $1;
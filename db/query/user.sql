@@ -7,7 +7,56 @@ INSERT INTO users (
 RETURNING *;
 
 -- name: GetUser :one
-SELECT * FROM users WHERE username = $1 LIMIT 1;
+SELECT * FROM users WHERE lower(username) = lower(sqlc.arg(username)) LIMIT 1;
 
 -- name: GetUserById :one
-SELECT * FROM users WHERE id = $1 LIMIT 1;
\ No newline at end of file
+SELECT * FROM users WHERE id = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = $1 LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users SET password = $2
+ WHERE id = $1
+RETURNING *;
+
+-- name: MarkUserEmailVerified :one
+UPDATE users SET email_verified_at = COALESCE(email_verified_at, now())
+ WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserEmail :one
+UPDATE users SET email = $2, email_verified_at = NULL
+ WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUsername :one
+UPDATE users SET username = $2
+ WHERE id = $1
+RETURNING *;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = $2
+ WHERE id = $1
+RETURNING *;
+
+-- name: DisableUser :one
+UPDATE users SET disabled_at = COALESCE(disabled_at, now())
+ WHERE id = $1
+RETURNING *;
+
+-- name: EnableUser :one
+UPDATE users SET disabled_at = NULL
+ WHERE id = $1
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ WHERE sqlc.arg(search)::varchar = ''
+    OR strpos(lower(username), lower(sqlc.arg(search))) > 0
+    OR strpos(lower(email), lower(sqlc.arg(search))) > 0
+ ORDER BY id
+ LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
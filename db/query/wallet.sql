@@ -0,0 +1,44 @@
+-- name: CreateWallet :one
+INSERT INTO wallets (
+    user_id,
+    name,
+    type,
+    currency,
+    opening_balance
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetWallet :one
+SELECT * FROM wallets WHERE id = $1 AND user_id = $2 LIMIT 1;
+
+-- name: GetWallets :many
+SELECT * FROM wallets
+ WHERE user_id = $1
+ ORDER BY name, id;
+
+-- name: UpdateWallet :one
+UPDATE wallets
+   SET name = $2, type = $3, currency = $4, opening_balance = $5
+ WHERE id = $1 AND user_id = $6
+RETURNING *;
+
+-- name: DeleteWallet :exec
+DELETE FROM wallets WHERE id = $1 AND user_id = $2;
+
+-- name: GetWalletBalance :one
+-- Accounts of type 'credit' are income and add to the balance, 'debit' are
+-- expenses and subtract from it.
+SELECT w.id AS wallet_id,
+       w.opening_balance,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense,
+       (w.opening_balance
+         + COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)
+         - COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0))::bigint AS balance
+  FROM wallets w
+  LEFT JOIN accounts a ON a.wallet_id = w.id AND a.date <= @date::date
+ WHERE w.id = @wallet_id AND w.user_id = @user_id
+ GROUP BY w.id;
+
+-- name: DeleteUserWallets :exec
+DELETE FROM wallets WHERE user_id = $1;
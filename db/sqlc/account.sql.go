@@ -13,41 +13,43 @@ import (
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-    user_id,
+    ledger_id,
     category_id,
     title,
     type,
     description,
     date,
-    value
-) VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, user_id, category_id, title, type, description, value, date, created_at
+    value,
+    wallet_id
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id
 `
 
 type CreateAccountParams struct {
-	UserID      int32     `json:"user_id"`
-	CategoryID  int32     `json:"category_id"`
-	Title       string    `json:"title"`
-	Type        string    `json:"type"`
-	Description string    `json:"description"`
-	Date        time.Time `json:"date"`
-	Value       int32     `json:"value"`
+	LedgerID    int32         `json:"ledger_id"`
+	CategoryID  sql.NullInt32 `json:"category_id"`
+	Title       string        `json:"title"`
+	Type        string        `json:"type"`
+	Description string        `json:"description"`
+	Date        time.Time     `json:"date"`
+	Value       int32         `json:"value"`
+	WalletID    sql.NullInt32 `json:"wallet_id"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
 	row := q.db.QueryRowContext(ctx, createAccount,
-		arg.UserID,
+		arg.LedgerID,
 		arg.CategoryID,
 		arg.Title,
 		arg.Type,
 		arg.Description,
 		arg.Date,
 		arg.Value,
+		arg.WalletID,
 	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.CategoryID,
 		&i.Title,
 		&i.Type,
@@ -55,29 +57,53 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Value,
 		&i.Date,
 		&i.CreatedAt,
+		&i.RecurrenceID,
+		&i.InstallmentGroupID,
+		&i.InstallmentNumber,
+		&i.WalletID,
+		&i.TransferID,
+		&i.LedgerID,
 	)
 	return i, err
 }
 
 const deleteAccount = `-- name: DeleteAccount :exec
-DELETE FROM accounts WHERE id = $1
+DELETE FROM accounts WHERE id = $1 AND ledger_id = $2
+`
+
+type DeleteAccountParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) DeleteAccount(ctx context.Context, arg DeleteAccountParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAccount, arg.ID, arg.LedgerID)
+	return err
+}
+
+const deleteLedgerAccounts = `-- name: DeleteLedgerAccounts :exec
+DELETE FROM accounts WHERE ledger_id = $1
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteAccount, id)
+func (q *Queries) DeleteLedgerAccounts(ctx context.Context, ledgerID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteLedgerAccounts, ledgerID)
 	return err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, user_id, category_id, title, type, description, value, date, created_at FROM accounts WHERE id = $1 LIMIT 1
+SELECT id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id FROM accounts WHERE id = $1 AND ledger_id = $2 LIMIT 1
 `
 
-func (q *Queries) GetAccount(ctx context.Context, id int32) (Account, error) {
-	row := q.db.QueryRowContext(ctx, getAccount, id)
+type GetAccountParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccount, arg.ID, arg.LedgerID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.CategoryID,
 		&i.Title,
 		&i.Type,
@@ -85,44 +111,125 @@ func (q *Queries) GetAccount(ctx context.Context, id int32) (Account, error) {
 		&i.Value,
 		&i.Date,
 		&i.CreatedAt,
+		&i.RecurrenceID,
+		&i.InstallmentGroupID,
+		&i.InstallmentNumber,
+		&i.WalletID,
+		&i.TransferID,
+		&i.LedgerID,
 	)
 	return i, err
 }
 
-const getAccountGraph = `-- name: GetAccountGraph :one
-SELECT COUNT(*) FROM accounts
-WHERE user_id = $1 and type = $2
+const getAccountGraph = `-- name: GetAccountGraph :many
+WITH periods AS (
+  SELECT p.period,
+         COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+         COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense
+    FROM generate_series(
+           date_trunc($1::text, $2::timestamp),
+           date_trunc($1::text, $3::timestamp),
+           ('1 ' || $1::text)::interval
+         ) AS p(period)
+    LEFT JOIN accounts a
+      ON a.ledger_id = $4
+     AND a.transfer_id IS NULL
+     AND a.date BETWEEN $2::timestamp AND $3::timestamp
+     AND date_trunc($1::text, a.date::timestamp) = p.period
+     AND ($5::int IS NULL OR a.category_id = $5)
+     AND ($6::int IS NULL OR a.wallet_id = $6)
+   GROUP BY p.period
+)
+SELECT period::date AS period,
+       income,
+       expense,
+       (income - expense)::bigint AS balance,
+       (SUM(income) OVER w)::bigint AS cumulative_income,
+       (SUM(expense) OVER w)::bigint AS cumulative_expense,
+       (SUM(income - expense) OVER w)::bigint AS cumulative_balance
+  FROM periods
+WINDOW w AS (ORDER BY period)
+ ORDER BY period
 `
 
 type GetAccountGraphParams struct {
-	UserID int32  `json:"user_id"`
-	Type   string `json:"type"`
+	Granularity string        `json:"granularity"`
+	StartDate   time.Time     `json:"start_date"`
+	EndDate     time.Time     `json:"end_date"`
+	LedgerID    int32         `json:"ledger_id"`
+	CategoryID  sql.NullInt32 `json:"category_id"`
+	WalletID    sql.NullInt32 `json:"wallet_id"`
 }
 
-func (q *Queries) GetAccountGraph(ctx context.Context, arg GetAccountGraphParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getAccountGraph, arg.UserID, arg.Type)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type GetAccountGraphRow struct {
+	Period            time.Time `json:"period"`
+	Income            int64     `json:"income"`
+	Expense           int64     `json:"expense"`
+	Balance           int64     `json:"balance"`
+	CumulativeIncome  int64     `json:"cumulative_income"`
+	CumulativeExpense int64     `json:"cumulative_expense"`
+	CumulativeBalance int64     `json:"cumulative_balance"`
+}
+
+// Like GetAccountsSummary, optionally for one category or wallet, with
+// running totals from the start of the range next to the values of each
+// period. Balance is income minus expense.
+func (q *Queries) GetAccountGraph(ctx context.Context, arg GetAccountGraphParams) ([]GetAccountGraphRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountGraph,
+		arg.Granularity,
+		arg.StartDate,
+		arg.EndDate,
+		arg.LedgerID,
+		arg.CategoryID,
+		arg.WalletID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAccountGraphRow{}
+	for rows.Next() {
+		var i GetAccountGraphRow
+		if err := rows.Scan(
+			&i.Period,
+			&i.Income,
+			&i.Expense,
+			&i.Balance,
+			&i.CumulativeIncome,
+			&i.CumulativeExpense,
+			&i.CumulativeBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getAccounts = `-- name: GetAccounts :many
-SELECT a.id, a.user_id, 
+SELECT a.id, a.ledger_id, 
        a.title, a.type, a.description, 
        a.value, a.date, a.created_at, 
+       a.wallet_id,
        c.title as category_title
   FROM accounts a
   LEFT JOIN categories c on c.id = a.category_id
- WHERE a.user_id = $1 
+ WHERE a.ledger_id = $1
    AND a.type = $2
-   AND a.category_id = COALESCE($3, a.category_id)
+   AND ($3::int IS NULL OR a.category_id = $3)
    AND (UPPER(a.title) LIKE CONCAT('%', UPPER($4::text), '%'))
    AND (UPPER(a.description) LIKE CONCAT('%', UPPER($5::text), '%'))
    AND a.date = COALESCE($6, a.date)
 `
 
 type GetAccountsParams struct {
-	UserID      int32         `json:"user_id"`
+	LedgerID    int32         `json:"ledger_id"`
 	Type        string        `json:"type"`
 	CategoryID  sql.NullInt32 `json:"category_id"`
 	Title       string        `json:"title"`
@@ -132,19 +239,20 @@ type GetAccountsParams struct {
 
 type GetAccountsRow struct {
 	ID            int32          `json:"id"`
-	UserID        int32          `json:"user_id"`
+	LedgerID      int32          `json:"ledger_id"`
 	Title         string         `json:"title"`
 	Type          string         `json:"type"`
 	Description   string         `json:"description"`
 	Value         int32          `json:"value"`
 	Date          time.Time      `json:"date"`
 	CreatedAt     time.Time      `json:"created_at"`
+	WalletID      sql.NullInt32  `json:"wallet_id"`
 	CategoryTitle sql.NullString `json:"category_title"`
 }
 
 func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]GetAccountsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getAccounts,
-		arg.UserID,
+		arg.LedgerID,
 		arg.Type,
 		arg.CategoryID,
 		arg.Title,
@@ -160,13 +268,14 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Get
 		var i GetAccountsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.UserID,
+			&i.LedgerID,
 			&i.Title,
 			&i.Type,
 			&i.Description,
 			&i.Value,
 			&i.Date,
 			&i.CreatedAt,
+			&i.WalletID,
 			&i.CategoryTitle,
 		); err != nil {
 			return nil, err
@@ -183,24 +292,165 @@ func (q *Queries) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]Get
 }
 
 const getAccountsReports = `-- name: GetAccountsReports :one
-SELECT SUM(value) AS sum_value FROM accounts 
-WHERE user_id = $1 AND type = $2
+SELECT COALESCE(SUM(value), 0)::bigint AS sum_value FROM accounts 
+WHERE ledger_id = $1 AND type = $2 AND transfer_id IS NULL
 `
 
 type GetAccountsReportsParams struct {
-	UserID int32  `json:"user_id"`
-	Type   string `json:"type"`
+	LedgerID int32  `json:"ledger_id"`
+	Type     string `json:"type"`
 }
 
 func (q *Queries) GetAccountsReports(ctx context.Context, arg GetAccountsReportsParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getAccountsReports, arg.UserID, arg.Type)
+	row := q.db.QueryRowContext(ctx, getAccountsReports, arg.LedgerID, arg.Type)
 	var sum_value int64
 	err := row.Scan(&sum_value)
 	return sum_value, err
 }
 
+const getAccountsSummary = `-- name: GetAccountsSummary :many
+SELECT p.period::date AS period,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense,
+       (COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)
+         - COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0))::bigint AS net
+  FROM generate_series(
+         date_trunc($1::text, $2::timestamp),
+         date_trunc($1::text, $3::timestamp),
+         ('1 ' || $1::text)::interval
+       ) AS p(period)
+  LEFT JOIN accounts a
+    ON a.ledger_id = $4
+   AND a.transfer_id IS NULL
+   AND a.date BETWEEN $2::timestamp AND $3::timestamp
+   AND date_trunc($1::text, a.date::timestamp) = p.period
+ GROUP BY p.period
+ ORDER BY p.period
+`
+
+type GetAccountsSummaryParams struct {
+	Granularity string    `json:"granularity"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	LedgerID    int32     `json:"ledger_id"`
+}
+
+type GetAccountsSummaryRow struct {
+	Period  time.Time `json:"period"`
+	Income  int64     `json:"income"`
+	Expense int64     `json:"expense"`
+	Net     int64     `json:"net"`
+}
+
+// One row per period of @granularity (day, week, month or year) between
+// @start_date and @end_date, periods without accounts included with zeros.
+// Transfer legs only move money between wallets and are left out.
+func (q *Queries) GetAccountsSummary(ctx context.Context, arg GetAccountsSummaryParams) ([]GetAccountsSummaryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAccountsSummary,
+		arg.Granularity,
+		arg.StartDate,
+		arg.EndDate,
+		arg.LedgerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAccountsSummaryRow{}
+	for rows.Next() {
+		var i GetAccountsSummaryRow
+		if err := rows.Scan(
+			&i.Period,
+			&i.Income,
+			&i.Expense,
+			&i.Net,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCategoryBreakdown = `-- name: GetCategoryBreakdown :many
+SELECT a.category_id,
+       c.title AS category_title,
+       SUM(a.value)::bigint AS total,
+       COUNT(*)::bigint AS count,
+       COALESCE(ROUND(SUM(a.value) * 100.0 / NULLIF(SUM(SUM(a.value)) OVER (), 0), 2), 0)::float8 AS share,
+       ROUND(AVG(a.value), 2)::float8 AS average
+  FROM accounts a
+  LEFT JOIN categories c ON c.id = a.category_id
+ WHERE a.ledger_id = $1
+   AND a.type = $2
+   AND a.transfer_id IS NULL
+   AND a.date BETWEEN $3::date AND $4::date
+ GROUP BY a.category_id, c.title
+ ORDER BY total DESC, c.title, a.category_id
+`
+
+type GetCategoryBreakdownParams struct {
+	LedgerID  int32     `json:"ledger_id"`
+	Type      string    `json:"type"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+type GetCategoryBreakdownRow struct {
+	CategoryID    sql.NullInt32  `json:"category_id"`
+	CategoryTitle sql.NullString `json:"category_title"`
+	Total         int64          `json:"total"`
+	Count         int64          `json:"count"`
+	Share         float64        `json:"share"`
+	Average       float64        `json:"average"`
+}
+
+// Totals of the accounts of @type between @start_date and @end_date per
+// category, largest first. Share is the percentage of the total of all
+// categories, average the mean value per account. Transfer legs are left out.
+func (q *Queries) GetCategoryBreakdown(ctx context.Context, arg GetCategoryBreakdownParams) ([]GetCategoryBreakdownRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCategoryBreakdown,
+		arg.LedgerID,
+		arg.Type,
+		arg.StartDate,
+		arg.EndDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetCategoryBreakdownRow{}
+	for rows.Next() {
+		var i GetCategoryBreakdownRow
+		if err := rows.Scan(
+			&i.CategoryID,
+			&i.CategoryTitle,
+			&i.Total,
+			&i.Count,
+			&i.Share,
+			&i.Average,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateAccounts = `-- name: UpdateAccounts :one
-UPDATE accounts SET title = $2, description = $3, value = $4 WHERE id = $1 RETURNING id, user_id, category_id, title, type, description, value, date, created_at
+UPDATE accounts SET title = $2, description = $3, value = $4 WHERE id = $1 AND ledger_id = $5 RETURNING id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id
 `
 
 type UpdateAccountsParams struct {
@@ -208,6 +458,7 @@ type UpdateAccountsParams struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	Value       int32  `json:"value"`
+	LedgerID    int32  `json:"ledger_id"`
 }
 
 func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error) {
@@ -216,11 +467,11 @@ func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams)
 		arg.Title,
 		arg.Description,
 		arg.Value,
+		arg.LedgerID,
 	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
-		&i.UserID,
 		&i.CategoryID,
 		&i.Title,
 		&i.Type,
@@ -228,6 +479,12 @@ func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams)
 		&i.Value,
 		&i.Date,
 		&i.CreatedAt,
+		&i.RecurrenceID,
+		&i.InstallmentGroupID,
+		&i.InstallmentNumber,
+		&i.WalletID,
+		&i.TransferID,
+		&i.LedgerID,
 	)
 	return i, err
 }
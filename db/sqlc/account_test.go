@@ -13,8 +13,11 @@ import (
 func createRandomAccount(t *testing.T) Account {
 	cat := createRandomCategory(t)
 	arg := CreateAccountParams{
-		UserID:      cat.UserID,
-		CategoryID:  cat.ID,
+		LedgerID: cat.LedgerID,
+		CategoryID: sql.NullInt32{
+			Int32: cat.ID,
+			Valid: true,
+		},
 		Title:       util.RandomString(12),
 		Type:        cat.Type,
 		Description: util.RandomString(20),
@@ -26,7 +29,7 @@ func createRandomAccount(t *testing.T) Account {
 
 	require.NoError(t, err)
 	require.NotEmpty(t, account)
-	require.Equal(t, arg.UserID, account.UserID)
+	require.Equal(t, arg.LedgerID, account.LedgerID)
 	require.Equal(t, arg.Title, account.Title)
 	require.Equal(t, arg.Type, account.Type)
 	require.Equal(t, arg.Description, account.Description)
@@ -43,13 +46,13 @@ func TestCreateAccount(t *testing.T) {
 
 func TestGetAccountById(t *testing.T) {
 	acc1 := createRandomAccount(t)
-	acc2, err := testQueries.GetAccount(context.Background(), acc1.ID)
+	acc2, err := testQueries.GetAccount(context.Background(), GetAccountParams{ID: acc1.ID, LedgerID: acc1.LedgerID})
 
 	require.NoError(t, err)
 	require.NotEmpty(t, acc2)
 
 	require.Equal(t, acc1.ID, acc2.ID)
-	require.Equal(t, acc1.UserID, acc2.UserID)
+	require.Equal(t, acc1.LedgerID, acc2.LedgerID)
 	require.Equal(t, acc1.Title, acc2.Title)
 	require.Equal(t, acc1.Type, acc2.Type)
 	require.Equal(t, acc1.Description, acc2.Description)
@@ -61,7 +64,7 @@ func TestGetAccountById(t *testing.T) {
 
 func TestDeleteAccount(t *testing.T) {
 	acc := createRandomAccount(t)
-	err := testQueries.DeleteAccount(context.Background(), acc.ID)
+	err := testQueries.DeleteAccount(context.Background(), DeleteAccountParams{ID: acc.ID, LedgerID: acc.LedgerID})
 
 	require.NoError(t, err)
 }
@@ -74,6 +77,7 @@ func TestUpdateAccount(t *testing.T) {
 		Title:       util.RandomString(12),
 		Description: util.RandomString(20),
 		Value:       20,
+		LedgerID:    acc1.LedgerID,
 	}
 
 	cat2, err := testQueries.UpdateAccounts(context.Background(), arg)
@@ -94,14 +98,11 @@ func TestListAccounts(t *testing.T) {
 	lastAccount := createRandomAccount(t)
 
 	arg := GetAccountsParams{
-		UserID:      lastAccount.UserID,
+		LedgerID:    lastAccount.LedgerID,
 		Type:        lastAccount.Type,
 		Title:       lastAccount.Title,
 		Description: lastAccount.Description,
-		CategoryID: sql.NullInt32{
-			Valid: true,
-			Int32: lastAccount.CategoryID,
-		},
+		CategoryID:  lastAccount.CategoryID,
 		Date: sql.NullTime{
 			Valid: true,
 			Time:  lastAccount.Date,
@@ -116,7 +117,7 @@ func TestListAccounts(t *testing.T) {
 	for _, acc := range accs {
 
 		require.Equal(t, lastAccount.ID, acc.ID)
-		require.Equal(t, lastAccount.UserID, acc.UserID)
+		require.Equal(t, lastAccount.LedgerID, acc.LedgerID)
 		require.Equal(t, arg.Title, acc.Title)
 		require.NotEmpty(t, acc.CategoryTitle)
 		require.Equal(t, lastAccount.Description, acc.Description)
@@ -133,8 +134,8 @@ func TestListGetReports(t *testing.T) {
 	lastAccount := createRandomAccount(t)
 
 	arg := GetAccountsReportsParams{
-		UserID: lastAccount.UserID,
-		Type:   lastAccount.Type,
+		LedgerID: lastAccount.LedgerID,
+		Type:     lastAccount.Type,
 	}
 
 	total, err := testQueries.GetAccountsReports(context.Background(), arg)
@@ -148,13 +149,76 @@ func TestListGetAccountGraph(t *testing.T) {
 	lastAccount := createRandomAccount(t)
 
 	arg := GetAccountGraphParams{
-		UserID: lastAccount.UserID,
-		Type:   lastAccount.Type,
+		Granularity: "day",
+		StartDate:   lastAccount.Date.AddDate(0, 0, -1),
+		EndDate:     lastAccount.Date,
+		LedgerID:    lastAccount.LedgerID,
+		CategoryID:  lastAccount.CategoryID,
 	}
 
-	total, err := testQueries.GetAccountGraph(context.Background(), arg)
+	points, err := testQueries.GetAccountGraph(context.Background(), arg)
 
 	require.NoError(t, err)
-	require.NotEmpty(t, total)
+	require.Len(t, points, 2)
+	require.Zero(t, points[0].CumulativeIncome+points[0].CumulativeExpense)
+	require.Equal(t, int64(lastAccount.Value), points[1].CumulativeIncome+points[1].CumulativeExpense)
+}
+
+func TestGetAccountsSummary(t *testing.T) {
+	cat := createRandomCategory(t)
+	date := time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)
+	_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		LedgerID:    cat.LedgerID,
+		CategoryID:  sql.NullInt32{Int32: cat.ID, Valid: true},
+		Title:       util.RandomString(12),
+		Type:        "credit",
+		Description: util.RandomString(20),
+		Value:       250,
+		Date:        date,
+	})
+	require.NoError(t, err)
+
+	periods, err := testQueries.GetAccountsSummary(context.Background(), GetAccountsSummaryParams{
+		Granularity: "month",
+		StartDate:   date.AddDate(0, -1, 0),
+		EndDate:     date.AddDate(0, 1, 0),
+		LedgerID:    cat.LedgerID,
+	})
+	require.NoError(t, err)
+	require.Len(t, periods, 3)
+	require.Zero(t, periods[0].Income)
+	require.Equal(t, int64(250), periods[1].Income)
+	require.Equal(t, int64(250), periods[1].Net)
+	require.Zero(t, periods[2].Net)
+}
 
+func TestGetCategoryBreakdown(t *testing.T) {
+	cat := createRandomCategory(t)
+	date := time.Date(2023, time.May, 10, 0, 0, 0, 0, time.UTC)
+	for _, value := range []int32{100, 50} {
+		_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			LedgerID:    cat.LedgerID,
+			CategoryID:  sql.NullInt32{Int32: cat.ID, Valid: true},
+			Title:       util.RandomString(12),
+			Type:        cat.Type,
+			Description: util.RandomString(20),
+			Value:       value,
+			Date:        date,
+		})
+		require.NoError(t, err)
+	}
+
+	rows, err := testQueries.GetCategoryBreakdown(context.Background(), GetCategoryBreakdownParams{
+		LedgerID:  cat.LedgerID,
+		Type:      cat.Type,
+		StartDate: date,
+		EndDate:   date,
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, cat.ID, rows[0].CategoryID.Int32)
+	require.Equal(t, int64(150), rows[0].Total)
+	require.Equal(t, int64(2), rows[0].Count)
+	require.Equal(t, 100.0, rows[0].Share)
+	require.Equal(t, 75.0, rows[0].Average)
 }
@@ -0,0 +1,180 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: admin.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const countUsersByRole = `-- name: CountUsersByRole :many
+SELECT role, count(*) AS users FROM users
+ GROUP BY role
+ ORDER BY role
+`
+
+type CountUsersByRoleRow struct {
+	Role  string `json:"role"`
+	Users int64  `json:"users"`
+}
+
+func (q *Queries) CountUsersByRole(ctx context.Context) ([]CountUsersByRoleRow, error) {
+	rows, err := q.db.QueryContext(ctx, countUsersByRole)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountUsersByRoleRow{}
+	for rows.Next() {
+		var i CountUsersByRoleRow
+		if err := rows.Scan(&i.Role, &i.Users); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createAuditLog = `-- name: CreateAuditLog :one
+INSERT INTO audit_logs (
+    actor_id,
+    action,
+    target_user_id,
+    status,
+    details,
+    client_ip
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, actor_id, action, target_user_id, status, details, client_ip, created_at
+`
+
+type CreateAuditLogParams struct {
+	ActorID      sql.NullInt32 `json:"actor_id"`
+	Action       string        `json:"action"`
+	TargetUserID sql.NullInt32 `json:"target_user_id"`
+	Status       int32         `json:"status"`
+	Details      string        `json:"details"`
+	ClientIp     string        `json:"client_ip"`
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, createAuditLog,
+		arg.ActorID,
+		arg.Action,
+		arg.TargetUserID,
+		arg.Status,
+		arg.Details,
+		arg.ClientIp,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.ActorID,
+		&i.Action,
+		&i.TargetUserID,
+		&i.Status,
+		&i.Details,
+		&i.ClientIp,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSystemStats = `-- name: GetSystemStats :one
+SELECT
+    (SELECT count(*) FROM users) AS users,
+    (SELECT count(*) FROM users WHERE email_verified_at IS NOT NULL) AS verified_users,
+    (SELECT count(*) FROM users WHERE disabled_at IS NOT NULL) AS disabled_users,
+    (SELECT count(*) FROM user_totp WHERE confirmed_at IS NOT NULL) AS two_factor_users,
+    (SELECT count(*) FROM sessions WHERE revoked_at IS NULL AND expires_at > now()) AS active_sessions,
+    (SELECT count(*) FROM categories) AS categories,
+    (SELECT count(*) FROM accounts) AS accounts,
+    (SELECT count(*) FROM wallets) AS wallets,
+    (SELECT count(*) FROM transfers) AS transfers,
+    (SELECT count(*) FROM recurrences) AS recurrences
+`
+
+type GetSystemStatsRow struct {
+	Users          int64 `json:"users"`
+	VerifiedUsers  int64 `json:"verified_users"`
+	DisabledUsers  int64 `json:"disabled_users"`
+	TwoFactorUsers int64 `json:"two_factor_users"`
+	ActiveSessions int64 `json:"active_sessions"`
+	Categories     int64 `json:"categories"`
+	Accounts       int64 `json:"accounts"`
+	Wallets        int64 `json:"wallets"`
+	Transfers      int64 `json:"transfers"`
+	Recurrences    int64 `json:"recurrences"`
+}
+
+func (q *Queries) GetSystemStats(ctx context.Context) (GetSystemStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getSystemStats)
+	var i GetSystemStatsRow
+	err := row.Scan(
+		&i.Users,
+		&i.VerifiedUsers,
+		&i.DisabledUsers,
+		&i.TwoFactorUsers,
+		&i.ActiveSessions,
+		&i.Categories,
+		&i.Accounts,
+		&i.Wallets,
+		&i.Transfers,
+		&i.Recurrences,
+	)
+	return i, err
+}
+
+const listAuditLogs = `-- name: ListAuditLogs :many
+SELECT id, actor_id, action, target_user_id, status, details, client_ip, created_at FROM audit_logs
+ WHERE $1::int IS NULL
+    OR target_user_id = $1
+ ORDER BY id DESC
+ LIMIT $3 OFFSET $2
+`
+
+type ListAuditLogsParams struct {
+	TargetUserID sql.NullInt32 `json:"target_user_id"`
+	PageOffset   int32         `json:"page_offset"`
+	PageLimit    int32         `json:"page_limit"`
+}
+
+func (q *Queries) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditLogs, arg.TargetUserID, arg.PageOffset, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AuditLog{}
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorID,
+			&i.Action,
+			&i.TargetUserID,
+			&i.Status,
+			&i.Details,
+			&i.ClientIp,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
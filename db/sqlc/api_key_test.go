@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAPIKey(t *testing.T, user User) ApiKey {
+	arg := CreateAPIKeyParams{
+		UserID:    user.ID,
+		Name:      util.RandomString(10),
+		KeyHash:   util.RandomString(32),
+		Prefix:    util.RandomString(10),
+		Scopes:    []string{"read", "accounts:write"},
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, apiKey.UserID)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.KeyHash, apiKey.KeyHash)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.WithinDuration(t, arg.ExpiresAt.Time, apiKey.ExpiresAt.Time, time.Second)
+	require.False(t, apiKey.LastUsedAt.Valid)
+	require.False(t, apiKey.RevokedAt.Valid)
+
+	return apiKey
+}
+
+func TestGetAPIKeyByHash(t *testing.T) {
+	apiKey := createRandomAPIKey(t, createRandomUser(t))
+
+	found, err := testQueries.GetAPIKeyByHash(context.Background(), apiKey.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, apiKey.ID, found.ID)
+	require.Equal(t, apiKey.Scopes, found.Scopes)
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	user := createRandomUser(t)
+	revoked := createRandomAPIKey(t, user)
+	kept := createRandomAPIKey(t, user)
+
+	_, err := testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{ID: revoked.ID, UserID: user.ID + 1})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.RevokeAPIKey(context.Background(), RevokeAPIKeyParams{ID: revoked.ID, UserID: user.ID})
+	require.NoError(t, err)
+
+	apiKeys, err := testQueries.ListAPIKeys(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 1)
+	require.Equal(t, kept.ID, apiKeys[0].ID)
+}
@@ -11,16 +11,16 @@ import (
 
 const createCategory = `-- name: CreateCategory :one
 INSERT INTO categories (
-    user_id,
+    ledger_id,
     title,
     type,
     description
 ) VALUES ($1, $2, $3, $4)
-RETURNING id, title, type, description, user_id, created_at
+RETURNING id, title, type, description, created_at, ledger_id
 `
 
 type CreateCategoryParams struct {
-	UserID      int32  `json:"user_id"`
+	LedgerID    int32  `json:"ledger_id"`
 	Title       string `json:"title"`
 	Type        string `json:"type"`
 	Description string `json:"description"`
@@ -28,7 +28,7 @@ type CreateCategoryParams struct {
 
 func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
 	row := q.db.QueryRowContext(ctx, createCategory,
-		arg.UserID,
+		arg.LedgerID,
 		arg.Title,
 		arg.Type,
 		arg.Description,
@@ -39,31 +39,45 @@ func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams)
 		&i.Title,
 		&i.Type,
 		&i.Description,
-		&i.UserID,
 		&i.CreatedAt,
+		&i.LedgerID,
 	)
 	return i, err
 }
 
 const deleteCategory = `-- name: DeleteCategory :exec
-DELETE FROM categories WHERE id = $1
+DELETE FROM categories WHERE id = $1 AND ledger_id = $2
 `
 
-func (q *Queries) DeleteCategory(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteCategory, id)
+type DeleteCategoryParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) error {
+	_, err := q.db.ExecContext(ctx, deleteCategory, arg.ID, arg.LedgerID)
+	return err
+}
+
+const deleteLedgerCategories = `-- name: DeleteLedgerCategories :exec
+DELETE FROM categories WHERE ledger_id = $1
+`
+
+func (q *Queries) DeleteLedgerCategories(ctx context.Context, ledgerID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteLedgerCategories, ledgerID)
 	return err
 }
 
 const getCategories = `-- name: GetCategories :many
-SELECT id, title, type, description, user_id, created_at FROM categories 
- WHERE user_id = $1
+SELECT id, title, type, description, created_at, ledger_id FROM categories 
+ WHERE ledger_id = $1
    AND type = $2
    AND (UPPER(title) LIKE CONCAT('%', UPPER($3::text), '%'))
    AND (UPPER(description) LIKE CONCAT('%', UPPER($4::text), '%'))
 `
 
 type GetCategoriesParams struct {
-	UserID      int32  `json:"user_id"`
+	LedgerID    int32  `json:"ledger_id"`
 	Type        string `json:"type"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
@@ -71,7 +85,7 @@ type GetCategoriesParams struct {
 
 func (q *Queries) GetCategories(ctx context.Context, arg GetCategoriesParams) ([]Category, error) {
 	rows, err := q.db.QueryContext(ctx, getCategories,
-		arg.UserID,
+		arg.LedgerID,
 		arg.Type,
 		arg.Title,
 		arg.Description,
@@ -88,8 +102,8 @@ func (q *Queries) GetCategories(ctx context.Context, arg GetCategoriesParams) ([
 			&i.Title,
 			&i.Type,
 			&i.Description,
-			&i.UserID,
 			&i.CreatedAt,
+			&i.LedgerID,
 		); err != nil {
 			return nil, err
 		}
@@ -105,43 +119,54 @@ func (q *Queries) GetCategories(ctx context.Context, arg GetCategoriesParams) ([
 }
 
 const getCategory = `-- name: GetCategory :one
-SELECT id, title, type, description, user_id, created_at FROM categories WHERE id = $1 LIMIT 1
+SELECT id, title, type, description, created_at, ledger_id FROM categories WHERE id = $1 AND ledger_id = $2 LIMIT 1
 `
 
-func (q *Queries) GetCategory(ctx context.Context, id int32) (Category, error) {
-	row := q.db.QueryRowContext(ctx, getCategory, id)
+type GetCategoryParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error) {
+	row := q.db.QueryRowContext(ctx, getCategory, arg.ID, arg.LedgerID)
 	var i Category
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Type,
 		&i.Description,
-		&i.UserID,
 		&i.CreatedAt,
+		&i.LedgerID,
 	)
 	return i, err
 }
 
 const updateCategories = `-- name: UpdateCategories :one
-UPDATE categories SET title = $2, description = $3 WHERE id = $1 RETURNING id, title, type, description, user_id, created_at
+UPDATE categories SET title = $2, description = $3 WHERE id = $1 AND ledger_id = $4 RETURNING id, title, type, description, created_at, ledger_id
 `
 
 type UpdateCategoriesParams struct {
 	ID          int32  `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	LedgerID    int32  `json:"ledger_id"`
 }
 
 func (q *Queries) UpdateCategories(ctx context.Context, arg UpdateCategoriesParams) (Category, error) {
-	row := q.db.QueryRowContext(ctx, updateCategories, arg.ID, arg.Title, arg.Description)
+	row := q.db.QueryRowContext(ctx, updateCategories,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.LedgerID,
+	)
 	var i Category
 	err := row.Scan(
 		&i.ID,
 		&i.Title,
 		&i.Type,
 		&i.Description,
-		&i.UserID,
 		&i.CreatedAt,
+		&i.LedgerID,
 	)
 	return i, err
 }
@@ -9,9 +9,10 @@ import (
 )
 
 func createRandomCategory(t *testing.T) Category {
-	user1 := createRandomUser(t)
+	ledger, err := testQueries.GetPersonalLedger(context.Background(), createRandomUser(t).ID)
+	require.NoError(t, err)
 	arg := CreateCategoryParams{
-		UserID:      user1.ID,
+		LedgerID:    ledger.ID,
 		Title:       util.RandomString(12),
 		Type:        "debit",
 		Description: util.RandomString(20),
@@ -21,7 +22,7 @@ func createRandomCategory(t *testing.T) Category {
 
 	require.NoError(t, err)
 	require.NotEmpty(t, category)
-	require.Equal(t, arg.UserID, category.UserID)
+	require.Equal(t, arg.LedgerID, category.LedgerID)
 	require.Equal(t, arg.Title, category.Title)
 	require.Equal(t, arg.Type, category.Type)
 	require.Equal(t, arg.Description, category.Description)
@@ -35,13 +36,13 @@ func TestCreateCategory(t *testing.T) {
 
 func TestGetCategoryById(t *testing.T) {
 	cat1 := createRandomCategory(t)
-	cat2, err := testQueries.GetCategory(context.Background(), cat1.ID)
+	cat2, err := testQueries.GetCategory(context.Background(), GetCategoryParams{ID: cat1.ID, LedgerID: cat1.LedgerID})
 
 	require.NoError(t, err)
 	require.NotEmpty(t, cat2)
 
 	require.Equal(t, cat1.ID, cat2.ID)
-	require.Equal(t, cat1.UserID, cat2.UserID)
+	require.Equal(t, cat1.LedgerID, cat2.LedgerID)
 	require.Equal(t, cat1.Title, cat2.Title)
 	require.Equal(t, cat1.Type, cat2.Type)
 	require.Equal(t, cat1.Description, cat2.Description)
@@ -50,7 +51,7 @@ func TestGetCategoryById(t *testing.T) {
 
 func TestDeleteCategory(t *testing.T) {
 	cat1 := createRandomCategory(t)
-	err := testQueries.DeleteCategory(context.Background(), cat1.ID)
+	err := testQueries.DeleteCategory(context.Background(), DeleteCategoryParams{ID: cat1.ID, LedgerID: cat1.LedgerID})
 
 	require.NoError(t, err)
 }
@@ -62,6 +63,7 @@ func TestUpdateCategory(t *testing.T) {
 		ID:          cat1.ID,
 		Title:       util.RandomString(12),
 		Description: util.RandomString(20),
+		LedgerID:    cat1.LedgerID,
 	}
 
 	cat2, err := testQueries.UpdateCategories(context.Background(), arg)
@@ -80,7 +82,7 @@ func TestListCategories(t *testing.T) {
 	lastCategory := createRandomCategory(t)
 
 	arg := GetCategoriesParams{
-		UserID:      lastCategory.UserID,
+		LedgerID:    lastCategory.LedgerID,
 		Type:        lastCategory.Type,
 		Title:       lastCategory.Title,
 		Description: lastCategory.Description,
@@ -94,7 +96,7 @@ func TestListCategories(t *testing.T) {
 	for _, cat := range cats {
 
 		require.Equal(t, lastCategory.ID, cat.ID)
-		require.Equal(t, lastCategory.UserID, cat.UserID)
+		require.Equal(t, lastCategory.LedgerID, cat.LedgerID)
 		require.Equal(t, arg.Title, cat.Title)
 		require.Equal(t, lastCategory.Description, cat.Description)
 		require.Equal(t, lastCategory.Title, cat.Title)
@@ -0,0 +1,256 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: installment.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createInstallmentAccount = `-- name: CreateInstallmentAccount :one
+INSERT INTO accounts (
+    ledger_id,
+    category_id,
+    installment_group_id,
+    installment_number,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id
+`
+
+type CreateInstallmentAccountParams struct {
+	LedgerID           int32         `json:"ledger_id"`
+	CategoryID         sql.NullInt32 `json:"category_id"`
+	InstallmentGroupID sql.NullInt32 `json:"installment_group_id"`
+	InstallmentNumber  sql.NullInt32 `json:"installment_number"`
+	Title              string        `json:"title"`
+	Type               string        `json:"type"`
+	Description        string        `json:"description"`
+	Date               time.Time     `json:"date"`
+	Value              int32         `json:"value"`
+}
+
+func (q *Queries) CreateInstallmentAccount(ctx context.Context, arg CreateInstallmentAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createInstallmentAccount,
+		arg.LedgerID,
+		arg.CategoryID,
+		arg.InstallmentGroupID,
+		arg.InstallmentNumber,
+		arg.Title,
+		arg.Type,
+		arg.Description,
+		arg.Date,
+		arg.Value,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.Value,
+		&i.Date,
+		&i.CreatedAt,
+		&i.RecurrenceID,
+		&i.InstallmentGroupID,
+		&i.InstallmentNumber,
+		&i.WalletID,
+		&i.TransferID,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const createInstallmentGroup = `-- name: CreateInstallmentGroup :one
+INSERT INTO installment_groups (
+    ledger_id,
+    category_id,
+    title,
+    type,
+    description,
+    total_value,
+    installment_count,
+    first_date
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, category_id, title, type, description, total_value, installment_count, first_date, created_at, ledger_id
+`
+
+type CreateInstallmentGroupParams struct {
+	LedgerID         int32     `json:"ledger_id"`
+	CategoryID       int32     `json:"category_id"`
+	Title            string    `json:"title"`
+	Type             string    `json:"type"`
+	Description      string    `json:"description"`
+	TotalValue       int32     `json:"total_value"`
+	InstallmentCount int32     `json:"installment_count"`
+	FirstDate        time.Time `json:"first_date"`
+}
+
+func (q *Queries) CreateInstallmentGroup(ctx context.Context, arg CreateInstallmentGroupParams) (InstallmentGroup, error) {
+	row := q.db.QueryRowContext(ctx, createInstallmentGroup,
+		arg.LedgerID,
+		arg.CategoryID,
+		arg.Title,
+		arg.Type,
+		arg.Description,
+		arg.TotalValue,
+		arg.InstallmentCount,
+		arg.FirstDate,
+	)
+	var i InstallmentGroup
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.TotalValue,
+		&i.InstallmentCount,
+		&i.FirstDate,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const deleteInstallmentGroup = `-- name: DeleteInstallmentGroup :exec
+DELETE FROM installment_groups WHERE id = $1 AND ledger_id = $2
+`
+
+type DeleteInstallmentGroupParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) DeleteInstallmentGroup(ctx context.Context, arg DeleteInstallmentGroupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteInstallmentGroup, arg.ID, arg.LedgerID)
+	return err
+}
+
+const deleteLedgerInstallmentGroups = `-- name: DeleteLedgerInstallmentGroups :exec
+DELETE FROM installment_groups WHERE ledger_id = $1
+`
+
+func (q *Queries) DeleteLedgerInstallmentGroups(ctx context.Context, ledgerID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteLedgerInstallmentGroups, ledgerID)
+	return err
+}
+
+const getInstallmentAccounts = `-- name: GetInstallmentAccounts :many
+SELECT id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id FROM accounts
+ WHERE installment_group_id = $1
+ ORDER BY installment_number
+`
+
+func (q *Queries) GetInstallmentAccounts(ctx context.Context, installmentGroupID sql.NullInt32) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, getInstallmentAccounts, installmentGroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Type,
+			&i.Description,
+			&i.Value,
+			&i.Date,
+			&i.CreatedAt,
+			&i.RecurrenceID,
+			&i.InstallmentGroupID,
+			&i.InstallmentNumber,
+			&i.WalletID,
+			&i.TransferID,
+			&i.LedgerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInstallmentGroup = `-- name: GetInstallmentGroup :one
+SELECT id, category_id, title, type, description, total_value, installment_count, first_date, created_at, ledger_id FROM installment_groups WHERE id = $1 AND ledger_id = $2 LIMIT 1
+`
+
+type GetInstallmentGroupParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) GetInstallmentGroup(ctx context.Context, arg GetInstallmentGroupParams) (InstallmentGroup, error) {
+	row := q.db.QueryRowContext(ctx, getInstallmentGroup, arg.ID, arg.LedgerID)
+	var i InstallmentGroup
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.TotalValue,
+		&i.InstallmentCount,
+		&i.FirstDate,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const updateInstallmentGroup = `-- name: UpdateInstallmentGroup :one
+UPDATE installment_groups
+   SET title = $2, description = $3, total_value = $4
+ WHERE id = $1 AND ledger_id = $5
+RETURNING id, category_id, title, type, description, total_value, installment_count, first_date, created_at, ledger_id
+`
+
+type UpdateInstallmentGroupParams struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	TotalValue  int32  `json:"total_value"`
+	LedgerID    int32  `json:"ledger_id"`
+}
+
+func (q *Queries) UpdateInstallmentGroup(ctx context.Context, arg UpdateInstallmentGroupParams) (InstallmentGroup, error) {
+	row := q.db.QueryRowContext(ctx, updateInstallmentGroup,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.TotalValue,
+		arg.LedgerID,
+	)
+	var i InstallmentGroup
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.TotalValue,
+		&i.InstallmentCount,
+		&i.FirstDate,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
@@ -0,0 +1,432 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: ledger.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const addLedgerMember = `-- name: AddLedgerMember :one
+INSERT INTO ledger_members (
+    ledger_id,
+    user_id,
+    role
+) VALUES ($1, $2, $3)
+RETURNING ledger_id, user_id, role, created_at
+`
+
+type AddLedgerMemberParams struct {
+	LedgerID int32  `json:"ledger_id"`
+	UserID   int32  `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) AddLedgerMember(ctx context.Context, arg AddLedgerMemberParams) (LedgerMember, error) {
+	row := q.db.QueryRowContext(ctx, addLedgerMember, arg.LedgerID, arg.UserID, arg.Role)
+	var i LedgerMember
+	err := row.Scan(
+		&i.LedgerID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLedger = `-- name: CreateLedger :one
+INSERT INTO ledgers (
+    name,
+    owner_id,
+    personal
+) VALUES ($1, $2, $3)
+RETURNING id, name, owner_id, personal, created_at
+`
+
+type CreateLedgerParams struct {
+	Name     string `json:"name"`
+	OwnerID  int32  `json:"owner_id"`
+	Personal bool   `json:"personal"`
+}
+
+func (q *Queries) CreateLedger(ctx context.Context, arg CreateLedgerParams) (Ledger, error) {
+	row := q.db.QueryRowContext(ctx, createLedger, arg.Name, arg.OwnerID, arg.Personal)
+	var i Ledger
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Personal,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLedgerInvitation = `-- name: CreateLedgerInvitation :one
+INSERT INTO ledger_invitations (
+    ledger_id,
+    email,
+    role,
+    token_hash,
+    invited_by,
+    expires_at
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, ledger_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+`
+
+type CreateLedgerInvitationParams struct {
+	LedgerID  int32         `json:"ledger_id"`
+	Email     string        `json:"email"`
+	Role      string        `json:"role"`
+	TokenHash string        `json:"token_hash"`
+	InvitedBy sql.NullInt32 `json:"invited_by"`
+	ExpiresAt time.Time     `json:"expires_at"`
+}
+
+func (q *Queries) CreateLedgerInvitation(ctx context.Context, arg CreateLedgerInvitationParams) (LedgerInvitation, error) {
+	row := q.db.QueryRowContext(ctx, createLedgerInvitation,
+		arg.LedgerID,
+		arg.Email,
+		arg.Role,
+		arg.TokenHash,
+		arg.InvitedBy,
+		arg.ExpiresAt,
+	)
+	var i LedgerInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.LedgerID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteLedger = `-- name: DeleteLedger :exec
+DELETE FROM ledgers WHERE id = $1
+`
+
+func (q *Queries) DeleteLedger(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteLedger, id)
+	return err
+}
+
+const deleteLedgerInvitation = `-- name: DeleteLedgerInvitation :one
+DELETE FROM ledger_invitations WHERE id = $1 AND ledger_id = $2
+RETURNING id, ledger_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at
+`
+
+type DeleteLedgerInvitationParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) DeleteLedgerInvitation(ctx context.Context, arg DeleteLedgerInvitationParams) (LedgerInvitation, error) {
+	row := q.db.QueryRowContext(ctx, deleteLedgerInvitation, arg.ID, arg.LedgerID)
+	var i LedgerInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.LedgerID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLedger = `-- name: GetLedger :one
+SELECT id, name, owner_id, personal, created_at FROM ledgers WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetLedger(ctx context.Context, id int32) (Ledger, error) {
+	row := q.db.QueryRowContext(ctx, getLedger, id)
+	var i Ledger
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Personal,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLedgerInvitationByHash = `-- name: GetLedgerInvitationByHash :one
+SELECT id, ledger_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at FROM ledger_invitations WHERE token_hash = $1 LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetLedgerInvitationByHash(ctx context.Context, tokenHash string) (LedgerInvitation, error) {
+	row := q.db.QueryRowContext(ctx, getLedgerInvitationByHash, tokenHash)
+	var i LedgerInvitation
+	err := row.Scan(
+		&i.ID,
+		&i.LedgerID,
+		&i.Email,
+		&i.Role,
+		&i.TokenHash,
+		&i.InvitedBy,
+		&i.ExpiresAt,
+		&i.AcceptedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLedgerMember = `-- name: GetLedgerMember :one
+SELECT ledger_id, user_id, role, created_at FROM ledger_members WHERE ledger_id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetLedgerMemberParams struct {
+	LedgerID int32 `json:"ledger_id"`
+	UserID   int32 `json:"user_id"`
+}
+
+func (q *Queries) GetLedgerMember(ctx context.Context, arg GetLedgerMemberParams) (LedgerMember, error) {
+	row := q.db.QueryRowContext(ctx, getLedgerMember, arg.LedgerID, arg.UserID)
+	var i LedgerMember
+	err := row.Scan(
+		&i.LedgerID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPersonalLedger = `-- name: GetPersonalLedger :one
+SELECT id, name, owner_id, personal, created_at FROM ledgers WHERE owner_id = $1 AND personal LIMIT 1
+`
+
+func (q *Queries) GetPersonalLedger(ctx context.Context, ownerID int32) (Ledger, error) {
+	row := q.db.QueryRowContext(ctx, getPersonalLedger, ownerID)
+	var i Ledger
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Personal,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listLedgerInvitations = `-- name: ListLedgerInvitations :many
+SELECT id, ledger_id, email, role, token_hash, invited_by, expires_at, accepted_at, created_at FROM ledger_invitations
+ WHERE ledger_id = $1
+   AND accepted_at IS NULL
+   AND expires_at > now()
+ ORDER BY id
+`
+
+func (q *Queries) ListLedgerInvitations(ctx context.Context, ledgerID int32) ([]LedgerInvitation, error) {
+	rows, err := q.db.QueryContext(ctx, listLedgerInvitations, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LedgerInvitation{}
+	for rows.Next() {
+		var i LedgerInvitation
+		if err := rows.Scan(
+			&i.ID,
+			&i.LedgerID,
+			&i.Email,
+			&i.Role,
+			&i.TokenHash,
+			&i.InvitedBy,
+			&i.ExpiresAt,
+			&i.AcceptedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerMembers = `-- name: ListLedgerMembers :many
+SELECT m.user_id, u.username, m.role, m.created_at
+  FROM ledger_members m
+  JOIN users u ON u.id = m.user_id
+ WHERE m.ledger_id = $1
+ ORDER BY m.created_at, m.user_id
+`
+
+type ListLedgerMembersRow struct {
+	UserID    int32     `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) ListLedgerMembers(ctx context.Context, ledgerID int32) ([]ListLedgerMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listLedgerMembers, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLedgerMembersRow{}
+	for rows.Next() {
+		var i ListLedgerMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Role,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserLedgers = `-- name: ListUserLedgers :many
+SELECT l.id, l.name, l.owner_id, l.personal, l.created_at, m.role
+  FROM ledgers l
+  JOIN ledger_members m ON m.ledger_id = l.id
+ WHERE m.user_id = $1
+ ORDER BY l.personal DESC, l.name, l.id
+`
+
+type ListUserLedgersRow struct {
+	ID        int32     `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   int32     `json:"owner_id"`
+	Personal  bool      `json:"personal"`
+	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"`
+}
+
+func (q *Queries) ListUserLedgers(ctx context.Context, userID int32) ([]ListUserLedgersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserLedgers, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserLedgersRow{}
+	for rows.Next() {
+		var i ListUserLedgersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OwnerID,
+			&i.Personal,
+			&i.CreatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLedgerInvitationAccepted = `-- name: MarkLedgerInvitationAccepted :exec
+UPDATE ledger_invitations SET accepted_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkLedgerInvitationAccepted(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markLedgerInvitationAccepted, id)
+	return err
+}
+
+const removeLedgerMember = `-- name: RemoveLedgerMember :one
+DELETE FROM ledger_members WHERE ledger_id = $1 AND user_id = $2
+RETURNING ledger_id, user_id, role, created_at
+`
+
+type RemoveLedgerMemberParams struct {
+	LedgerID int32 `json:"ledger_id"`
+	UserID   int32 `json:"user_id"`
+}
+
+func (q *Queries) RemoveLedgerMember(ctx context.Context, arg RemoveLedgerMemberParams) (LedgerMember, error) {
+	row := q.db.QueryRowContext(ctx, removeLedgerMember, arg.LedgerID, arg.UserID)
+	var i LedgerMember
+	err := row.Scan(
+		&i.LedgerID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateLedger = `-- name: UpdateLedger :one
+UPDATE ledgers SET name = $2 WHERE id = $1 RETURNING id, name, owner_id, personal, created_at
+`
+
+type UpdateLedgerParams struct {
+	ID   int32  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (q *Queries) UpdateLedger(ctx context.Context, arg UpdateLedgerParams) (Ledger, error) {
+	row := q.db.QueryRowContext(ctx, updateLedger, arg.ID, arg.Name)
+	var i Ledger
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OwnerID,
+		&i.Personal,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateLedgerMemberRole = `-- name: UpdateLedgerMemberRole :one
+UPDATE ledger_members SET role = $3
+ WHERE ledger_id = $1 AND user_id = $2
+RETURNING ledger_id, user_id, role, created_at
+`
+
+type UpdateLedgerMemberRoleParams struct {
+	LedgerID int32  `json:"ledger_id"`
+	UserID   int32  `json:"user_id"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) UpdateLedgerMemberRole(ctx context.Context, arg UpdateLedgerMemberRoleParams) (LedgerMember, error) {
+	row := q.db.QueryRowContext(ctx, updateLedgerMemberRole, arg.LedgerID, arg.UserID, arg.Role)
+	var i LedgerMember
+	err := row.Scan(
+		&i.LedgerID,
+		&i.UserID,
+		&i.Role,
+		&i.CreatedAt,
+	)
+	return i, err
+}
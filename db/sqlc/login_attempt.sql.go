@@ -0,0 +1,71 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: login_attempt.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const deleteLoginAttempt = `-- name: DeleteLoginAttempt :one
+DELETE FROM login_attempts WHERE key = $1
+RETURNING key, failures, last_failed_at
+`
+
+func (q *Queries) DeleteLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, deleteLoginAttempt, key)
+	var i LoginAttempt
+	err := row.Scan(&i.Key, &i.Failures, &i.LastFailedAt)
+	return i, err
+}
+
+const deleteStaleLoginAttempts = `-- name: DeleteStaleLoginAttempts :exec
+DELETE FROM login_attempts WHERE last_failed_at < $1
+`
+
+func (q *Queries) DeleteStaleLoginAttempts(ctx context.Context, lastFailedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteStaleLoginAttempts, lastFailedAt)
+	return err
+}
+
+const getLoginAttempt = `-- name: GetLoginAttempt :one
+SELECT key, failures, last_failed_at FROM login_attempts WHERE key = $1 LIMIT 1
+`
+
+func (q *Queries) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAttempt, key)
+	var i LoginAttempt
+	err := row.Scan(&i.Key, &i.Failures, &i.LastFailedAt)
+	return i, err
+}
+
+const recordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (
+    key,
+    failures,
+    last_failed_at
+) VALUES ($1, 1, $2)
+ON CONFLICT (key) DO UPDATE SET
+    failures = CASE
+        WHEN login_attempts.last_failed_at < $3 THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failed_at = EXCLUDED.last_failed_at
+RETURNING key, failures, last_failed_at
+`
+
+type RecordLoginFailureParams struct {
+	Key         string    `json:"key"`
+	FailedAt    time.Time `json:"failed_at"`
+	ResetBefore time.Time `json:"reset_before"`
+}
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error) {
+	row := q.db.QueryRowContext(ctx, recordLoginFailure, arg.Key, arg.FailedAt, arg.ResetBefore)
+	var i LoginAttempt
+	err := row.Scan(&i.Key, &i.Failures, &i.LastFailedAt)
+	return i, err
+}
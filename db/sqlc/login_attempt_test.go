@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginFailure(t *testing.T) {
+	key := "user:" + util.RandomString(12)
+	now := time.Now().Truncate(time.Second)
+
+	for i := 1; i <= 3; i++ {
+		attempt, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+			Key:         key,
+			FailedAt:    now,
+			ResetBefore: now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int32(i), attempt.Failures)
+		require.WithinDuration(t, now, attempt.LastFailedAt, time.Second)
+	}
+
+	// Failures older than ResetBefore no longer count.
+	later := now.Add(2 * time.Hour)
+	attempt, err := testQueries.RecordLoginFailure(context.Background(), RecordLoginFailureParams{
+		Key:         key,
+		FailedAt:    later,
+		ResetBefore: later.Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), attempt.Failures)
+
+	deleted, err := testQueries.DeleteLoginAttempt(context.Background(), key)
+	require.NoError(t, err)
+	require.Equal(t, attempt.Failures, deleted.Failures)
+
+	_, err = testQueries.GetLoginAttempt(context.Background(), key)
+	require.Error(t, err)
+}
@@ -0,0 +1,316 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// MemStore is an in-memory Store for tests. It mirrors what the SQL queries
+// do: the same filters and sums, the unique, check and foreign key constraints
+// (failing with the same *pq.Error codes) and the ON DELETE behaviour of the
+// migrations. Transactions are serialized behind a single lock.
+type MemStore struct {
+	mu   sync.Mutex
+	data *memData
+}
+
+var _ Store = (*MemStore)(nil)
+
+func NewMemStore() *MemStore {
+	return &MemStore{data: newMemData()}
+}
+
+type memData struct {
+	seq                     map[string]int32
+	users                   map[int32]User
+	categories              map[int32]Category
+	accounts                map[int32]Account
+	recurrences             map[int32]Recurrence
+	installmentGroups       map[int32]InstallmentGroup
+	wallets                 map[int32]Wallet
+	transfers               map[int32]Transfer
+	sessions                map[int32]Session
+	passwordResetTokens     map[int32]PasswordResetToken
+	emailVerificationTokens map[int32]EmailVerificationToken
+	loginAttempts           map[string]LoginAttempt
+	userTOTP                map[int32]UserTotp
+	recoveryCodes           map[int32]RecoveryCode
+	apiKeys                 map[int32]ApiKey
+	auditLogs               map[int64]AuditLog
+	ledgers                 map[int32]Ledger
+	ledgerMembers           map[memLedgerMemberKey]LedgerMember
+	ledgerInvitations       map[int32]LedgerInvitation
+}
+
+func newMemData() *memData {
+	return &memData{
+		seq:                     map[string]int32{},
+		users:                   map[int32]User{},
+		categories:              map[int32]Category{},
+		accounts:                map[int32]Account{},
+		recurrences:             map[int32]Recurrence{},
+		installmentGroups:       map[int32]InstallmentGroup{},
+		wallets:                 map[int32]Wallet{},
+		transfers:               map[int32]Transfer{},
+		sessions:                map[int32]Session{},
+		passwordResetTokens:     map[int32]PasswordResetToken{},
+		emailVerificationTokens: map[int32]EmailVerificationToken{},
+		loginAttempts:           map[string]LoginAttempt{},
+		userTOTP:                map[int32]UserTotp{},
+		recoveryCodes:           map[int32]RecoveryCode{},
+		apiKeys:                 map[int32]ApiKey{},
+		auditLogs:               map[int64]AuditLog{},
+		ledgers:                 map[int32]Ledger{},
+		ledgerMembers:           map[memLedgerMemberKey]LedgerMember{},
+		ledgerInvitations:       map[int32]LedgerInvitation{},
+	}
+}
+
+func (d *memData) clone() *memData {
+	c := newMemData()
+	copyMap(c.seq, d.seq)
+	copyMap(c.users, d.users)
+	copyMap(c.categories, d.categories)
+	copyMap(c.accounts, d.accounts)
+	copyMap(c.recurrences, d.recurrences)
+	copyMap(c.installmentGroups, d.installmentGroups)
+	copyMap(c.wallets, d.wallets)
+	copyMap(c.transfers, d.transfers)
+	copyMap(c.sessions, d.sessions)
+	copyMap(c.passwordResetTokens, d.passwordResetTokens)
+	copyMap(c.emailVerificationTokens, d.emailVerificationTokens)
+	copyMap(c.loginAttempts, d.loginAttempts)
+	copyMap(c.userTOTP, d.userTOTP)
+	copyMap(c.recoveryCodes, d.recoveryCodes)
+	copyMap(c.apiKeys, d.apiKeys)
+	copyMap(c.auditLogs, d.auditLogs)
+	copyMap(c.ledgers, d.ledgers)
+	copyMap(c.ledgerMembers, d.ledgerMembers)
+	copyMap(c.ledgerInvitations, d.ledgerInvitations)
+	return c
+}
+
+func (d *memData) nextID(table string) int32 {
+	d.seq[table]++
+	return d.seq[table]
+}
+
+// ExecTx runs fn against a private copy of the data and publishes the copy
+// only when fn succeeds. The options are accepted for interface compatibility;
+// holding the lock for the whole call already makes it serializable.
+func (s *MemStore) ExecTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &MemStore{data: s.data.clone()}
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	s.data = tx.data
+	return nil
+}
+
+func (s *MemStore) CreateInstallmentsTx(ctx context.Context, arg CreateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	return createInstallmentsTx(ctx, s, arg)
+}
+
+func (s *MemStore) UpdateInstallmentsTx(ctx context.Context, arg UpdateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	return updateInstallmentsTx(ctx, s, arg)
+}
+
+func (s *MemStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return transferTx(ctx, s, arg)
+}
+
+func (s *MemStore) GetTransferDetails(ctx context.Context, arg GetTransferParams) (TransferTxResult, error) {
+	return getTransferDetails(ctx, s, arg)
+}
+
+func (s *MemStore) UpdateTransferTx(ctx context.Context, arg UpdateTransferTxParams) (TransferTxResult, error) {
+	return updateTransferTx(ctx, s, arg)
+}
+
+func (s *MemStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	return rotateSessionTx(ctx, s, arg)
+}
+
+func (s *MemStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	return resetPasswordTx(ctx, s, arg)
+}
+
+func (s *MemStore) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	return verifyEmailTx(ctx, s, tokenHash)
+}
+
+func (s *MemStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	return changePasswordTx(ctx, s, arg)
+}
+
+func (s *MemStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	return changeEmailTx(ctx, s, arg)
+}
+
+func (s *MemStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	return createUserTx(ctx, s, arg)
+}
+
+func (s *MemStore) DeleteUserTx(ctx context.Context, userID int32) error {
+	return deleteUserTx(ctx, s, userID)
+}
+
+func (s *MemStore) DisableUserTx(ctx context.Context, userID int32) (User, error) {
+	return disableUserTx(ctx, s, userID)
+}
+
+func (s *MemStore) ForcePasswordResetTx(ctx context.Context, arg ForcePasswordResetTxParams) (User, error) {
+	return forcePasswordResetTx(ctx, s, arg)
+}
+
+func (s *MemStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (UserTotp, error) {
+	return confirmTOTPTx(ctx, s, arg)
+}
+
+func (s *MemStore) ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error {
+	return replaceRecoveryCodesTx(ctx, s, arg)
+}
+
+func (s *MemStore) DisableTOTPTx(ctx context.Context, userID int32) error {
+	return disableTOTPTx(ctx, s, userID)
+}
+
+func (s *MemStore) CreateLedgerTx(ctx context.Context, arg CreateLedgerTxParams) (Ledger, error) {
+	return createLedgerTx(ctx, s, arg)
+}
+
+func (s *MemStore) DeleteLedgerTx(ctx context.Context, ledgerID int32) error {
+	return deleteLedgerTx(ctx, s, ledgerID)
+}
+
+func (s *MemStore) AcceptLedgerInvitationTx(ctx context.Context, arg AcceptLedgerInvitationTxParams) (LedgerMember, error) {
+	return acceptLedgerInvitationTx(ctx, s, arg)
+}
+
+func copyMap[K comparable, V any](dst, src map[K]V) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// sortedValues returns the values of m ordered by key, which matches the
+// insertion order of serial ids.
+func sortedValues[K int32 | int64, V any](m map[K]V) []V {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	values := make([]V, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+// memPage mirrors LIMIT limit OFFSET offset.
+func memPage[V any](values []V, limit, offset int32) []V {
+	if int(offset) >= len(values) {
+		return values[:0]
+	}
+	values = values[offset:]
+	if int(limit) < len(values) {
+		values = values[:limit]
+	}
+	return values
+}
+
+// memDate truncates t the way Postgres does when storing it in a date column.
+func memDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// memDateTrunc mirrors date_trunc(unit, t) for the units the reports use.
+// Weeks start on Monday, like in Postgres.
+func memDateTrunc(unit string, t time.Time) (time.Time, error) {
+	t = memDate(t)
+	switch unit {
+	case "day":
+		return t, nil
+	case "week":
+		return t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7)), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return t, &pq.Error{
+		Code:    "22023",
+		Message: fmt.Sprintf("unit %q not recognized for type timestamp without time zone", unit),
+	}
+}
+
+// memAddUnit adds one unit of the reports to t.
+func memAddUnit(unit string, t time.Time) time.Time {
+	switch unit {
+	case "week":
+		return t.AddDate(0, 0, 7)
+	case "month":
+		return t.AddDate(0, 1, 0)
+	case "year":
+		return t.AddDate(1, 0, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// memRound2 mirrors ROUND(x, 2), which rounds halves away from zero.
+func memRound2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
+
+// memContains mirrors UPPER(column) LIKE CONCAT('%', UPPER(pattern), '%').
+func memContains(value, pattern string) bool {
+	return strings.Contains(strings.ToUpper(value), strings.ToUpper(pattern))
+}
+
+func memUniqueViolation(constraint string) error {
+	return &pq.Error{
+		Code:       "23505",
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		Constraint: constraint,
+	}
+}
+
+func memForeignKeyViolation(table, constraint string) error {
+	return &pq.Error{
+		Code:       "23503",
+		Message:    fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func memNotNullViolation(table, column string) error {
+	return &pq.Error{
+		Code:    "23502",
+		Message: fmt.Sprintf("null value in column %q of relation %q violates not-null constraint", column, table),
+		Table:   table,
+		Column:  column,
+	}
+}
+
+func memCheckViolation(table string) error {
+	return &pq.Error{
+		Code:    "23514",
+		Message: fmt.Sprintf("new row for relation %q violates check constraint", table),
+		Table:   table,
+	}
+}
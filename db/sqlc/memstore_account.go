@@ -0,0 +1,296 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// insertAccount applies the constraints of the accounts table and stores acc
+// with a fresh id.
+func (d *memData) insertAccount(acc Account) (Account, error) {
+	if _, ok := d.ledgers[acc.LedgerID]; !ok {
+		return Account{}, memForeignKeyViolation("accounts", "accounts_ledger_id_fkey")
+	}
+	if acc.CategoryID.Valid {
+		if _, ok := d.categories[acc.CategoryID.Int32]; !ok {
+			return Account{}, memForeignKeyViolation("accounts", "accounts_category_id_fkey")
+		}
+	}
+	if acc.RecurrenceID.Valid {
+		if _, ok := d.recurrences[acc.RecurrenceID.Int32]; !ok {
+			return Account{}, memForeignKeyViolation("accounts", "accounts_recurrence_id_fkey")
+		}
+	}
+	if acc.InstallmentGroupID.Valid {
+		if _, ok := d.installmentGroups[acc.InstallmentGroupID.Int32]; !ok {
+			return Account{}, memForeignKeyViolation("accounts", "accounts_installment_group_id_fkey")
+		}
+	}
+	if acc.WalletID.Valid {
+		if _, ok := d.wallets[acc.WalletID.Int32]; !ok {
+			return Account{}, memForeignKeyViolation("accounts", "accounts_wallet_id_fkey")
+		}
+	}
+	if acc.TransferID.Valid {
+		if _, ok := d.transfers[acc.TransferID.Int32]; !ok {
+			return Account{}, memForeignKeyViolation("accounts", "accounts_transfer_id_fkey")
+		}
+	}
+
+	acc.Date = memDate(acc.Date)
+	for _, other := range d.accounts {
+		if acc.RecurrenceID.Valid && other.RecurrenceID == acc.RecurrenceID && other.Date.Equal(acc.Date) {
+			return Account{}, memUniqueViolation("accounts_recurrence_id_date_idx")
+		}
+		if acc.InstallmentGroupID.Valid && other.InstallmentGroupID == acc.InstallmentGroupID && other.InstallmentNumber == acc.InstallmentNumber {
+			return Account{}, memUniqueViolation("accounts_installment_group_id_installment_number_idx")
+		}
+	}
+
+	acc.ID = d.nextID("accounts")
+	acc.CreatedAt = time.Now()
+	d.accounts[acc.ID] = acc
+	return acc, nil
+}
+
+func (s *MemStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.insertAccount(Account{
+		LedgerID:    arg.LedgerID,
+		CategoryID:  arg.CategoryID,
+		Title:       arg.Title,
+		Type:        arg.Type,
+		Description: arg.Description,
+		Date:        arg.Date,
+		Value:       arg.Value,
+		WalletID:    arg.WalletID,
+	})
+}
+
+func (s *MemStore) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, ok := s.data.accounts[arg.ID]
+	if !ok || acc.LedgerID != arg.LedgerID {
+		return Account{}, sql.ErrNoRows
+	}
+	return acc, nil
+}
+
+func (s *MemStore) GetAccounts(ctx context.Context, arg GetAccountsParams) ([]GetAccountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []GetAccountsRow{}
+	for _, acc := range sortedValues(s.data.accounts) {
+		if acc.LedgerID != arg.LedgerID || acc.Type != arg.Type {
+			continue
+		}
+		if arg.CategoryID.Valid && acc.CategoryID != arg.CategoryID {
+			continue
+		}
+		if !memContains(acc.Title, arg.Title) || !memContains(acc.Description, arg.Description) {
+			continue
+		}
+		if arg.Date.Valid && !acc.Date.Equal(memDate(arg.Date.Time)) {
+			continue
+		}
+
+		row := GetAccountsRow{
+			ID:          acc.ID,
+			LedgerID:    acc.LedgerID,
+			Title:       acc.Title,
+			Type:        acc.Type,
+			Description: acc.Description,
+			Value:       acc.Value,
+			Date:        acc.Date,
+			CreatedAt:   acc.CreatedAt,
+			WalletID:    acc.WalletID,
+		}
+		if cat, ok := s.data.categories[acc.CategoryID.Int32]; ok && acc.CategoryID.Valid {
+			row.CategoryTitle = sql.NullString{String: cat.Title, Valid: true}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (s *MemStore) GetAccountsReports(ctx context.Context, arg GetAccountsReportsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sum int64
+	for _, acc := range s.data.accounts {
+		if acc.LedgerID == arg.LedgerID && acc.Type == arg.Type && !acc.TransferID.Valid {
+			sum += int64(acc.Value)
+		}
+	}
+	return sum, nil
+}
+
+// periodTotals sums income and expense of the accounts of a ledger that keep
+// accepts per period of unit between start and end, mirroring the series of
+// the period reports. Transfer legs are left out.
+func (d *memData) periodTotals(ledgerID int32, unit string, start, end time.Time, keep func(Account) bool) ([]GetAccountsSummaryRow, error) {
+	first, err := memDateTrunc(unit, start)
+	if err != nil {
+		return nil, err
+	}
+	last, _ := memDateTrunc(unit, end)
+
+	rows := []GetAccountsSummaryRow{}
+	periods := map[time.Time]int{}
+	for period := first; !period.After(last); period = memAddUnit(unit, period) {
+		periods[period] = len(rows)
+		rows = append(rows, GetAccountsSummaryRow{Period: period})
+	}
+
+	start, end = memDate(start), memDate(end)
+	for _, acc := range d.accounts {
+		if acc.LedgerID != ledgerID || acc.TransferID.Valid || acc.Date.Before(start) || acc.Date.After(end) || !keep(acc) {
+			continue
+		}
+		period, _ := memDateTrunc(unit, acc.Date)
+		row := &rows[periods[period]]
+		switch acc.Type {
+		case "credit":
+			row.Income += int64(acc.Value)
+		case "debit":
+			row.Expense += int64(acc.Value)
+		}
+	}
+	for i := range rows {
+		rows[i].Net = rows[i].Income - rows[i].Expense
+	}
+	return rows, nil
+}
+
+func (s *MemStore) GetAccountsSummary(ctx context.Context, arg GetAccountsSummaryParams) ([]GetAccountsSummaryRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.periodTotals(arg.LedgerID, arg.Granularity, arg.StartDate, arg.EndDate, func(Account) bool { return true })
+}
+
+func (s *MemStore) GetAccountGraph(ctx context.Context, arg GetAccountGraphParams) ([]GetAccountGraphRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totals, err := s.data.periodTotals(arg.LedgerID, arg.Granularity, arg.StartDate, arg.EndDate, func(acc Account) bool {
+		return (!arg.CategoryID.Valid || acc.CategoryID == arg.CategoryID) &&
+			(!arg.WalletID.Valid || acc.WalletID == arg.WalletID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]GetAccountGraphRow, len(totals))
+	var income, expense int64
+	for i, total := range totals {
+		income += total.Income
+		expense += total.Expense
+		rows[i] = GetAccountGraphRow{
+			Period:            total.Period,
+			Income:            total.Income,
+			Expense:           total.Expense,
+			Balance:           total.Net,
+			CumulativeIncome:  income,
+			CumulativeExpense: expense,
+			CumulativeBalance: income - expense,
+		}
+	}
+	return rows, nil
+}
+
+func (s *MemStore) GetCategoryBreakdown(ctx context.Context, arg GetCategoryBreakdownParams) ([]GetCategoryBreakdownRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []GetCategoryBreakdownRow{}
+	groups := map[sql.NullInt32]int{}
+	var sum int64
+	start, end := memDate(arg.StartDate), memDate(arg.EndDate)
+	for _, acc := range s.data.accounts {
+		if acc.LedgerID != arg.LedgerID || acc.Type != arg.Type || acc.TransferID.Valid ||
+			acc.Date.Before(start) || acc.Date.After(end) {
+			continue
+		}
+		i, ok := groups[acc.CategoryID]
+		if !ok {
+			i = len(rows)
+			groups[acc.CategoryID] = i
+			row := GetCategoryBreakdownRow{CategoryID: acc.CategoryID}
+			if cat, ok := s.data.categories[acc.CategoryID.Int32]; ok && acc.CategoryID.Valid {
+				row.CategoryTitle = sql.NullString{String: cat.Title, Valid: true}
+			}
+			rows = append(rows, row)
+		}
+		rows[i].Total += int64(acc.Value)
+		rows[i].Count++
+		sum += int64(acc.Value)
+	}
+
+	for i := range rows {
+		if sum != 0 {
+			rows[i].Share = memRound2(float64(rows[i].Total) * 100 / float64(sum))
+		}
+		rows[i].Average = memRound2(float64(rows[i].Total) / float64(rows[i].Count))
+	}
+	// NULL titles and categories sort last, like in Postgres.
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.CategoryTitle != b.CategoryTitle {
+			return a.CategoryTitle.Valid && (!b.CategoryTitle.Valid || a.CategoryTitle.String < b.CategoryTitle.String)
+		}
+		return a.CategoryID.Valid && (!b.CategoryID.Valid || a.CategoryID.Int32 < b.CategoryID.Int32)
+	})
+	return rows, nil
+}
+
+func (s *MemStore) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, ok := s.data.accounts[arg.ID]
+	if !ok || acc.LedgerID != arg.LedgerID {
+		return Account{}, sql.ErrNoRows
+	}
+
+	acc.Title = arg.Title
+	acc.Description = arg.Description
+	acc.Value = arg.Value
+	s.data.accounts[acc.ID] = acc
+	return acc, nil
+}
+
+func (s *MemStore) DeleteAccount(ctx context.Context, arg DeleteAccountParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	acc, ok := s.data.accounts[arg.ID]
+	if !ok || acc.LedgerID != arg.LedgerID {
+		return nil
+	}
+	delete(s.data.accounts, acc.ID)
+	return nil
+}
+
+func (s *MemStore) DeleteLedgerAccounts(ctx context.Context, ledgerID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, acc := range s.data.accounts {
+		if acc.LedgerID == ledgerID {
+			delete(s.data.accounts, acc.ID)
+		}
+	}
+	return nil
+}
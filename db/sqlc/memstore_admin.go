@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+func (s *MemStore) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.ActorID.Int32]; arg.ActorID.Valid && !ok {
+		return AuditLog{}, memForeignKeyViolation("audit_logs", "audit_logs_actor_id_fkey")
+	}
+	if _, ok := s.data.users[arg.TargetUserID.Int32]; arg.TargetUserID.Valid && !ok {
+		return AuditLog{}, memForeignKeyViolation("audit_logs", "audit_logs_target_user_id_fkey")
+	}
+
+	entry := AuditLog{
+		ID:           int64(s.data.nextID("audit_logs")),
+		ActorID:      arg.ActorID,
+		Action:       arg.Action,
+		TargetUserID: arg.TargetUserID,
+		Status:       arg.Status,
+		Details:      arg.Details,
+		ClientIp:     arg.ClientIp,
+		CreatedAt:    time.Now(),
+	}
+	s.data.auditLogs[entry.ID] = entry
+	return entry, nil
+}
+
+func (s *MemStore) ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []AuditLog{}
+	for _, entry := range sortedValues(s.data.auditLogs) {
+		if !arg.TargetUserID.Valid || entry.TargetUserID == arg.TargetUserID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].ID > entries[j].ID })
+	return memPage(entries, arg.PageLimit, arg.PageOffset), nil
+}
+
+func (s *MemStore) GetSystemStats(ctx context.Context) (GetSystemStatsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := GetSystemStatsRow{
+		Users:       int64(len(s.data.users)),
+		Categories:  int64(len(s.data.categories)),
+		Accounts:    int64(len(s.data.accounts)),
+		Wallets:     int64(len(s.data.wallets)),
+		Transfers:   int64(len(s.data.transfers)),
+		Recurrences: int64(len(s.data.recurrences)),
+	}
+	for _, user := range s.data.users {
+		if user.EmailVerifiedAt.Valid {
+			stats.VerifiedUsers++
+		}
+		if user.DisabledAt.Valid {
+			stats.DisabledUsers++
+		}
+	}
+	for _, totp := range s.data.userTOTP {
+		if totp.ConfirmedAt.Valid {
+			stats.TwoFactorUsers++
+		}
+	}
+	now := time.Now()
+	for _, session := range s.data.sessions {
+		if !session.RevokedAt.Valid && session.ExpiresAt.After(now) {
+			stats.ActiveSessions++
+		}
+	}
+	return stats, nil
+}
+
+func (s *MemStore) CountUsersByRole(ctx context.Context) ([]CountUsersByRoleRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := map[string]int64{}
+	for _, user := range s.data.users {
+		counts[user.Role]++
+	}
+	rows := []CountUsersByRoleRow{}
+	for role, users := range counts {
+		rows = append(rows, CountUsersByRoleRow{Role: role, Users: users})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Role < rows[j].Role })
+	return rows, nil
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return ApiKey{}, memForeignKeyViolation("api_keys", "api_keys_user_id_fkey")
+	}
+	for _, apiKey := range s.data.apiKeys {
+		if apiKey.KeyHash == arg.KeyHash {
+			return ApiKey{}, memUniqueViolation("api_keys_key_hash_key")
+		}
+	}
+
+	apiKey := ApiKey{
+		ID:        s.data.nextID("api_keys"),
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+		KeyHash:   arg.KeyHash,
+		Prefix:    arg.Prefix,
+		Scopes:    append([]string{}, arg.Scopes...),
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	s.data.apiKeys[apiKey.ID] = apiKey
+	return apiKey, nil
+}
+
+func (s *MemStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, apiKey := range s.data.apiKeys {
+		if apiKey.KeyHash == keyHash {
+			return apiKey, nil
+		}
+	}
+	return ApiKey{}, sql.ErrNoRows
+}
+
+func (s *MemStore) ListAPIKeys(ctx context.Context, userID int32) ([]ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apiKeys := []ApiKey{}
+	for _, apiKey := range sortedValues(s.data.apiKeys) {
+		if apiKey.UserID == userID && !apiKey.RevokedAt.Valid {
+			apiKeys = append(apiKeys, apiKey)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (s *MemStore) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apiKey, ok := s.data.apiKeys[arg.ID]
+	if !ok || apiKey.UserID != arg.UserID || apiKey.RevokedAt.Valid {
+		return ApiKey{}, sql.ErrNoRows
+	}
+	apiKey.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.data.apiKeys[arg.ID] = apiKey
+	return apiKey, nil
+}
+
+func (s *MemStore) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apiKey, ok := s.data.apiKeys[arg.ID]
+	if ok {
+		apiKey.LastUsedAt = arg.LastUsedAt
+		s.data.apiKeys[arg.ID] = apiKey
+	}
+	return nil
+}
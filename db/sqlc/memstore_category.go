@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.ledgers[arg.LedgerID]; !ok {
+		return Category{}, memForeignKeyViolation("categories", "categories_ledger_id_fkey")
+	}
+
+	cat := Category{
+		ID:          s.data.nextID("categories"),
+		Title:       arg.Title,
+		Type:        arg.Type,
+		Description: arg.Description,
+		LedgerID:    arg.LedgerID,
+		CreatedAt:   time.Now(),
+	}
+	s.data.categories[cat.ID] = cat
+	return cat, nil
+}
+
+func (s *MemStore) GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cat, ok := s.data.categories[arg.ID]
+	if !ok || cat.LedgerID != arg.LedgerID {
+		return Category{}, sql.ErrNoRows
+	}
+	return cat, nil
+}
+
+func (s *MemStore) GetCategories(ctx context.Context, arg GetCategoriesParams) ([]Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cats := []Category{}
+	for _, cat := range sortedValues(s.data.categories) {
+		if cat.LedgerID != arg.LedgerID || cat.Type != arg.Type {
+			continue
+		}
+		if !memContains(cat.Title, arg.Title) || !memContains(cat.Description, arg.Description) {
+			continue
+		}
+		cats = append(cats, cat)
+	}
+	return cats, nil
+}
+
+func (s *MemStore) UpdateCategories(ctx context.Context, arg UpdateCategoriesParams) (Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cat, ok := s.data.categories[arg.ID]
+	if !ok || cat.LedgerID != arg.LedgerID {
+		return Category{}, sql.ErrNoRows
+	}
+
+	cat.Title = arg.Title
+	cat.Description = arg.Description
+	s.data.categories[cat.ID] = cat
+	return cat, nil
+}
+
+func (s *MemStore) DeleteCategory(ctx context.Context, arg DeleteCategoryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := arg.ID
+	if cat, ok := s.data.categories[id]; !ok || cat.LedgerID != arg.LedgerID {
+		return nil
+	}
+	for _, acc := range s.data.accounts {
+		if acc.CategoryID.Valid && acc.CategoryID.Int32 == id {
+			return memForeignKeyViolation("accounts", "accounts_category_id_fkey")
+		}
+	}
+	for _, rec := range s.data.recurrences {
+		if rec.CategoryID == id {
+			return memForeignKeyViolation("recurrences", "recurrences_category_id_fkey")
+		}
+	}
+	for _, group := range s.data.installmentGroups {
+		if group.CategoryID == id {
+			return memForeignKeyViolation("installment_groups", "installment_groups_category_id_fkey")
+		}
+	}
+
+	delete(s.data.categories, id)
+	return nil
+}
+
+func (s *MemStore) DeleteLedgerCategories(ctx context.Context, ledgerID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	owned := map[int32]bool{}
+	for _, cat := range s.data.categories {
+		if cat.LedgerID == ledgerID {
+			owned[cat.ID] = true
+		}
+	}
+	for _, acc := range s.data.accounts {
+		if acc.CategoryID.Valid && owned[acc.CategoryID.Int32] {
+			return memForeignKeyViolation("accounts", "accounts_category_id_fkey")
+		}
+	}
+	for _, rec := range s.data.recurrences {
+		if owned[rec.CategoryID] {
+			return memForeignKeyViolation("recurrences", "recurrences_category_id_fkey")
+		}
+	}
+	for _, group := range s.data.installmentGroups {
+		if owned[group.CategoryID] {
+			return memForeignKeyViolation("installment_groups", "installment_groups_category_id_fkey")
+		}
+	}
+
+	for id := range owned {
+		delete(s.data.categories, id)
+	}
+	return nil
+}
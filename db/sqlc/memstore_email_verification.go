@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return EmailVerificationToken{}, memForeignKeyViolation("email_verification_tokens", "email_verification_tokens_user_id_fkey")
+	}
+	for _, verificationToken := range s.data.emailVerificationTokens {
+		if verificationToken.TokenHash == arg.TokenHash {
+			return EmailVerificationToken{}, memUniqueViolation("email_verification_tokens_token_hash_key")
+		}
+	}
+
+	verificationToken := EmailVerificationToken{
+		ID:        s.data.nextID("email_verification_tokens"),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	s.data.emailVerificationTokens[verificationToken.ID] = verificationToken
+	return verificationToken, nil
+}
+
+func (s *MemStore) GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, verificationToken := range s.data.emailVerificationTokens {
+		if verificationToken.TokenHash == tokenHash {
+			return verificationToken, nil
+		}
+	}
+	return EmailVerificationToken{}, sql.ErrNoRows
+}
+
+func (s *MemStore) MarkEmailVerificationTokensUsed(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	for id, verificationToken := range s.data.emailVerificationTokens {
+		if verificationToken.UserID == userID && !verificationToken.UsedAt.Valid {
+			verificationToken.UsedAt = now
+			s.data.emailVerificationTokens[id] = verificationToken
+		}
+	}
+	return nil
+}
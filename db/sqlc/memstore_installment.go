@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (s *MemStore) CreateInstallmentGroup(ctx context.Context, arg CreateInstallmentGroupParams) (InstallmentGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.ledgers[arg.LedgerID]; !ok {
+		return InstallmentGroup{}, memForeignKeyViolation("installment_groups", "installment_groups_ledger_id_fkey")
+	}
+	if _, ok := s.data.categories[arg.CategoryID]; !ok {
+		return InstallmentGroup{}, memForeignKeyViolation("installment_groups", "installment_groups_category_id_fkey")
+	}
+	if arg.InstallmentCount <= 0 {
+		return InstallmentGroup{}, memCheckViolation("installment_groups")
+	}
+
+	group := InstallmentGroup{
+		ID:               s.data.nextID("installment_groups"),
+		LedgerID:         arg.LedgerID,
+		CategoryID:       arg.CategoryID,
+		Title:            arg.Title,
+		Type:             arg.Type,
+		Description:      arg.Description,
+		TotalValue:       arg.TotalValue,
+		InstallmentCount: arg.InstallmentCount,
+		FirstDate:        memDate(arg.FirstDate),
+		CreatedAt:        time.Now(),
+	}
+	s.data.installmentGroups[group.ID] = group
+	return group, nil
+}
+
+func (s *MemStore) GetInstallmentGroup(ctx context.Context, arg GetInstallmentGroupParams) (InstallmentGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.data.installmentGroups[arg.ID]
+	if !ok || group.LedgerID != arg.LedgerID {
+		return InstallmentGroup{}, sql.ErrNoRows
+	}
+	return group, nil
+}
+
+func (s *MemStore) UpdateInstallmentGroup(ctx context.Context, arg UpdateInstallmentGroupParams) (InstallmentGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.data.installmentGroups[arg.ID]
+	if !ok || group.LedgerID != arg.LedgerID {
+		return InstallmentGroup{}, sql.ErrNoRows
+	}
+
+	group.Title = arg.Title
+	group.Description = arg.Description
+	group.TotalValue = arg.TotalValue
+	s.data.installmentGroups[group.ID] = group
+	return group, nil
+}
+
+func (s *MemStore) DeleteInstallmentGroup(ctx context.Context, arg DeleteInstallmentGroupParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, ok := s.data.installmentGroups[arg.ID]
+	if !ok || group.LedgerID != arg.LedgerID {
+		return nil
+	}
+	id := group.ID
+
+	for _, acc := range s.data.accounts {
+		if acc.InstallmentGroupID.Valid && acc.InstallmentGroupID.Int32 == id {
+			delete(s.data.accounts, acc.ID)
+		}
+	}
+	delete(s.data.installmentGroups, id)
+	return nil
+}
+
+func (s *MemStore) CreateInstallmentAccount(ctx context.Context, arg CreateInstallmentAccountParams) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.data.insertAccount(Account{
+		LedgerID:           arg.LedgerID,
+		CategoryID:         arg.CategoryID,
+		InstallmentGroupID: arg.InstallmentGroupID,
+		InstallmentNumber:  arg.InstallmentNumber,
+		Title:              arg.Title,
+		Type:               arg.Type,
+		Description:        arg.Description,
+		Date:               arg.Date,
+		Value:              arg.Value,
+	})
+}
+
+func (s *MemStore) GetInstallmentAccounts(ctx context.Context, installmentGroupID sql.NullInt32) ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accs := []Account{}
+	if !installmentGroupID.Valid {
+		return accs, nil
+	}
+	for _, acc := range sortedValues(s.data.accounts) {
+		if acc.InstallmentGroupID == installmentGroupID {
+			accs = append(accs, acc)
+		}
+	}
+	sort.SliceStable(accs, func(i, j int) bool {
+		return accs[i].InstallmentNumber.Int32 < accs[j].InstallmentNumber.Int32
+	})
+	return accs, nil
+}
+
+func (s *MemStore) DeleteLedgerInstallmentGroups(ctx context.Context, ledgerID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, group := range s.data.installmentGroups {
+		if group.LedgerID != ledgerID {
+			continue
+		}
+		for _, acc := range s.data.accounts {
+			if acc.InstallmentGroupID.Valid && acc.InstallmentGroupID.Int32 == group.ID {
+				delete(s.data.accounts, acc.ID)
+			}
+		}
+		delete(s.data.installmentGroups, group.ID)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// memLedgerMemberKey is the primary key of ledger_members.
+type memLedgerMemberKey struct {
+	ledgerID int32
+	userID   int32
+}
+
+// personalLedger returns the personal ledger of a user.
+func (d *memData) personalLedger(ownerID int32) (Ledger, bool) {
+	for _, ledger := range d.ledgers {
+		if ledger.OwnerID == ownerID && ledger.Personal {
+			return ledger, true
+		}
+	}
+	return Ledger{}, false
+}
+
+func (s *MemStore) CreateLedger(ctx context.Context, arg CreateLedgerParams) (Ledger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.OwnerID]; !ok {
+		return Ledger{}, memForeignKeyViolation("ledgers", "ledgers_owner_id_fkey")
+	}
+	if _, ok := s.data.personalLedger(arg.OwnerID); ok && arg.Personal {
+		return Ledger{}, memUniqueViolation("ledgers_personal_idx")
+	}
+
+	ledger := Ledger{
+		ID:        s.data.nextID("ledgers"),
+		Name:      arg.Name,
+		OwnerID:   arg.OwnerID,
+		Personal:  arg.Personal,
+		CreatedAt: time.Now(),
+	}
+	s.data.ledgers[ledger.ID] = ledger
+	return ledger, nil
+}
+
+func (s *MemStore) GetLedger(ctx context.Context, id int32) (Ledger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger, ok := s.data.ledgers[id]
+	if !ok {
+		return Ledger{}, sql.ErrNoRows
+	}
+	return ledger, nil
+}
+
+func (s *MemStore) GetPersonalLedger(ctx context.Context, ownerID int32) (Ledger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger, ok := s.data.personalLedger(ownerID)
+	if !ok {
+		return Ledger{}, sql.ErrNoRows
+	}
+	return ledger, nil
+}
+
+func (s *MemStore) ListUserLedgers(ctx context.Context, userID int32) ([]ListUserLedgersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []ListUserLedgersRow{}
+	for _, ledger := range sortedValues(s.data.ledgers) {
+		member, ok := s.data.ledgerMembers[memLedgerMemberKey{ledger.ID, userID}]
+		if !ok {
+			continue
+		}
+		rows = append(rows, ListUserLedgersRow{
+			ID:        ledger.ID,
+			Name:      ledger.Name,
+			OwnerID:   ledger.OwnerID,
+			Personal:  ledger.Personal,
+			CreatedAt: ledger.CreatedAt,
+			Role:      member.Role,
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Personal != rows[j].Personal {
+			return rows[i].Personal
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows, nil
+}
+
+func (s *MemStore) UpdateLedger(ctx context.Context, arg UpdateLedgerParams) (Ledger, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger, ok := s.data.ledgers[arg.ID]
+	if !ok {
+		return Ledger{}, sql.ErrNoRows
+	}
+	ledger.Name = arg.Name
+	s.data.ledgers[ledger.ID] = ledger
+	return ledger, nil
+}
+
+// DeleteLedger removes a ledger. Like the foreign keys, it fails while finance
+// data is still in the ledger and cascades to members and invitations.
+func (s *MemStore) DeleteLedger(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, cat := range s.data.categories {
+		if cat.LedgerID == id {
+			return memForeignKeyViolation("categories", "categories_ledger_id_fkey")
+		}
+	}
+	for _, acc := range s.data.accounts {
+		if acc.LedgerID == id {
+			return memForeignKeyViolation("accounts", "accounts_ledger_id_fkey")
+		}
+	}
+	for _, rec := range s.data.recurrences {
+		if rec.LedgerID == id {
+			return memForeignKeyViolation("recurrences", "recurrences_ledger_id_fkey")
+		}
+	}
+	for _, group := range s.data.installmentGroups {
+		if group.LedgerID == id {
+			return memForeignKeyViolation("installment_groups", "installment_groups_ledger_id_fkey")
+		}
+	}
+
+	for key := range s.data.ledgerMembers {
+		if key.ledgerID == id {
+			delete(s.data.ledgerMembers, key)
+		}
+	}
+	for _, invitation := range s.data.ledgerInvitations {
+		if invitation.LedgerID == id {
+			delete(s.data.ledgerInvitations, invitation.ID)
+		}
+	}
+	delete(s.data.ledgers, id)
+	return nil
+}
+
+func (s *MemStore) AddLedgerMember(ctx context.Context, arg AddLedgerMemberParams) (LedgerMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.ledgers[arg.LedgerID]; !ok {
+		return LedgerMember{}, memForeignKeyViolation("ledger_members", "ledger_members_ledger_id_fkey")
+	}
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return LedgerMember{}, memForeignKeyViolation("ledger_members", "ledger_members_user_id_fkey")
+	}
+	if !validLedgerRole(arg.Role) {
+		return LedgerMember{}, memCheckViolation("ledger_members")
+	}
+	key := memLedgerMemberKey{arg.LedgerID, arg.UserID}
+	if _, ok := s.data.ledgerMembers[key]; ok {
+		return LedgerMember{}, memUniqueViolation("ledger_members_pkey")
+	}
+
+	member := LedgerMember{
+		LedgerID:  arg.LedgerID,
+		UserID:    arg.UserID,
+		Role:      arg.Role,
+		CreatedAt: time.Now(),
+	}
+	s.data.ledgerMembers[key] = member
+	return member, nil
+}
+
+func (s *MemStore) GetLedgerMember(ctx context.Context, arg GetLedgerMemberParams) (LedgerMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.data.ledgerMembers[memLedgerMemberKey{arg.LedgerID, arg.UserID}]
+	if !ok {
+		return LedgerMember{}, sql.ErrNoRows
+	}
+	return member, nil
+}
+
+func (s *MemStore) ListLedgerMembers(ctx context.Context, ledgerID int32) ([]ListLedgerMembersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := []ListLedgerMembersRow{}
+	for key, member := range s.data.ledgerMembers {
+		if key.ledgerID != ledgerID {
+			continue
+		}
+		rows = append(rows, ListLedgerMembersRow{
+			UserID:    member.UserID,
+			Username:  s.data.users[member.UserID].Username,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].CreatedAt.Equal(rows[j].CreatedAt) {
+			return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+		}
+		return rows[i].UserID < rows[j].UserID
+	})
+	return rows, nil
+}
+
+func (s *MemStore) UpdateLedgerMemberRole(ctx context.Context, arg UpdateLedgerMemberRoleParams) (LedgerMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memLedgerMemberKey{arg.LedgerID, arg.UserID}
+	member, ok := s.data.ledgerMembers[key]
+	if !ok {
+		return LedgerMember{}, sql.ErrNoRows
+	}
+	if !validLedgerRole(arg.Role) {
+		return LedgerMember{}, memCheckViolation("ledger_members")
+	}
+	member.Role = arg.Role
+	s.data.ledgerMembers[key] = member
+	return member, nil
+}
+
+func (s *MemStore) RemoveLedgerMember(ctx context.Context, arg RemoveLedgerMemberParams) (LedgerMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memLedgerMemberKey{arg.LedgerID, arg.UserID}
+	member, ok := s.data.ledgerMembers[key]
+	if !ok {
+		return LedgerMember{}, sql.ErrNoRows
+	}
+	delete(s.data.ledgerMembers, key)
+	return member, nil
+}
+
+func (s *MemStore) CreateLedgerInvitation(ctx context.Context, arg CreateLedgerInvitationParams) (LedgerInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.ledgers[arg.LedgerID]; !ok {
+		return LedgerInvitation{}, memForeignKeyViolation("ledger_invitations", "ledger_invitations_ledger_id_fkey")
+	}
+	if arg.InvitedBy.Valid {
+		if _, ok := s.data.users[arg.InvitedBy.Int32]; !ok {
+			return LedgerInvitation{}, memForeignKeyViolation("ledger_invitations", "ledger_invitations_invited_by_fkey")
+		}
+	}
+	if arg.Role != LedgerRoleEditor && arg.Role != LedgerRoleViewer {
+		return LedgerInvitation{}, memCheckViolation("ledger_invitations")
+	}
+	for _, other := range s.data.ledgerInvitations {
+		if other.TokenHash == arg.TokenHash {
+			return LedgerInvitation{}, memUniqueViolation("ledger_invitations_token_hash_key")
+		}
+	}
+
+	invitation := LedgerInvitation{
+		ID:        s.data.nextID("ledger_invitations"),
+		LedgerID:  arg.LedgerID,
+		Email:     arg.Email,
+		Role:      arg.Role,
+		TokenHash: arg.TokenHash,
+		InvitedBy: arg.InvitedBy,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	s.data.ledgerInvitations[invitation.ID] = invitation
+	return invitation, nil
+}
+
+func (s *MemStore) GetLedgerInvitationByHash(ctx context.Context, tokenHash string) (LedgerInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, invitation := range s.data.ledgerInvitations {
+		if invitation.TokenHash == tokenHash {
+			return invitation, nil
+		}
+	}
+	return LedgerInvitation{}, sql.ErrNoRows
+}
+
+func (s *MemStore) ListLedgerInvitations(ctx context.Context, ledgerID int32) ([]LedgerInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	invitations := []LedgerInvitation{}
+	for _, invitation := range sortedValues(s.data.ledgerInvitations) {
+		if invitation.LedgerID == ledgerID && !invitation.AcceptedAt.Valid && invitation.ExpiresAt.After(now) {
+			invitations = append(invitations, invitation)
+		}
+	}
+	return invitations, nil
+}
+
+func (s *MemStore) MarkLedgerInvitationAccepted(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invitation, ok := s.data.ledgerInvitations[id]
+	if !ok {
+		return nil
+	}
+	invitation.AcceptedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.data.ledgerInvitations[id] = invitation
+	return nil
+}
+
+func (s *MemStore) DeleteLedgerInvitation(ctx context.Context, arg DeleteLedgerInvitationParams) (LedgerInvitation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invitation, ok := s.data.ledgerInvitations[arg.ID]
+	if !ok || invitation.LedgerID != arg.LedgerID {
+		return LedgerInvitation{}, sql.ErrNoRows
+	}
+	delete(s.data.ledgerInvitations, invitation.ID)
+	return invitation, nil
+}
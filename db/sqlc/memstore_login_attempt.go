@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.data.loginAttempts[key]
+	if !ok {
+		return LoginAttempt{}, sql.ErrNoRows
+	}
+	return attempt, nil
+}
+
+func (s *MemStore) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.data.loginAttempts[arg.Key]
+	if !ok || attempt.LastFailedAt.Before(arg.ResetBefore) {
+		attempt = LoginAttempt{Key: arg.Key}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = arg.FailedAt
+	s.data.loginAttempts[arg.Key] = attempt
+	return attempt, nil
+}
+
+func (s *MemStore) DeleteLoginAttempt(ctx context.Context, key string) (LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.data.loginAttempts[key]
+	if !ok {
+		return LoginAttempt{}, sql.ErrNoRows
+	}
+	delete(s.data.loginAttempts, key)
+	return attempt, nil
+}
+
+func (s *MemStore) DeleteStaleLoginAttempts(ctx context.Context, lastFailedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, attempt := range s.data.loginAttempts {
+		if attempt.LastFailedAt.Before(lastFailedAt) {
+			delete(s.data.loginAttempts, key)
+		}
+	}
+	return nil
+}
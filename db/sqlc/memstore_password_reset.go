@@ -0,0 +1,57 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return PasswordResetToken{}, memForeignKeyViolation("password_reset_tokens", "password_reset_tokens_user_id_fkey")
+	}
+	for _, resetToken := range s.data.passwordResetTokens {
+		if resetToken.TokenHash == arg.TokenHash {
+			return PasswordResetToken{}, memUniqueViolation("password_reset_tokens_token_hash_key")
+		}
+	}
+
+	resetToken := PasswordResetToken{
+		ID:        s.data.nextID("password_reset_tokens"),
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	s.data.passwordResetTokens[resetToken.ID] = resetToken
+	return resetToken, nil
+}
+
+func (s *MemStore) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, resetToken := range s.data.passwordResetTokens {
+		if resetToken.TokenHash == tokenHash {
+			return resetToken, nil
+		}
+	}
+	return PasswordResetToken{}, sql.ErrNoRows
+}
+
+func (s *MemStore) MarkPasswordResetTokensUsed(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	for id, resetToken := range s.data.passwordResetTokens {
+		if resetToken.UserID == userID && !resetToken.UsedAt.Valid {
+			resetToken.UsedAt = now
+			s.data.passwordResetTokens[id] = resetToken
+		}
+	}
+	return nil
+}
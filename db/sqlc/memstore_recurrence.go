@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *MemStore) CreateRecurrence(ctx context.Context, arg CreateRecurrenceParams) (Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.ledgers[arg.LedgerID]; !ok {
+		return Recurrence{}, memForeignKeyViolation("recurrences", "recurrences_ledger_id_fkey")
+	}
+	if _, ok := s.data.categories[arg.CategoryID]; !ok {
+		return Recurrence{}, memForeignKeyViolation("recurrences", "recurrences_category_id_fkey")
+	}
+	switch arg.Frequency {
+	case "daily", "weekly", "monthly", "yearly":
+	default:
+		return Recurrence{}, memCheckViolation("recurrences")
+	}
+	if arg.Interval <= 0 {
+		return Recurrence{}, memCheckViolation("recurrences")
+	}
+
+	rec := Recurrence{
+		ID:          s.data.nextID("recurrences"),
+		LedgerID:    arg.LedgerID,
+		CategoryID:  arg.CategoryID,
+		Title:       arg.Title,
+		Type:        arg.Type,
+		Description: arg.Description,
+		Value:       arg.Value,
+		Frequency:   arg.Frequency,
+		Interval:    arg.Interval,
+		StartDate:   memDate(arg.StartDate),
+		EndDate:     memNullDate(arg.EndDate),
+		Occurrences: arg.Occurrences,
+		CreatedAt:   time.Now(),
+	}
+	s.data.recurrences[rec.ID] = rec
+	return rec, nil
+}
+
+func (s *MemStore) GetRecurrence(ctx context.Context, arg GetRecurrenceParams) (Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.data.recurrences[arg.ID]
+	if !ok || rec.LedgerID != arg.LedgerID {
+		return Recurrence{}, sql.ErrNoRows
+	}
+	return rec, nil
+}
+
+func (s *MemStore) GetRecurrences(ctx context.Context, ledgerID int32) ([]Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recs := []Recurrence{}
+	for _, rec := range sortedValues(s.data.recurrences) {
+		if rec.LedgerID == ledgerID {
+			recs = append(recs, rec)
+		}
+	}
+	sort.SliceStable(recs, func(i, j int) bool {
+		return recs[i].StartDate.Before(recs[j].StartDate)
+	})
+	return recs, nil
+}
+
+func (s *MemStore) GetPendingRecurrences(ctx context.Context, until time.Time) ([]Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until = memDate(until)
+	recs := []Recurrence{}
+	for _, rec := range sortedValues(s.data.recurrences) {
+		if rec.StartDate.After(until) {
+			continue
+		}
+		if rec.MaterializedUntil.Valid && !rec.MaterializedUntil.Time.Before(until) {
+			continue
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func (s *MemStore) UpdateRecurrence(ctx context.Context, arg UpdateRecurrenceParams) (Recurrence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.data.recurrences[arg.ID]
+	if !ok || rec.LedgerID != arg.LedgerID {
+		return Recurrence{}, sql.ErrNoRows
+	}
+
+	rec.Title = arg.Title
+	rec.Description = arg.Description
+	rec.Value = arg.Value
+	rec.EndDate = memNullDate(arg.EndDate)
+	rec.Occurrences = arg.Occurrences
+	s.data.recurrences[rec.ID] = rec
+	return rec, nil
+}
+
+func (s *MemStore) SetRecurrenceMaterializedUntil(ctx context.Context, arg SetRecurrenceMaterializedUntilParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.data.recurrences[arg.ID]
+	if !ok {
+		return nil
+	}
+
+	rec.MaterializedUntil = memNullDate(arg.MaterializedUntil)
+	s.data.recurrences[rec.ID] = rec
+	return nil
+}
+
+func (s *MemStore) DeleteRecurrence(ctx context.Context, arg DeleteRecurrenceParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec, ok := s.data.recurrences[arg.ID]
+	if !ok || rec.LedgerID != arg.LedgerID {
+		return nil
+	}
+	id := rec.ID
+
+	for _, acc := range s.data.accounts {
+		if acc.RecurrenceID.Valid && acc.RecurrenceID.Int32 == id {
+			acc.RecurrenceID = sql.NullInt32{}
+			s.data.accounts[acc.ID] = acc
+		}
+	}
+	delete(s.data.recurrences, id)
+	return nil
+}
+
+func (s *MemStore) CreateRecurrenceAccount(ctx context.Context, arg CreateRecurrenceAccountParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.data.insertAccount(Account{
+		LedgerID:     arg.LedgerID,
+		CategoryID:   arg.CategoryID,
+		RecurrenceID: arg.RecurrenceID,
+		Title:        arg.Title,
+		Type:         arg.Type,
+		Description:  arg.Description,
+		Date:         arg.Date,
+		Value:        arg.Value,
+	})
+	if err != nil {
+		// ON CONFLICT (recurrence_id, date) DO NOTHING
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Constraint == "accounts_recurrence_id_date_idx" {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return 1, nil
+}
+
+func memNullDate(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: memDate(t.Time), Valid: true}
+}
+
+func (s *MemStore) DeleteLedgerRecurrences(ctx context.Context, ledgerID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rec := range s.data.recurrences {
+		if rec.LedgerID != ledgerID {
+			continue
+		}
+		for _, acc := range s.data.accounts {
+			if acc.RecurrenceID.Valid && acc.RecurrenceID.Int32 == rec.ID {
+				acc.RecurrenceID = sql.NullInt32{}
+				s.data.accounts[acc.ID] = acc
+			}
+		}
+		delete(s.data.recurrences, rec.ID)
+	}
+	return nil
+}
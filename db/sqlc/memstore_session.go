@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (s *MemStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return Session{}, memForeignKeyViolation("sessions", "sessions_user_id_fkey")
+	}
+	for _, session := range s.data.sessions {
+		if session.TokenHash == arg.TokenHash {
+			return Session{}, memUniqueViolation("sessions_token_hash_key")
+		}
+	}
+
+	now := time.Now()
+	session := Session{
+		ID:         s.data.nextID("sessions"),
+		UserID:     arg.UserID,
+		FamilyID:   arg.FamilyID,
+		TokenHash:  arg.TokenHash,
+		ExpiresAt:  arg.ExpiresAt,
+		CreatedAt:  now,
+		UserAgent:  arg.UserAgent,
+		ClientIp:   arg.ClientIp,
+		LastUsedAt: now,
+		StartedAt:  arg.StartedAt,
+	}
+	s.data.sessions[session.ID] = session
+	return session, nil
+}
+
+func (s *MemStore) GetSession(ctx context.Context, arg GetSessionParams) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.data.sessions[arg.ID]
+	if !ok || session.UserID != arg.UserID {
+		return Session{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (s *MemStore) GetSessionByTokenHash(ctx context.Context, tokenHash string) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.data.sessions {
+		if session.TokenHash == tokenHash {
+			return session, nil
+		}
+	}
+	return Session{}, sql.ErrNoRows
+}
+
+func (s *MemStore) ListActiveSessions(ctx context.Context, userID int32) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	sessions := []Session{}
+	for _, session := range sortedValues(s.data.sessions) {
+		if session.UserID == userID && !session.RotatedAt.Valid && !session.RevokedAt.Valid &&
+			session.ExpiresAt.After(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if !sessions[i].LastUsedAt.Equal(sessions[j].LastUsedAt) {
+			return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+		}
+		return sessions[i].ID > sessions[j].ID
+	})
+	return sessions, nil
+}
+
+func (s *MemStore) MarkSessionRotated(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.data.sessions[id]
+	if !ok {
+		return nil
+	}
+	session.RotatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	s.data.sessions[id] = session
+	return nil
+}
+
+func (s *MemStore) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.revokeSessions(func(session Session) bool { return session.FamilyID == familyID })
+	return nil
+}
+
+func (s *MemStore) RevokeUserSessions(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data.revokeSessions(func(session Session) bool { return session.UserID == userID })
+	return nil
+}
+
+func (d *memData) revokeSessions(match func(Session) bool) {
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+	for id, session := range d.sessions {
+		if match(session) && !session.RevokedAt.Valid {
+			session.RevokedAt = now
+			d.sessions[id] = session
+		}
+	}
+}
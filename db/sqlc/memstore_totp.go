@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (s *MemStore) CreateTOTP(ctx context.Context, arg CreateTOTPParams) (UserTotp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return UserTotp{}, memForeignKeyViolation("user_totp", "user_totp_user_id_fkey")
+	}
+	if totp, ok := s.data.userTOTP[arg.UserID]; ok && totp.ConfirmedAt.Valid {
+		return UserTotp{}, sql.ErrNoRows
+	}
+
+	totp := UserTotp{
+		UserID:    arg.UserID,
+		Secret:    arg.Secret,
+		CreatedAt: time.Now(),
+	}
+	s.data.userTOTP[arg.UserID] = totp
+	return totp, nil
+}
+
+func (s *MemStore) GetTOTP(ctx context.Context, userID int32) (UserTotp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totp, ok := s.data.userTOTP[userID]
+	if !ok {
+		return UserTotp{}, sql.ErrNoRows
+	}
+	return totp, nil
+}
+
+func (s *MemStore) ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) (UserTotp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totp, ok := s.data.userTOTP[arg.UserID]
+	if !ok || totp.ConfirmedAt.Valid {
+		return UserTotp{}, sql.ErrNoRows
+	}
+	totp.ConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	totp.LastUsedStep = arg.LastUsedStep
+	s.data.userTOTP[arg.UserID] = totp
+	return totp, nil
+}
+
+func (s *MemStore) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	totp, ok := s.data.userTOTP[arg.UserID]
+	if !ok || totp.LastUsedStep >= arg.LastUsedStep {
+		return UserTotp{}, sql.ErrNoRows
+	}
+	totp.LastUsedStep = arg.LastUsedStep
+	s.data.userTOTP[arg.UserID] = totp
+	return totp, nil
+}
+
+func (s *MemStore) DeleteTOTP(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data.userTOTP, userID)
+	return nil
+}
+
+func (s *MemStore) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return RecoveryCode{}, memForeignKeyViolation("recovery_codes", "recovery_codes_user_id_fkey")
+	}
+	for _, recoveryCode := range s.data.recoveryCodes {
+		if recoveryCode.UserID == arg.UserID && recoveryCode.CodeHash == arg.CodeHash {
+			return RecoveryCode{}, memUniqueViolation("recovery_codes_user_id_code_hash_idx")
+		}
+	}
+
+	recoveryCode := RecoveryCode{
+		ID:        s.data.nextID("recovery_codes"),
+		UserID:    arg.UserID,
+		CodeHash:  arg.CodeHash,
+		CreatedAt: time.Now(),
+	}
+	s.data.recoveryCodes[recoveryCode.ID] = recoveryCode
+	return recoveryCode, nil
+}
+
+func (s *MemStore) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, recoveryCode := range s.data.recoveryCodes {
+		if recoveryCode.UserID == arg.UserID && recoveryCode.CodeHash == arg.CodeHash && !recoveryCode.UsedAt.Valid {
+			recoveryCode.UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			s.data.recoveryCodes[id] = recoveryCode
+			return recoveryCode, nil
+		}
+	}
+	return RecoveryCode{}, sql.ErrNoRows
+}
+
+func (s *MemStore) CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, recoveryCode := range s.data.recoveryCodes {
+		if recoveryCode.UserID == userID && !recoveryCode.UsedAt.Valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *MemStore) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, recoveryCode := range s.data.recoveryCodes {
+		if recoveryCode.UserID == userID {
+			delete(s.data.recoveryCodes, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (s *MemStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return Transfer{}, memForeignKeyViolation("transfers", "transfers_user_id_fkey")
+	}
+	if _, ok := s.data.wallets[arg.FromWalletID]; !ok {
+		return Transfer{}, memForeignKeyViolation("transfers", "transfers_from_wallet_id_fkey")
+	}
+	if _, ok := s.data.wallets[arg.ToWalletID]; !ok {
+		return Transfer{}, memForeignKeyViolation("transfers", "transfers_to_wallet_id_fkey")
+	}
+	if arg.FromWalletID == arg.ToWalletID || arg.Value <= 0 {
+		return Transfer{}, memCheckViolation("transfers")
+	}
+
+	transfer := Transfer{
+		ID:           s.data.nextID("transfers"),
+		UserID:       arg.UserID,
+		FromWalletID: arg.FromWalletID,
+		ToWalletID:   arg.ToWalletID,
+		Description:  arg.Description,
+		Value:        arg.Value,
+		Date:         memDate(arg.Date),
+		CreatedAt:    time.Now(),
+	}
+	s.data.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+func (s *MemStore) GetTransfer(ctx context.Context, arg GetTransferParams) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, ok := s.data.transfers[arg.ID]
+	if !ok || transfer.UserID != arg.UserID {
+		return Transfer{}, sql.ErrNoRows
+	}
+	return transfer, nil
+}
+
+func (s *MemStore) GetTransfers(ctx context.Context, userID int32) ([]Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfers := []Transfer{}
+	for _, transfer := range sortedValues(s.data.transfers) {
+		if transfer.UserID == userID {
+			transfers = append(transfers, transfer)
+		}
+	}
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if !transfers[i].Date.Equal(transfers[j].Date) {
+			return transfers[i].Date.After(transfers[j].Date)
+		}
+		return transfers[i].ID > transfers[j].ID
+	})
+	return transfers, nil
+}
+
+func (s *MemStore) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, ok := s.data.transfers[arg.ID]
+	if !ok || transfer.UserID != arg.UserID {
+		return Transfer{}, sql.ErrNoRows
+	}
+	if arg.Value <= 0 {
+		return Transfer{}, memCheckViolation("transfers")
+	}
+
+	transfer.Description = arg.Description
+	transfer.Value = arg.Value
+	transfer.Date = memDate(arg.Date)
+	s.data.transfers[transfer.ID] = transfer
+	return transfer, nil
+}
+
+func (s *MemStore) DeleteTransfer(ctx context.Context, arg DeleteTransferParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	transfer, ok := s.data.transfers[arg.ID]
+	if !ok || transfer.UserID != arg.UserID {
+		return nil
+	}
+	s.data.deleteTransfer(transfer.ID)
+	return nil
+}
+
+// deleteTransfer removes a transfer and, like ON DELETE CASCADE, its accounts.
+func (d *memData) deleteTransfer(id int32) {
+	for _, acc := range d.accounts {
+		if acc.TransferID.Valid && acc.TransferID.Int32 == id {
+			delete(d.accounts, acc.ID)
+		}
+	}
+	delete(d.transfers, id)
+}
+
+func (s *MemStore) CreateTransferAccount(ctx context.Context, arg CreateTransferAccountParams) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger, ok := s.data.personalLedger(arg.UserID)
+	if !ok {
+		return Account{}, memNotNullViolation("accounts", "ledger_id")
+	}
+	return s.data.insertAccount(Account{
+		LedgerID:    ledger.ID,
+		WalletID:    arg.WalletID,
+		TransferID:  arg.TransferID,
+		Title:       arg.Title,
+		Type:        arg.Type,
+		Description: arg.Description,
+		Date:        arg.Date,
+		Value:       arg.Value,
+	})
+}
+
+func (s *MemStore) GetTransferAccounts(ctx context.Context, transferID sql.NullInt32) ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accs := []Account{}
+	if !transferID.Valid {
+		return accs, nil
+	}
+	for _, acc := range sortedValues(s.data.accounts) {
+		if acc.TransferID == transferID {
+			accs = append(accs, acc)
+		}
+	}
+	return accs, nil
+}
+
+func (s *MemStore) UpdateTransferAccounts(ctx context.Context, arg UpdateTransferAccountsParams) ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accs := []Account{}
+	if !arg.TransferID.Valid {
+		return accs, nil
+	}
+	for _, acc := range sortedValues(s.data.accounts) {
+		if acc.TransferID != arg.TransferID {
+			continue
+		}
+		acc.Description = arg.Description
+		acc.Value = arg.Value
+		acc.Date = memDate(arg.Date)
+		s.data.accounts[acc.ID] = acc
+		accs = append(accs, acc)
+	}
+	return accs, nil
+}
+
+func (s *MemStore) DeleteUserTransfers(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, transfer := range s.data.transfers {
+		if transfer.UserID == userID {
+			s.data.deleteTransfer(transfer.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+func (s *MemStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.data.users {
+		if user.Email == arg.Email {
+			return User{}, memUniqueViolation("users_email_key")
+		}
+		if strings.EqualFold(user.Username, arg.Username) {
+			return User{}, memUniqueViolation("users_username_lower_idx")
+		}
+	}
+
+	user := User{
+		ID:        s.data.nextID("users"),
+		Username:  arg.Username,
+		Password:  arg.Password,
+		Email:     arg.Email,
+		CreatedAt: time.Now(),
+		Role:      RoleUser,
+	}
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+func (s *MemStore) GetUser(ctx context.Context, username string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range sortedValues(s.data.users) {
+		if strings.EqualFold(user.Username, username) {
+			return user, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
+
+func (s *MemStore) GetUserById(ctx context.Context, id int32) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (s *MemStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, user := range s.data.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
+
+func (s *MemStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[arg.ID]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	user.Password = arg.Password
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+func (s *MemStore) MarkUserEmailVerified(ctx context.Context, id int32) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	if !user.EmailVerifiedAt.Valid {
+		user.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		s.data.users[user.ID] = user
+	}
+	return user, nil
+}
+
+func (s *MemStore) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[arg.ID]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	for _, other := range s.data.users {
+		if other.ID != user.ID && other.Email == arg.Email {
+			return User{}, memUniqueViolation("users_email_key")
+		}
+	}
+	user.Email = arg.Email
+	user.EmailVerifiedAt = sql.NullTime{}
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+func (s *MemStore) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[arg.ID]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	for _, other := range s.data.users {
+		if other.ID != user.ID && strings.EqualFold(other.Username, arg.Username) {
+			return User{}, memUniqueViolation("users_username_lower_idx")
+		}
+	}
+	user.Username = arg.Username
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+// DeleteUser removes a user. Like the foreign keys, it fails while finance
+// data still references the user and cascades to sessions and tokens.
+func (s *MemStore) DeleteUser(ctx context.Context, id int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ledger := range s.data.ledgers {
+		if ledger.OwnerID == id {
+			return memForeignKeyViolation("ledgers", "ledgers_owner_id_fkey")
+		}
+	}
+	for _, wallet := range s.data.wallets {
+		if wallet.UserID == id {
+			return memForeignKeyViolation("wallets", "wallets_user_id_fkey")
+		}
+	}
+	for _, transfer := range s.data.transfers {
+		if transfer.UserID == id {
+			return memForeignKeyViolation("transfers", "transfers_user_id_fkey")
+		}
+	}
+
+	for _, session := range s.data.sessions {
+		if session.UserID == id {
+			delete(s.data.sessions, session.ID)
+		}
+	}
+	for _, resetToken := range s.data.passwordResetTokens {
+		if resetToken.UserID == id {
+			delete(s.data.passwordResetTokens, resetToken.ID)
+		}
+	}
+	for _, verificationToken := range s.data.emailVerificationTokens {
+		if verificationToken.UserID == id {
+			delete(s.data.emailVerificationTokens, verificationToken.ID)
+		}
+	}
+	delete(s.data.userTOTP, id)
+	for _, recoveryCode := range s.data.recoveryCodes {
+		if recoveryCode.UserID == id {
+			delete(s.data.recoveryCodes, recoveryCode.ID)
+		}
+	}
+	for _, apiKey := range s.data.apiKeys {
+		if apiKey.UserID == id {
+			delete(s.data.apiKeys, apiKey.ID)
+		}
+	}
+	for key := range s.data.ledgerMembers {
+		if key.userID == id {
+			delete(s.data.ledgerMembers, key)
+		}
+	}
+	for _, invitation := range s.data.ledgerInvitations {
+		if invitation.InvitedBy.Valid && invitation.InvitedBy.Int32 == id {
+			invitation.InvitedBy = sql.NullInt32{}
+			s.data.ledgerInvitations[invitation.ID] = invitation
+		}
+	}
+	for _, entry := range s.data.auditLogs {
+		if entry.ActorID.Valid && entry.ActorID.Int32 == id {
+			entry.ActorID = sql.NullInt32{}
+		}
+		if entry.TargetUserID.Valid && entry.TargetUserID.Int32 == id {
+			entry.TargetUserID = sql.NullInt32{}
+		}
+		s.data.auditLogs[entry.ID] = entry
+	}
+	delete(s.data.users, id)
+	return nil
+}
+
+func (s *MemStore) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[arg.ID]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	if !validRole(arg.Role) {
+		return User{}, memCheckViolation("users")
+	}
+	user.Role = arg.Role
+	s.data.users[user.ID] = user
+	return user, nil
+}
+
+func (s *MemStore) DisableUser(ctx context.Context, id int32) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	if !user.DisabledAt.Valid {
+		user.DisabledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	s.data.users[id] = user
+	return user, nil
+}
+
+func (s *MemStore) EnableUser(ctx context.Context, id int32) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.data.users[id]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	user.DisabledAt = sql.NullTime{}
+	s.data.users[id] = user
+	return user, nil
+}
+
+func (s *MemStore) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search := strings.ToLower(arg.Search)
+	users := []User{}
+	for _, user := range sortedValues(s.data.users) {
+		if search == "" ||
+			strings.Contains(strings.ToLower(user.Username), search) ||
+			strings.Contains(strings.ToLower(user.Email), search) {
+			users = append(users, user)
+		}
+	}
+	return memPage(users, arg.PageLimit, arg.PageOffset), nil
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+)
+
+func (s *MemStore) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.data.users[arg.UserID]; !ok {
+		return Wallet{}, memForeignKeyViolation("wallets", "wallets_user_id_fkey")
+	}
+
+	wallet := Wallet{
+		ID:             s.data.nextID("wallets"),
+		UserID:         arg.UserID,
+		Name:           arg.Name,
+		Type:           arg.Type,
+		Currency:       arg.Currency,
+		OpeningBalance: arg.OpeningBalance,
+		CreatedAt:      time.Now(),
+	}
+	s.data.wallets[wallet.ID] = wallet
+	return wallet, nil
+}
+
+func (s *MemStore) GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.data.wallets[arg.ID]
+	if !ok || wallet.UserID != arg.UserID {
+		return Wallet{}, sql.ErrNoRows
+	}
+	return wallet, nil
+}
+
+func (s *MemStore) GetWallets(ctx context.Context, userID int32) ([]Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallets := []Wallet{}
+	for _, wallet := range sortedValues(s.data.wallets) {
+		if wallet.UserID == userID {
+			wallets = append(wallets, wallet)
+		}
+	}
+	sort.SliceStable(wallets, func(i, j int) bool {
+		return wallets[i].Name < wallets[j].Name
+	})
+	return wallets, nil
+}
+
+func (s *MemStore) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.data.wallets[arg.ID]
+	if !ok || wallet.UserID != arg.UserID {
+		return Wallet{}, sql.ErrNoRows
+	}
+
+	wallet.Name = arg.Name
+	wallet.Type = arg.Type
+	wallet.Currency = arg.Currency
+	wallet.OpeningBalance = arg.OpeningBalance
+	s.data.wallets[wallet.ID] = wallet
+	return wallet, nil
+}
+
+func (s *MemStore) DeleteWallet(ctx context.Context, arg DeleteWalletParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.data.wallets[arg.ID]
+	if !ok || wallet.UserID != arg.UserID {
+		return nil
+	}
+	id := wallet.ID
+
+	for _, transfer := range s.data.transfers {
+		if transfer.FromWalletID == id || transfer.ToWalletID == id {
+			s.data.deleteTransfer(transfer.ID)
+		}
+	}
+	for _, acc := range s.data.accounts {
+		if acc.WalletID.Valid && acc.WalletID.Int32 == id {
+			acc.WalletID = sql.NullInt32{}
+			s.data.accounts[acc.ID] = acc
+		}
+	}
+	delete(s.data.wallets, id)
+	return nil
+}
+
+func (s *MemStore) GetWalletBalance(ctx context.Context, arg GetWalletBalanceParams) (GetWalletBalanceRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wallet, ok := s.data.wallets[arg.WalletID]
+	if !ok || wallet.UserID != arg.UserID {
+		return GetWalletBalanceRow{}, sql.ErrNoRows
+	}
+
+	row := GetWalletBalanceRow{
+		WalletID:       wallet.ID,
+		OpeningBalance: wallet.OpeningBalance,
+	}
+	date := memDate(arg.Date)
+	for _, acc := range s.data.accounts {
+		if !acc.WalletID.Valid || acc.WalletID.Int32 != wallet.ID || acc.Date.After(date) {
+			continue
+		}
+		switch acc.Type {
+		case "credit":
+			row.Income += int64(acc.Value)
+		case "debit":
+			row.Expense += int64(acc.Value)
+		}
+	}
+	row.Balance = int64(wallet.OpeningBalance) + row.Income - row.Expense
+	return row, nil
+}
+
+func (s *MemStore) DeleteUserWallets(ctx context.Context, userID int32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, wallet := range s.data.wallets {
+		if wallet.UserID != userID {
+			continue
+		}
+		for _, transfer := range s.data.transfers {
+			if transfer.FromWalletID == wallet.ID || transfer.ToWalletID == wallet.ID {
+				s.data.deleteTransfer(transfer.ID)
+			}
+		}
+		for _, acc := range s.data.accounts {
+			if acc.WalletID.Valid && acc.WalletID.Int32 == wallet.ID {
+				acc.WalletID = sql.NullInt32{}
+				s.data.accounts[acc.ID] = acc
+			}
+		}
+		delete(s.data.wallets, wallet.ID)
+	}
+	return nil
+}
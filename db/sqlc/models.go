@@ -5,19 +5,49 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 )
 
 type Account struct {
-	ID          int32     `json:"id"`
-	UserID      int32     `json:"user_id"`
-	CategoryID  int32     `json:"category_id"`
-	Title       string    `json:"title"`
-	Type        string    `json:"type"`
-	Description string    `json:"description"`
-	Value       int32     `json:"value"`
-	Date        time.Time `json:"date"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID                 int32         `json:"id"`
+	CategoryID         sql.NullInt32 `json:"category_id"`
+	Title              string        `json:"title"`
+	Type               string        `json:"type"`
+	Description        string        `json:"description"`
+	Value              int32         `json:"value"`
+	Date               time.Time     `json:"date"`
+	CreatedAt          time.Time     `json:"created_at"`
+	RecurrenceID       sql.NullInt32 `json:"recurrence_id"`
+	InstallmentGroupID sql.NullInt32 `json:"installment_group_id"`
+	InstallmentNumber  sql.NullInt32 `json:"installment_number"`
+	WalletID           sql.NullInt32 `json:"wallet_id"`
+	TransferID         sql.NullInt32 `json:"transfer_id"`
+	LedgerID           int32         `json:"ledger_id"`
+}
+
+type ApiKey struct {
+	ID         int32        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	Name       string       `json:"name"`
+	KeyHash    string       `json:"key_hash"`
+	Prefix     string       `json:"prefix"`
+	Scopes     []string     `json:"scopes"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID           int64         `json:"id"`
+	ActorID      sql.NullInt32 `json:"actor_id"`
+	Action       string        `json:"action"`
+	TargetUserID sql.NullInt32 `json:"target_user_id"`
+	Status       int32         `json:"status"`
+	Details      string        `json:"details"`
+	ClientIp     string        `json:"client_ip"`
+	CreatedAt    time.Time     `json:"created_at"`
 }
 
 type Category struct {
@@ -25,14 +55,150 @@ type Category struct {
 	Title       string    `json:"title"`
 	Type        string    `json:"type"`
 	Description string    `json:"description"`
-	UserID      int32     `json:"user_id"`
 	CreatedAt   time.Time `json:"created_at"`
+	LedgerID    int32     `json:"ledger_id"`
 }
 
-type User struct {
+type EmailVerificationToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type InstallmentGroup struct {
+	ID               int32     `json:"id"`
+	CategoryID       int32     `json:"category_id"`
+	Title            string    `json:"title"`
+	Type             string    `json:"type"`
+	Description      string    `json:"description"`
+	TotalValue       int32     `json:"total_value"`
+	InstallmentCount int32     `json:"installment_count"`
+	FirstDate        time.Time `json:"first_date"`
+	CreatedAt        time.Time `json:"created_at"`
+	LedgerID         int32     `json:"ledger_id"`
+}
+
+type Ledger struct {
 	ID        int32     `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"password"`
-	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	OwnerID   int32     `json:"owner_id"`
+	Personal  bool      `json:"personal"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LedgerInvitation struct {
+	ID         int32         `json:"id"`
+	LedgerID   int32         `json:"ledger_id"`
+	Email      string        `json:"email"`
+	Role       string        `json:"role"`
+	TokenHash  string        `json:"token_hash"`
+	InvitedBy  sql.NullInt32 `json:"invited_by"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	AcceptedAt sql.NullTime  `json:"accepted_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type LedgerMember struct {
+	LedgerID  int32     `json:"ledger_id"`
+	UserID    int32     `json:"user_id"`
+	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type LoginAttempt struct {
+	Key          string    `json:"key"`
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+type PasswordResetToken struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type RecoveryCode struct {
+	ID        int32        `json:"id"`
+	UserID    int32        `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Recurrence struct {
+	ID                int32         `json:"id"`
+	CategoryID        int32         `json:"category_id"`
+	Title             string        `json:"title"`
+	Type              string        `json:"type"`
+	Description       string        `json:"description"`
+	Value             int32         `json:"value"`
+	Frequency         string        `json:"frequency"`
+	Interval          int32         `json:"interval"`
+	StartDate         time.Time     `json:"start_date"`
+	EndDate           sql.NullTime  `json:"end_date"`
+	Occurrences       sql.NullInt32 `json:"occurrences"`
+	MaterializedUntil sql.NullTime  `json:"materialized_until"`
+	CreatedAt         time.Time     `json:"created_at"`
+	LedgerID          int32         `json:"ledger_id"`
+}
+
+type Session struct {
+	ID         int32        `json:"id"`
+	UserID     int32        `json:"user_id"`
+	FamilyID   string       `json:"family_id"`
+	TokenHash  string       `json:"token_hash"`
+	ExpiresAt  time.Time    `json:"expires_at"`
+	RotatedAt  sql.NullTime `json:"rotated_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	UserAgent  string       `json:"user_agent"`
+	ClientIp   string       `json:"client_ip"`
+	LastUsedAt time.Time    `json:"last_used_at"`
+	StartedAt  time.Time    `json:"started_at"`
+}
+
+type Transfer struct {
+	ID           int32     `json:"id"`
+	UserID       int32     `json:"user_id"`
+	FromWalletID int32     `json:"from_wallet_id"`
+	ToWalletID   int32     `json:"to_wallet_id"`
+	Description  string    `json:"description"`
+	Value        int32     `json:"value"`
+	Date         time.Time `json:"date"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type User struct {
+	ID              int32        `json:"id"`
+	Username        string       `json:"username"`
+	Password        string       `json:"password"`
+	Email           string       `json:"email"`
+	CreatedAt       time.Time    `json:"created_at"`
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+	Role            string       `json:"role"`
+	DisabledAt      sql.NullTime `json:"disabled_at"`
+}
+
+type UserTotp struct {
+	UserID       int32        `json:"user_id"`
+	Secret       string       `json:"secret"`
+	ConfirmedAt  sql.NullTime `json:"confirmed_at"`
+	LastUsedStep int64        `json:"last_used_step"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type Wallet struct {
+	ID             int32     `json:"id"`
+	UserID         int32     `json:"user_id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Currency       string    `json:"currency"`
+	OpeningBalance int32     `json:"opening_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -6,24 +6,136 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AddLedgerMember(ctx context.Context, arg AddLedgerMemberParams) (LedgerMember, error)
+	ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) (UserTotp, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error)
+	CountUsersByRole(ctx context.Context) ([]CountUsersByRoleRow, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error)
+	CreateInstallmentAccount(ctx context.Context, arg CreateInstallmentAccountParams) (Account, error)
+	CreateInstallmentGroup(ctx context.Context, arg CreateInstallmentGroupParams) (InstallmentGroup, error)
+	CreateLedger(ctx context.Context, arg CreateLedgerParams) (Ledger, error)
+	CreateLedgerInvitation(ctx context.Context, arg CreateLedgerInvitationParams) (LedgerInvitation, error)
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateRecurrence(ctx context.Context, arg CreateRecurrenceParams) (Recurrence, error)
+	CreateRecurrenceAccount(ctx context.Context, arg CreateRecurrenceAccountParams) (int64, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	CreateTOTP(ctx context.Context, arg CreateTOTPParams) (UserTotp, error)
+	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	// Wallets are personal, so the legs of a transfer go to the personal ledger
+	// of its user.
+	CreateTransferAccount(ctx context.Context, arg CreateTransferAccountParams) (Account, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int32) error
-	DeleteCategory(ctx context.Context, id int32) error
-	GetAccount(ctx context.Context, id int32) (Account, error)
-	GetAccountGraph(ctx context.Context, arg GetAccountGraphParams) (int64, error)
+	CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error)
+	DeleteAccount(ctx context.Context, arg DeleteAccountParams) error
+	DeleteCategory(ctx context.Context, arg DeleteCategoryParams) error
+	DeleteInstallmentGroup(ctx context.Context, arg DeleteInstallmentGroupParams) error
+	DeleteLedger(ctx context.Context, id int32) error
+	DeleteLedgerAccounts(ctx context.Context, ledgerID int32) error
+	DeleteLedgerCategories(ctx context.Context, ledgerID int32) error
+	DeleteLedgerInstallmentGroups(ctx context.Context, ledgerID int32) error
+	DeleteLedgerInvitation(ctx context.Context, arg DeleteLedgerInvitationParams) (LedgerInvitation, error)
+	DeleteLedgerRecurrences(ctx context.Context, ledgerID int32) error
+	DeleteLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	DeleteRecoveryCodes(ctx context.Context, userID int32) error
+	DeleteRecurrence(ctx context.Context, arg DeleteRecurrenceParams) error
+	DeleteStaleLoginAttempts(ctx context.Context, lastFailedAt time.Time) error
+	DeleteTOTP(ctx context.Context, userID int32) error
+	DeleteTransfer(ctx context.Context, arg DeleteTransferParams) error
+	DeleteUser(ctx context.Context, id int32) error
+	DeleteUserTransfers(ctx context.Context, userID int32) error
+	DeleteUserWallets(ctx context.Context, userID int32) error
+	DeleteWallet(ctx context.Context, arg DeleteWalletParams) error
+	DisableUser(ctx context.Context, id int32) (User, error)
+	EnableUser(ctx context.Context, id int32) (User, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetAccount(ctx context.Context, arg GetAccountParams) (Account, error)
+	// Like GetAccountsSummary, optionally for one category or wallet, with
+	// running totals from the start of the range next to the values of each
+	// period. Balance is income minus expense.
+	GetAccountGraph(ctx context.Context, arg GetAccountGraphParams) ([]GetAccountGraphRow, error)
 	GetAccounts(ctx context.Context, arg GetAccountsParams) ([]GetAccountsRow, error)
 	GetAccountsReports(ctx context.Context, arg GetAccountsReportsParams) (int64, error)
+	// One row per period of @granularity (day, week, month or year) between
+	// @start_date and @end_date, periods without accounts included with zeros.
+	// Transfer legs only move money between wallets and are left out.
+	GetAccountsSummary(ctx context.Context, arg GetAccountsSummaryParams) ([]GetAccountsSummaryRow, error)
 	GetCategories(ctx context.Context, arg GetCategoriesParams) ([]Category, error)
-	GetCategory(ctx context.Context, id int32) (Category, error)
+	GetCategory(ctx context.Context, arg GetCategoryParams) (Category, error)
+	// Totals of the accounts of @type between @start_date and @end_date per
+	// category, largest first. Share is the percentage of the total of all
+	// categories, average the mean value per account. Transfer legs are left out.
+	GetCategoryBreakdown(ctx context.Context, arg GetCategoryBreakdownParams) ([]GetCategoryBreakdownRow, error)
+	GetEmailVerificationTokenByHash(ctx context.Context, tokenHash string) (EmailVerificationToken, error)
+	GetInstallmentAccounts(ctx context.Context, installmentGroupID sql.NullInt32) ([]Account, error)
+	GetInstallmentGroup(ctx context.Context, arg GetInstallmentGroupParams) (InstallmentGroup, error)
+	GetLedger(ctx context.Context, id int32) (Ledger, error)
+	GetLedgerInvitationByHash(ctx context.Context, tokenHash string) (LedgerInvitation, error)
+	GetLedgerMember(ctx context.Context, arg GetLedgerMemberParams) (LedgerMember, error)
+	GetLoginAttempt(ctx context.Context, key string) (LoginAttempt, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetPendingRecurrences(ctx context.Context, until time.Time) ([]Recurrence, error)
+	GetPersonalLedger(ctx context.Context, ownerID int32) (Ledger, error)
+	GetRecurrence(ctx context.Context, arg GetRecurrenceParams) (Recurrence, error)
+	GetRecurrences(ctx context.Context, ledgerID int32) ([]Recurrence, error)
+	GetSession(ctx context.Context, arg GetSessionParams) (Session, error)
+	GetSessionByTokenHash(ctx context.Context, tokenHash string) (Session, error)
+	GetSystemStats(ctx context.Context) (GetSystemStatsRow, error)
+	GetTOTP(ctx context.Context, userID int32) (UserTotp, error)
+	GetTransfer(ctx context.Context, arg GetTransferParams) (Transfer, error)
+	GetTransferAccounts(ctx context.Context, transferID sql.NullInt32) ([]Account, error)
+	GetTransfers(ctx context.Context, userID int32) ([]Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id int32) (User, error)
+	GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error)
+	// Accounts of type 'credit' are income and add to the balance, 'debit' are
+	// expenses and subtract from it.
+	GetWalletBalance(ctx context.Context, arg GetWalletBalanceParams) (GetWalletBalanceRow, error)
+	GetWallets(ctx context.Context, userID int32) ([]Wallet, error)
+	ListAPIKeys(ctx context.Context, userID int32) ([]ApiKey, error)
+	ListActiveSessions(ctx context.Context, userID int32) ([]Session, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListLedgerInvitations(ctx context.Context, ledgerID int32) ([]LedgerInvitation, error)
+	ListLedgerMembers(ctx context.Context, ledgerID int32) ([]ListLedgerMembersRow, error)
+	ListUserLedgers(ctx context.Context, userID int32) ([]ListUserLedgersRow, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	MarkEmailVerificationTokensUsed(ctx context.Context, userID int32) error
+	MarkLedgerInvitationAccepted(ctx context.Context, id int32) error
+	MarkPasswordResetTokensUsed(ctx context.Context, userID int32) error
+	MarkSessionRotated(ctx context.Context, id int32) error
+	MarkUserEmailVerified(ctx context.Context, id int32) (User, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	RemoveLedgerMember(ctx context.Context, arg RemoveLedgerMemberParams) (LedgerMember, error)
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) error
+	RevokeUserSessions(ctx context.Context, userID int32) error
+	SetRecurrenceMaterializedUntil(ctx context.Context, arg SetRecurrenceMaterializedUntilParams) error
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error
 	UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error)
 	UpdateCategories(ctx context.Context, arg UpdateCategoriesParams) (Category, error)
+	UpdateInstallmentGroup(ctx context.Context, arg UpdateInstallmentGroupParams) (InstallmentGroup, error)
+	UpdateLedger(ctx context.Context, arg UpdateLedgerParams) (Ledger, error)
+	UpdateLedgerMemberRole(ctx context.Context, arg UpdateLedgerMemberRoleParams) (LedgerMember, error)
+	UpdateRecurrence(ctx context.Context, arg UpdateRecurrenceParams) (Recurrence, error)
+	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	UpdateTransferAccounts(ctx context.Context, arg UpdateTransferAccountsParams) ([]Account, error)
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (User, error)
+	UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,322 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: recurrence.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createRecurrence = `-- name: CreateRecurrence :one
+INSERT INTO recurrences (
+    ledger_id,
+    category_id,
+    title,
+    type,
+    description,
+    value,
+    frequency,
+    interval,
+    start_date,
+    end_date,
+    occurrences
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING id, category_id, title, type, description, value, frequency, interval, start_date, end_date, occurrences, materialized_until, created_at, ledger_id
+`
+
+type CreateRecurrenceParams struct {
+	LedgerID    int32         `json:"ledger_id"`
+	CategoryID  int32         `json:"category_id"`
+	Title       string        `json:"title"`
+	Type        string        `json:"type"`
+	Description string        `json:"description"`
+	Value       int32         `json:"value"`
+	Frequency   string        `json:"frequency"`
+	Interval    int32         `json:"interval"`
+	StartDate   time.Time     `json:"start_date"`
+	EndDate     sql.NullTime  `json:"end_date"`
+	Occurrences sql.NullInt32 `json:"occurrences"`
+}
+
+func (q *Queries) CreateRecurrence(ctx context.Context, arg CreateRecurrenceParams) (Recurrence, error) {
+	row := q.db.QueryRowContext(ctx, createRecurrence,
+		arg.LedgerID,
+		arg.CategoryID,
+		arg.Title,
+		arg.Type,
+		arg.Description,
+		arg.Value,
+		arg.Frequency,
+		arg.Interval,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Occurrences,
+	)
+	var i Recurrence
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.Value,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Occurrences,
+		&i.MaterializedUntil,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const createRecurrenceAccount = `-- name: CreateRecurrenceAccount :execrows
+INSERT INTO accounts (
+    ledger_id,
+    category_id,
+    recurrence_id,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (recurrence_id, date) DO NOTHING
+`
+
+type CreateRecurrenceAccountParams struct {
+	LedgerID     int32         `json:"ledger_id"`
+	CategoryID   sql.NullInt32 `json:"category_id"`
+	RecurrenceID sql.NullInt32 `json:"recurrence_id"`
+	Title        string        `json:"title"`
+	Type         string        `json:"type"`
+	Description  string        `json:"description"`
+	Date         time.Time     `json:"date"`
+	Value        int32         `json:"value"`
+}
+
+func (q *Queries) CreateRecurrenceAccount(ctx context.Context, arg CreateRecurrenceAccountParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createRecurrenceAccount,
+		arg.LedgerID,
+		arg.CategoryID,
+		arg.RecurrenceID,
+		arg.Title,
+		arg.Type,
+		arg.Description,
+		arg.Date,
+		arg.Value,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteLedgerRecurrences = `-- name: DeleteLedgerRecurrences :exec
+DELETE FROM recurrences WHERE ledger_id = $1
+`
+
+func (q *Queries) DeleteLedgerRecurrences(ctx context.Context, ledgerID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteLedgerRecurrences, ledgerID)
+	return err
+}
+
+const deleteRecurrence = `-- name: DeleteRecurrence :exec
+DELETE FROM recurrences WHERE id = $1 AND ledger_id = $2
+`
+
+type DeleteRecurrenceParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) DeleteRecurrence(ctx context.Context, arg DeleteRecurrenceParams) error {
+	_, err := q.db.ExecContext(ctx, deleteRecurrence, arg.ID, arg.LedgerID)
+	return err
+}
+
+const getPendingRecurrences = `-- name: GetPendingRecurrences :many
+SELECT id, category_id, title, type, description, value, frequency, interval, start_date, end_date, occurrences, materialized_until, created_at, ledger_id FROM recurrences
+ WHERE start_date <= $1::date
+   AND (materialized_until IS NULL OR materialized_until < $1::date)
+`
+
+func (q *Queries) GetPendingRecurrences(ctx context.Context, until time.Time) ([]Recurrence, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingRecurrences, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Recurrence{}
+	for rows.Next() {
+		var i Recurrence
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Type,
+			&i.Description,
+			&i.Value,
+			&i.Frequency,
+			&i.Interval,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Occurrences,
+			&i.MaterializedUntil,
+			&i.CreatedAt,
+			&i.LedgerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecurrence = `-- name: GetRecurrence :one
+SELECT id, category_id, title, type, description, value, frequency, interval, start_date, end_date, occurrences, materialized_until, created_at, ledger_id FROM recurrences WHERE id = $1 AND ledger_id = $2 LIMIT 1
+`
+
+type GetRecurrenceParams struct {
+	ID       int32 `json:"id"`
+	LedgerID int32 `json:"ledger_id"`
+}
+
+func (q *Queries) GetRecurrence(ctx context.Context, arg GetRecurrenceParams) (Recurrence, error) {
+	row := q.db.QueryRowContext(ctx, getRecurrence, arg.ID, arg.LedgerID)
+	var i Recurrence
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.Value,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Occurrences,
+		&i.MaterializedUntil,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const getRecurrences = `-- name: GetRecurrences :many
+SELECT id, category_id, title, type, description, value, frequency, interval, start_date, end_date, occurrences, materialized_until, created_at, ledger_id FROM recurrences
+ WHERE ledger_id = $1
+ ORDER BY start_date, id
+`
+
+func (q *Queries) GetRecurrences(ctx context.Context, ledgerID int32) ([]Recurrence, error) {
+	rows, err := q.db.QueryContext(ctx, getRecurrences, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Recurrence{}
+	for rows.Next() {
+		var i Recurrence
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Type,
+			&i.Description,
+			&i.Value,
+			&i.Frequency,
+			&i.Interval,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Occurrences,
+			&i.MaterializedUntil,
+			&i.CreatedAt,
+			&i.LedgerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setRecurrenceMaterializedUntil = `-- name: SetRecurrenceMaterializedUntil :exec
+UPDATE recurrences SET materialized_until = $2 WHERE id = $1
+`
+
+type SetRecurrenceMaterializedUntilParams struct {
+	ID                int32        `json:"id"`
+	MaterializedUntil sql.NullTime `json:"materialized_until"`
+}
+
+func (q *Queries) SetRecurrenceMaterializedUntil(ctx context.Context, arg SetRecurrenceMaterializedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setRecurrenceMaterializedUntil, arg.ID, arg.MaterializedUntil)
+	return err
+}
+
+const updateRecurrence = `-- name: UpdateRecurrence :one
+UPDATE recurrences
+   SET title = $2, description = $3, value = $4, end_date = $5, occurrences = $6
+ WHERE id = $1 AND ledger_id = $7
+RETURNING id, category_id, title, type, description, value, frequency, interval, start_date, end_date, occurrences, materialized_until, created_at, ledger_id
+`
+
+type UpdateRecurrenceParams struct {
+	ID          int32         `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Value       int32         `json:"value"`
+	EndDate     sql.NullTime  `json:"end_date"`
+	Occurrences sql.NullInt32 `json:"occurrences"`
+	LedgerID    int32         `json:"ledger_id"`
+}
+
+func (q *Queries) UpdateRecurrence(ctx context.Context, arg UpdateRecurrenceParams) (Recurrence, error) {
+	row := q.db.QueryRowContext(ctx, updateRecurrence,
+		arg.ID,
+		arg.Title,
+		arg.Description,
+		arg.Value,
+		arg.EndDate,
+		arg.Occurrences,
+		arg.LedgerID,
+	)
+	var i Recurrence
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.Value,
+		&i.Frequency,
+		&i.Interval,
+		&i.StartDate,
+		&i.EndDate,
+		&i.Occurrences,
+		&i.MaterializedUntil,
+		&i.CreatedAt,
+		&i.LedgerID,
+	)
+	return i, err
+}
@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomRecurrence(t *testing.T) Recurrence {
+	cat := createRandomCategory(t)
+	arg := CreateRecurrenceParams{
+		LedgerID:    cat.LedgerID,
+		CategoryID:  cat.ID,
+		Title:       util.RandomString(12),
+		Type:        cat.Type,
+		Description: util.RandomString(20),
+		Value:       10,
+		Frequency:   "monthly",
+		Interval:    1,
+		StartDate:   time.Now().AddDate(0, -2, 0),
+		Occurrences: sql.NullInt32{
+			Int32: 12,
+			Valid: true,
+		},
+	}
+
+	rec, err := testQueries.CreateRecurrence(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, rec)
+	require.Equal(t, arg.LedgerID, rec.LedgerID)
+	require.Equal(t, arg.CategoryID, rec.CategoryID)
+	require.Equal(t, arg.Title, rec.Title)
+	require.Equal(t, arg.Frequency, rec.Frequency)
+	require.Equal(t, arg.Interval, rec.Interval)
+	require.Equal(t, arg.Occurrences, rec.Occurrences)
+	require.False(t, rec.EndDate.Valid)
+	require.False(t, rec.MaterializedUntil.Valid)
+
+	return rec
+}
+
+func TestCreateRecurrence(t *testing.T) {
+	createRandomRecurrence(t)
+}
+
+func TestGetRecurrence(t *testing.T) {
+	rec1 := createRandomRecurrence(t)
+	rec2, err := testQueries.GetRecurrence(context.Background(), GetRecurrenceParams{ID: rec1.ID, LedgerID: rec1.LedgerID})
+
+	require.NoError(t, err)
+	require.Equal(t, rec1.ID, rec2.ID)
+	require.Equal(t, rec1.Title, rec2.Title)
+	require.Equal(t, rec1.Value, rec2.Value)
+	require.NotEmpty(t, rec2.CreatedAt)
+}
+
+func TestUpdateRecurrence(t *testing.T) {
+	rec1 := createRandomRecurrence(t)
+
+	arg := UpdateRecurrenceParams{
+		ID:          rec1.ID,
+		Title:       util.RandomString(12),
+		Description: util.RandomString(20),
+		Value:       20,
+		EndDate: sql.NullTime{
+			Time:  time.Now().AddDate(1, 0, 0),
+			Valid: true,
+		},
+		LedgerID: rec1.LedgerID,
+	}
+
+	rec2, err := testQueries.UpdateRecurrence(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, rec2.Title)
+	require.Equal(t, arg.Value, rec2.Value)
+	require.True(t, rec2.EndDate.Valid)
+	require.False(t, rec2.Occurrences.Valid)
+	require.Equal(t, rec1.Frequency, rec2.Frequency)
+}
+
+func TestDeleteRecurrence(t *testing.T) {
+	rec := createRandomRecurrence(t)
+	err := testQueries.DeleteRecurrence(context.Background(), DeleteRecurrenceParams{ID: rec.ID, LedgerID: rec.LedgerID})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetRecurrence(context.Background(), GetRecurrenceParams{ID: rec.ID, LedgerID: rec.LedgerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCreateRecurrenceAccountIsIdempotent(t *testing.T) {
+	rec := createRandomRecurrence(t)
+
+	arg := CreateRecurrenceAccountParams{
+		LedgerID: rec.LedgerID,
+		CategoryID: sql.NullInt32{
+			Int32: rec.CategoryID,
+			Valid: true,
+		},
+		RecurrenceID: sql.NullInt32{
+			Int32: rec.ID,
+			Valid: true,
+		},
+		Title:       rec.Title,
+		Type:        rec.Type,
+		Description: rec.Description,
+		Date:        rec.StartDate,
+		Value:       rec.Value,
+	}
+
+	rows, err := testQueries.CreateRecurrenceAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+
+	rows, err = testQueries.CreateRecurrenceAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, rows)
+}
+
+func TestGetPendingRecurrences(t *testing.T) {
+	rec := createRandomRecurrence(t)
+	today := time.Now()
+
+	recs, err := testQueries.GetPendingRecurrences(context.Background(), today)
+	require.NoError(t, err)
+	require.Contains(t, recurrenceIDs(recs), rec.ID)
+
+	err = testQueries.SetRecurrenceMaterializedUntil(context.Background(), SetRecurrenceMaterializedUntilParams{
+		ID: rec.ID,
+		MaterializedUntil: sql.NullTime{
+			Time:  today,
+			Valid: true,
+		},
+	})
+	require.NoError(t, err)
+
+	recs, err = testQueries.GetPendingRecurrences(context.Background(), today)
+	require.NoError(t, err)
+	require.NotContains(t, recurrenceIDs(recs), rec.ID)
+}
+
+func recurrenceIDs(recs []Recurrence) []int32 {
+	ids := []int32{}
+	for _, rec := range recs {
+		ids = append(ids, rec.ID)
+	}
+	return ids
+}
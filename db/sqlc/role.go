@@ -0,0 +1,34 @@
+package db
+
+// Roles a user can have, as allowed by users_role_check.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleSupport is for staff who help users with their accounts, without
+	// the full powers of an admin.
+	RoleSupport = "support"
+)
+
+func validRole(role string) bool {
+	switch role {
+	case RoleUser, RoleAdmin, RoleSupport:
+		return true
+	}
+	return false
+}
+
+// Roles of ledger members, as allowed by ledger_members_role_check. Every
+// ledger has one owner; editors and viewers are invited.
+const (
+	LedgerRoleOwner  = "owner"
+	LedgerRoleEditor = "editor"
+	LedgerRoleViewer = "viewer"
+)
+
+func validLedgerRole(role string) bool {
+	switch role {
+	case LedgerRoleOwner, LedgerRoleEditor, LedgerRoleViewer:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: session.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+    user_id,
+    family_id,
+    token_hash,
+    expires_at,
+    user_agent,
+    client_ip,
+    started_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at, created_at, user_agent, client_ip, last_used_at, started_at
+`
+
+type CreateSessionParams struct {
+	UserID    int32     `json:"user_id"`
+	FamilyID  string    `json:"family_id"`
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, createSession,
+		arg.UserID,
+		arg.FamilyID,
+		arg.TokenHash,
+		arg.ExpiresAt,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.StartedAt,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.LastUsedAt,
+		&i.StartedAt,
+	)
+	return i, err
+}
+
+const getSession = `-- name: GetSession :one
+SELECT id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at, created_at, user_agent, client_ip, last_used_at, started_at FROM sessions WHERE id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetSessionParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) GetSession(ctx context.Context, arg GetSessionParams) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSession, arg.ID, arg.UserID)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.LastUsedAt,
+		&i.StartedAt,
+	)
+	return i, err
+}
+
+const getSessionByTokenHash = `-- name: GetSessionByTokenHash :one
+SELECT id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at, created_at, user_agent, client_ip, last_used_at, started_at FROM sessions WHERE token_hash = $1 LIMIT 1 FOR UPDATE
+`
+
+func (q *Queries) GetSessionByTokenHash(ctx context.Context, tokenHash string) (Session, error) {
+	row := q.db.QueryRowContext(ctx, getSessionByTokenHash, tokenHash)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FamilyID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.RotatedAt,
+		&i.RevokedAt,
+		&i.CreatedAt,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.LastUsedAt,
+		&i.StartedAt,
+	)
+	return i, err
+}
+
+const listActiveSessions = `-- name: ListActiveSessions :many
+SELECT id, user_id, family_id, token_hash, expires_at, rotated_at, revoked_at, created_at, user_agent, client_ip, last_used_at, started_at FROM sessions
+ WHERE user_id = $1
+   AND rotated_at IS NULL
+   AND revoked_at IS NULL
+   AND expires_at > now()
+ ORDER BY last_used_at DESC, id DESC
+`
+
+func (q *Queries) ListActiveSessions(ctx context.Context, userID int32) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.FamilyID,
+			&i.TokenHash,
+			&i.ExpiresAt,
+			&i.RotatedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.LastUsedAt,
+			&i.StartedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markSessionRotated = `-- name: MarkSessionRotated :exec
+UPDATE sessions SET rotated_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkSessionRotated(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, markSessionRotated, id)
+	return err
+}
+
+const revokeSessionFamily = `-- name: RevokeSessionFamily :exec
+UPDATE sessions SET revoked_at = now()
+ WHERE family_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	_, err := q.db.ExecContext(ctx, revokeSessionFamily, familyID)
+	return err
+}
+
+const revokeUserSessions = `-- name: RevokeUserSessions :exec
+UPDATE sessions SET revoked_at = now()
+ WHERE user_id = $1 AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeUserSessions(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, revokeUserSessions, userID)
+	return err
+}
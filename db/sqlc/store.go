@@ -1,9 +1,39 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type Store interface {
 	Querier
+	ExecTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
+	CreateInstallmentsTx(ctx context.Context, arg CreateInstallmentsTxParams) (InstallmentsTxResult, error)
+	UpdateInstallmentsTx(ctx context.Context, arg UpdateInstallmentsTxParams) (InstallmentsTxResult, error)
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	GetTransferDetails(ctx context.Context, arg GetTransferParams) (TransferTxResult, error)
+	UpdateTransferTx(ctx context.Context, arg UpdateTransferTxParams) (TransferTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	VerifyEmailTx(ctx context.Context, tokenHash string) (User, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
+	ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteUserTx(ctx context.Context, userID int32) error
+	DisableUserTx(ctx context.Context, userID int32) (User, error)
+	ForcePasswordResetTx(ctx context.Context, arg ForcePasswordResetTxParams) (User, error)
+	ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (UserTotp, error)
+	ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error
+	DisableTOTPTx(ctx context.Context, userID int32) error
+	CreateLedgerTx(ctx context.Context, arg CreateLedgerTxParams) (Ledger, error)
+	DeleteLedgerTx(ctx context.Context, ledgerID int32) error
+	AcceptLedgerInvitationTx(ctx context.Context, arg AcceptLedgerInvitationTxParams) (LedgerMember, error)
 }
 
 type SQLStore struct {
@@ -17,3 +47,98 @@ func NewStore(db *sql.DB) *SQLStore {
 		Queries: New(db),
 	}
 }
+
+// txRunner is the part of a Store the multi-statement operations are built on,
+// so every Store implementation shares them.
+type txRunner interface {
+	ExecTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error
+}
+
+const defaultTxRetries = 3
+
+// TxOptions configures ExecTx. The zero value runs at the database's default
+// isolation level and retries up to defaultTxRetries times.
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// MaxRetries is how many times a transaction aborted by a serialization
+	// failure or a deadlock is run again. A negative value disables retries.
+	MaxRetries int
+}
+
+// ExecTx runs fn with queries bound to a single transaction, committing when fn
+// succeeds and rolling back when it returns an error. Transactions Postgres
+// aborts with a serialization failure or a deadlock are retried from the start,
+// so fn must not keep side effects outside the transaction between attempts.
+func (store *SQLStore) ExecTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	retries := opts.MaxRetries
+	if retries == 0 {
+		retries = defaultTxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		if err == nil || attempt >= retries || !IsRetryableTxError(err) {
+			return err
+		}
+
+		backoff := time.Duration(10*(attempt+1)+rand.Intn(10)) * time.Millisecond
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts TxOptions, fn func(Querier) error) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: opts.Isolation,
+		ReadOnly:  opts.ReadOnly,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = fn(New(tx))
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// IsRetryableTxError reports whether err is a Postgres serialization failure
+// or deadlock, after which the whole transaction can safely be run again.
+func IsRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+	return false
+}
+
+// IsUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func IsUniqueViolation(err error) bool {
+	_, ok := UniqueViolation(err)
+	return ok
+}
+
+// UniqueViolation returns the name of the constraint or unique index a
+// Postgres unique violation was raised for.
+func UniqueViolation(err error) (constraint string, ok bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code.Name() != "unique_violation" {
+		return "", false
+	}
+	return pqErr.Constraint, true
+}
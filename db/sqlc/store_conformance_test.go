@@ -0,0 +1,451 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStoreConformance runs the same cases against MemStore, which the api
+// tests use, and SQLStore, so the two cannot drift apart. Without a database
+// only MemStore is checked.
+func TestStoreConformance(t *testing.T) {
+	t.Run("MemStore", func(t *testing.T) {
+		runStoreConformance(t, NewMemStore())
+	})
+	t.Run("SQLStore", func(t *testing.T) {
+		if err := testDB.Ping(); err != nil {
+			t.Skipf("no database: %v", err)
+		}
+		runStoreConformance(t, NewStore(testDB))
+	})
+}
+
+// runStoreConformance checks store against the behaviour of the queries.
+// Every case signs up its own users, so it also runs on a shared database.
+func runStoreConformance(t *testing.T, store Store) {
+	for _, tc := range []struct {
+		name string
+		run  func(t *testing.T, store Store)
+	}{
+		{"GetAccountsFilters", conformGetAccountsFilters},
+		{"AccountsSummaryWeeks", conformAccountsSummaryWeeks},
+		{"AccountsSummaryMonths", conformAccountsSummaryMonths},
+		{"CategoryBreakdown", conformCategoryBreakdown},
+		{"AccountGraph", conformAccountGraph},
+		{"TransfersLeftOutOfReports", conformTransfersLeftOutOfReports},
+		{"Constraints", conformConstraints},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, store)
+		})
+	}
+}
+
+// conformUser signs up a user and returns them with their personal ledger.
+func conformUser(t *testing.T, store Store) (User, int32) {
+	user, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username: util.RandomString(12),
+		Password: util.RandomString(32),
+		Email:    util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	ledgers, err := store.ListUserLedgers(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, ledgers, 1)
+	require.True(t, ledgers[0].Personal)
+	return user, ledgers[0].ID
+}
+
+func conformCategory(t *testing.T, store Store, ledgerID int32, title, typ string) Category {
+	cat, err := store.CreateCategory(context.Background(), CreateCategoryParams{
+		LedgerID:    ledgerID,
+		Title:       title,
+		Type:        typ,
+		Description: util.RandomString(20),
+	})
+	require.NoError(t, err)
+	return cat
+}
+
+func conformWallet(t *testing.T, store Store, userID int32, openingBalance int32) Wallet {
+	wallet, err := store.CreateWallet(context.Background(), CreateWalletParams{
+		UserID:         userID,
+		Name:           util.RandomString(8),
+		Type:           "cash",
+		Currency:       "BRL",
+		OpeningBalance: openingBalance,
+	})
+	require.NoError(t, err)
+	return wallet
+}
+
+// conformAccount creates an account. The category and wallet are optional.
+func conformAccount(t *testing.T, store Store, arg CreateAccountParams) Account {
+	if arg.Title == "" {
+		arg.Title = util.RandomString(12)
+	}
+	if arg.Description == "" {
+		arg.Description = util.RandomString(20)
+	}
+	account, err := store.CreateAccount(context.Background(), arg)
+	require.NoError(t, err)
+	return account
+}
+
+func conformDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func nullInt32(value int32) sql.NullInt32 {
+	return sql.NullInt32{Int32: value, Valid: true}
+}
+
+// periodDays formats the periods of report rows as days, since the drivers
+// may return them in different locations.
+func periodDays(periods []time.Time) []string {
+	days := make([]string, len(periods))
+	for i, period := range periods {
+		days[i] = period.Format("2006-01-02")
+	}
+	return days
+}
+
+func conformGetAccountsFilters(t *testing.T, store Store) {
+	_, ledgerID := conformUser(t, store)
+	food := conformCategory(t, store, ledgerID, "Food", "debit")
+	rent := conformCategory(t, store, ledgerID, "Rent", "debit")
+	date := conformDate(2023, time.May, 10)
+
+	lunch := conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(food.ID), Title: "Lunch out",
+		Description: "Pizza place", Type: "debit", Value: 40, Date: date,
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(food.ID), Title: "Groceries",
+		Description: "Market", Type: "debit", Value: 120, Date: date.AddDate(0, 0, 1),
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(rent.ID), Title: "May rent",
+		Description: "Landlord", Type: "debit", Value: 900, Date: date,
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, Title: "Lunch refund", Type: "credit", Value: 40, Date: date,
+	})
+
+	titles := func(arg GetAccountsParams) []string {
+		arg.LedgerID = ledgerID
+		rows, err := store.GetAccounts(context.Background(), arg)
+		require.NoError(t, err)
+		titles := make([]string, len(rows))
+		for i, row := range rows {
+			titles[i] = row.Title
+		}
+		return titles
+	}
+
+	require.ElementsMatch(t, []string{"Lunch out", "Groceries", "May rent"}, titles(GetAccountsParams{Type: "debit"}))
+	require.ElementsMatch(t, []string{"Lunch refund"}, titles(GetAccountsParams{Type: "credit"}))
+	require.ElementsMatch(t, []string{"Lunch out", "Groceries"}, titles(GetAccountsParams{Type: "debit", CategoryID: nullInt32(food.ID)}))
+	require.ElementsMatch(t, []string{"Lunch out"}, titles(GetAccountsParams{Type: "debit", Title: "LUNCH"}))
+	require.ElementsMatch(t, []string{"Lunch out"}, titles(GetAccountsParams{Type: "debit", Description: "pizza"}))
+	require.ElementsMatch(t, []string{"Lunch out", "May rent"}, titles(GetAccountsParams{
+		Type: "debit", Date: sql.NullTime{Time: date, Valid: true},
+	}))
+
+	rows, err := store.GetAccounts(context.Background(), GetAccountsParams{
+		LedgerID: ledgerID, Type: "debit", Title: "Lunch",
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	require.Equal(t, lunch.ID, rows[0].ID)
+	require.Equal(t, "Food", rows[0].CategoryTitle.String)
+}
+
+func conformAccountsSummaryWeeks(t *testing.T, store Store) {
+	_, ledgerID := conformUser(t, store)
+
+	// May 1st 2023 is a Monday. The weeks of the bounds are partly outside
+	// of the range, and so are the accounts on those days.
+	for _, account := range []struct {
+		typ   string
+		value int32
+		date  time.Time
+	}{
+		{"credit", 999, conformDate(2023, time.May, 2)},
+		{"credit", 100, conformDate(2023, time.May, 3)},
+		{"debit", 40, conformDate(2023, time.May, 7)},
+		{"credit", 10, conformDate(2023, time.May, 15)},
+		{"debit", 5, conformDate(2023, time.May, 16)},
+	} {
+		conformAccount(t, store, CreateAccountParams{
+			LedgerID: ledgerID, Type: account.typ, Value: account.value, Date: account.date,
+		})
+	}
+
+	rows, err := store.GetAccountsSummary(context.Background(), GetAccountsSummaryParams{
+		Granularity: "week",
+		StartDate:   conformDate(2023, time.May, 3),
+		EndDate:     conformDate(2023, time.May, 15),
+		LedgerID:    ledgerID,
+	})
+	require.NoError(t, err)
+
+	periods := make([]time.Time, len(rows))
+	for i, row := range rows {
+		periods[i] = row.Period
+	}
+	require.Equal(t, []string{"2023-05-01", "2023-05-08", "2023-05-15"}, periodDays(periods))
+	require.Equal(t, []int64{100, 0, 10}, []int64{rows[0].Income, rows[1].Income, rows[2].Income})
+	require.Equal(t, []int64{40, 0, 0}, []int64{rows[0].Expense, rows[1].Expense, rows[2].Expense})
+	require.Equal(t, []int64{60, 0, 10}, []int64{rows[0].Net, rows[1].Net, rows[2].Net})
+}
+
+func conformAccountsSummaryMonths(t *testing.T, store Store) {
+	_, ledgerID := conformUser(t, store)
+	for _, account := range []struct {
+		typ   string
+		value int32
+		date  time.Time
+	}{
+		{"credit", 7, conformDate(2023, time.January, 31)},
+		{"debit", 3, conformDate(2023, time.February, 28)},
+		{"credit", 2, conformDate(2023, time.March, 1)},
+		{"credit", 50, conformDate(2023, time.March, 2)},
+	} {
+		conformAccount(t, store, CreateAccountParams{
+			LedgerID: ledgerID, Type: account.typ, Value: account.value, Date: account.date,
+		})
+	}
+
+	rows, err := store.GetAccountsSummary(context.Background(), GetAccountsSummaryParams{
+		Granularity: "month",
+		StartDate:   conformDate(2023, time.January, 31),
+		EndDate:     conformDate(2023, time.March, 1),
+		LedgerID:    ledgerID,
+	})
+	require.NoError(t, err)
+
+	periods := make([]time.Time, len(rows))
+	for i, row := range rows {
+		periods[i] = row.Period
+	}
+	require.Equal(t, []string{"2023-01-01", "2023-02-01", "2023-03-01"}, periodDays(periods))
+	require.Equal(t, []int64{7, 0, 2}, []int64{rows[0].Income, rows[1].Income, rows[2].Income})
+	require.Equal(t, []int64{0, 3, 0}, []int64{rows[0].Expense, rows[1].Expense, rows[2].Expense})
+	require.Equal(t, []int64{7, -3, 2}, []int64{rows[0].Net, rows[1].Net, rows[2].Net})
+}
+
+func conformCategoryBreakdown(t *testing.T, store Store) {
+	_, ledgerID := conformUser(t, store)
+	gamma := conformCategory(t, store, ledgerID, "gamma", "debit")
+	beta := conformCategory(t, store, ledgerID, "beta", "debit")
+	alpha := conformCategory(t, store, ledgerID, "alpha", "debit")
+	date := conformDate(2023, time.May, 10)
+
+	for _, account := range []struct {
+		category sql.NullInt32
+		typ      string
+		value    int32
+		date     time.Time
+	}{
+		{nullInt32(gamma.ID), "debit", 1, date},
+		{nullInt32(gamma.ID), "debit", 1, date},
+		{nullInt32(gamma.ID), "debit", 2, date},
+		{nullInt32(beta.ID), "debit", 1, date},
+		{nullInt32(alpha.ID), "debit", 1, date},
+		{sql.NullInt32{}, "debit", 1, date},
+		{nullInt32(alpha.ID), "credit", 500, date},
+		{nullInt32(alpha.ID), "debit", 500, date.AddDate(0, 0, 1)},
+	} {
+		conformAccount(t, store, CreateAccountParams{
+			LedgerID: ledgerID, CategoryID: account.category, Type: account.typ,
+			Value: account.value, Date: account.date,
+		})
+	}
+
+	rows, err := store.GetCategoryBreakdown(context.Background(), GetCategoryBreakdownParams{
+		LedgerID:  ledgerID,
+		Type:      "debit",
+		StartDate: date,
+		EndDate:   date,
+	})
+	require.NoError(t, err)
+
+	// Ties are ordered by title, accounts without a category last.
+	require.Equal(t, []GetCategoryBreakdownRow{
+		{CategoryID: nullInt32(gamma.ID), CategoryTitle: sql.NullString{String: "gamma", Valid: true}, Total: 4, Count: 3, Share: 57.14, Average: 1.33},
+		{CategoryID: nullInt32(alpha.ID), CategoryTitle: sql.NullString{String: "alpha", Valid: true}, Total: 1, Count: 1, Share: 14.29, Average: 1},
+		{CategoryID: nullInt32(beta.ID), CategoryTitle: sql.NullString{String: "beta", Valid: true}, Total: 1, Count: 1, Share: 14.29, Average: 1},
+		{Total: 1, Count: 1, Share: 14.29, Average: 1},
+	}, rows)
+}
+
+func conformAccountGraph(t *testing.T, store Store) {
+	user, ledgerID := conformUser(t, store)
+	salary := conformCategory(t, store, ledgerID, "Salary", "credit")
+	food := conformCategory(t, store, ledgerID, "Food", "debit")
+	wallet := conformWallet(t, store, user.ID, 0)
+
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(salary.ID), WalletID: nullInt32(wallet.ID),
+		Type: "credit", Value: 100, Date: conformDate(2023, time.January, 5),
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(food.ID),
+		Type: "debit", Value: 30, Date: conformDate(2023, time.February, 5),
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(salary.ID),
+		Type: "credit", Value: 50, Date: conformDate(2023, time.March, 5),
+	})
+
+	graph := func(category, wallet sql.NullInt32) []GetAccountGraphRow {
+		rows, err := store.GetAccountGraph(context.Background(), GetAccountGraphParams{
+			Granularity: "month",
+			StartDate:   conformDate(2023, time.January, 1),
+			EndDate:     conformDate(2023, time.March, 31),
+			LedgerID:    ledgerID,
+			CategoryID:  category,
+			WalletID:    wallet,
+		})
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		return rows
+	}
+
+	rows := graph(sql.NullInt32{}, sql.NullInt32{})
+	periods := make([]time.Time, len(rows))
+	for i, row := range rows {
+		periods[i] = row.Period
+	}
+	require.Equal(t, []string{"2023-01-01", "2023-02-01", "2023-03-01"}, periodDays(periods))
+	require.Equal(t, []int64{100, -30, 50}, []int64{rows[0].Balance, rows[1].Balance, rows[2].Balance})
+	require.Equal(t, []int64{100, 100, 150}, []int64{rows[0].CumulativeIncome, rows[1].CumulativeIncome, rows[2].CumulativeIncome})
+	require.Equal(t, []int64{0, 30, 30}, []int64{rows[0].CumulativeExpense, rows[1].CumulativeExpense, rows[2].CumulativeExpense})
+	require.Equal(t, []int64{100, 70, 120}, []int64{rows[0].CumulativeBalance, rows[1].CumulativeBalance, rows[2].CumulativeBalance})
+
+	rows = graph(nullInt32(food.ID), sql.NullInt32{})
+	require.Equal(t, []int64{0, -30, -30}, []int64{rows[0].CumulativeBalance, rows[1].CumulativeBalance, rows[2].CumulativeBalance})
+
+	rows = graph(sql.NullInt32{}, nullInt32(wallet.ID))
+	require.Equal(t, []int64{100, 100, 100}, []int64{rows[0].CumulativeBalance, rows[1].CumulativeBalance, rows[2].CumulativeBalance})
+}
+
+func conformTransfersLeftOutOfReports(t *testing.T, store Store) {
+	user, ledgerID := conformUser(t, store)
+	checking := conformWallet(t, store, user.ID, 1000)
+	savings := conformWallet(t, store, user.ID, 0)
+	date := conformDate(2023, time.May, 10)
+
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, WalletID: nullInt32(checking.ID), Type: "credit", Value: 200, Date: date.AddDate(0, 0, -9),
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, WalletID: nullInt32(checking.ID), Type: "debit", Value: 50, Date: date.AddDate(0, 0, -8),
+	})
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, WalletID: nullInt32(checking.ID), Type: "credit", Value: 10, Date: date.AddDate(0, 0, 10),
+	})
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		UserID:       user.ID,
+		FromWalletID: checking.ID,
+		ToWalletID:   savings.ID,
+		Description:  util.RandomString(20),
+		Value:        100,
+		Date:         date,
+	})
+	require.NoError(t, err)
+
+	// Transfers move money between wallets...
+	balance, err := store.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+		Date: date, WalletID: checking.ID, UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, GetWalletBalanceRow{
+		WalletID: checking.ID, OpeningBalance: 1000, Income: 200, Expense: 150, Balance: 1050,
+	}, balance)
+	balance, err = store.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+		Date: date, WalletID: savings.ID, UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), balance.Balance)
+
+	// ...but are neither income nor expense.
+	summary, err := store.GetAccountsSummary(context.Background(), GetAccountsSummaryParams{
+		Granularity: "day", StartDate: date, EndDate: date, LedgerID: ledgerID,
+	})
+	require.NoError(t, err)
+	require.Len(t, summary, 1)
+	require.Zero(t, summary[0].Income)
+	require.Zero(t, summary[0].Expense)
+
+	breakdown, err := store.GetCategoryBreakdown(context.Background(), GetCategoryBreakdownParams{
+		LedgerID: ledgerID, Type: "debit", StartDate: date, EndDate: date,
+	})
+	require.NoError(t, err)
+	require.Empty(t, breakdown)
+
+	total, err := store.GetAccountsReports(context.Background(), GetAccountsReportsParams{
+		LedgerID: ledgerID, Type: "debit",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50), total)
+}
+
+// requireConstraint checks that err is a violation of constraint.
+func requireConstraint(t *testing.T, err error, code pq.ErrorCode, constraint string) {
+	var pqErr *pq.Error
+	require.True(t, errors.As(err, &pqErr), "%v is not a database error", err)
+	require.Equal(t, code, pqErr.Code)
+	require.Equal(t, constraint, pqErr.Constraint)
+}
+
+func conformConstraints(t *testing.T, store Store) {
+	user, ledgerID := conformUser(t, store)
+	cat := conformCategory(t, store, ledgerID, "Food", "debit")
+	date := conformDate(2023, time.May, 10)
+
+	_, err := store.CreateUserTx(context.Background(), CreateUserParams{
+		Username: util.RandomString(12),
+		Password: util.RandomString(32),
+		Email:    user.Email,
+	})
+	requireConstraint(t, err, "23505", "users_email_key")
+
+	_, err = store.CreateCategory(context.Background(), CreateCategoryParams{
+		LedgerID: -1, Title: util.RandomString(8), Type: "debit", Description: util.RandomString(20),
+	})
+	requireConstraint(t, err, "23503", "categories_ledger_id_fkey")
+
+	_, err = store.CreateAccount(context.Background(), CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(-1), Title: util.RandomString(12),
+		Description: util.RandomString(20), Type: "debit", Value: 1, Date: date,
+	})
+	requireConstraint(t, err, "23503", "accounts_category_id_fkey")
+
+	_, err = store.CreateAccount(context.Background(), CreateAccountParams{
+		LedgerID: ledgerID, WalletID: nullInt32(-1), Title: util.RandomString(12),
+		Description: util.RandomString(20), Type: "debit", Value: 1, Date: date,
+	})
+	requireConstraint(t, err, "23503", "accounts_wallet_id_fkey")
+
+	_, err = store.CreateWallet(context.Background(), CreateWalletParams{
+		UserID: -1, Name: util.RandomString(8), Type: "cash", Currency: "BRL",
+	})
+	requireConstraint(t, err, "23503", "wallets_user_id_fkey")
+
+	// Categories in use cannot be deleted.
+	conformAccount(t, store, CreateAccountParams{
+		LedgerID: ledgerID, CategoryID: nullInt32(cat.ID), Type: "debit", Value: 1, Date: date,
+	})
+	err = store.DeleteCategory(context.Background(), DeleteCategoryParams{ID: cat.ID, LedgerID: ledgerID})
+	requireConstraint(t, err, "23503", "accounts_category_id_fkey")
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecTxRollsBackOnError(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	failure := errors.New("boom")
+
+	var wallet Wallet
+	err := store.ExecTx(context.Background(), TxOptions{}, func(q Querier) error {
+		var err error
+		wallet, err = q.CreateWallet(context.Background(), CreateWalletParams{
+			UserID:   user.ID,
+			Name:     util.RandomString(8),
+			Type:     "cash",
+			Currency: "BRL",
+		})
+		if err != nil {
+			return err
+		}
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	_, err = testQueries.GetWallet(context.Background(), GetWalletParams{ID: wallet.ID, UserID: wallet.UserID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExecTxSerializableConcurrentUpdates(t *testing.T) {
+	store := NewStore(testDB)
+	wallet := createRandomWallet(t, createRandomUser(t))
+
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			opts := TxOptions{Isolation: sql.LevelSerializable, MaxRetries: 50}
+			errs <- store.ExecTx(context.Background(), opts, func(q Querier) error {
+				current, err := q.GetWallet(context.Background(), GetWalletParams{ID: wallet.ID, UserID: wallet.UserID})
+				if err != nil {
+					return err
+				}
+				_, err = q.UpdateWallet(context.Background(), UpdateWalletParams{
+					ID:             current.ID,
+					Name:           current.Name,
+					Type:           current.Type,
+					Currency:       current.Currency,
+					OpeningBalance: current.OpeningBalance + 1,
+					UserID:         current.UserID,
+				})
+				return err
+			})
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	updated, err := testQueries.GetWallet(context.Background(), GetWalletParams{ID: wallet.ID, UserID: wallet.UserID})
+	require.NoError(t, err)
+	require.Equal(t, wallet.OpeningBalance+int32(n), updated.OpeningBalance)
+}
+
+func TestTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	wallet1 := createRandomWallet(t, user)
+	wallet2 := createRandomWallet(t, user)
+
+	n := 10
+	value := int32(10)
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		from, to := wallet1.ID, wallet2.ID
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				UserID:       user.ID,
+				FromWalletID: from,
+				ToWalletID:   to,
+				Description:  fmt.Sprintf("%d -> %d", from, to),
+				Value:        value,
+				Date:         time.Now(),
+			})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	transfers, err := testQueries.GetTransfers(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, transfers, n)
+
+	for _, wallet := range []Wallet{wallet1, wallet2} {
+		balance, err := testQueries.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+			WalletID: wallet.ID,
+			UserID:   wallet.UserID,
+			Date:     time.Now(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(wallet.OpeningBalance), balance.Balance)
+		require.Equal(t, int64(n/2)*int64(value), balance.Income)
+		require.Equal(t, int64(n/2)*int64(value), balance.Expense)
+	}
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, IsRetryableTxError(&pq.Error{Code: "40001"}))
+	require.True(t, IsRetryableTxError(fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"})))
+	require.False(t, IsRetryableTxError(&pq.Error{Code: "23505"}))
+	require.False(t, IsRetryableTxError(sql.ErrNoRows))
+}
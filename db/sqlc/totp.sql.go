@@ -0,0 +1,185 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: totp.sql
+
+package db
+
+import (
+	"context"
+)
+
+const confirmTOTP = `-- name: ConfirmTOTP :one
+UPDATE user_totp SET confirmed_at = now(), last_used_step = $2
+ WHERE user_id = $1 AND confirmed_at IS NULL
+RETURNING user_id, secret, confirmed_at, last_used_step, created_at
+`
+
+type ConfirmTOTPParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, confirmTOTP, arg.UserID, arg.LastUsedStep)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countUnusedRecoveryCodes = `-- name: CountUnusedRecoveryCodes :one
+SELECT count(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) CountUnusedRecoveryCodes(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnusedRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createRecoveryCode = `-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (
+    user_id,
+    code_hash
+) VALUES ($1, $2)
+RETURNING id, user_id, code_hash, used_at, created_at
+`
+
+type CreateRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createRecoveryCode, arg.UserID, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTOTP = `-- name: CreateTOTP :one
+INSERT INTO user_totp (
+    user_id,
+    secret
+) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET
+    secret = EXCLUDED.secret,
+    last_used_step = 0,
+    created_at = now()
+  WHERE user_totp.confirmed_at IS NULL
+RETURNING user_id, secret, confirmed_at, last_used_step, created_at
+`
+
+type CreateTOTPParams struct {
+	UserID int32  `json:"user_id"`
+	Secret string `json:"secret"`
+}
+
+func (q *Queries) CreateTOTP(ctx context.Context, arg CreateTOTPParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, createTOTP, arg.UserID, arg.Secret)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRecoveryCodes = `-- name: DeleteRecoveryCodes :exec
+DELETE FROM recovery_codes WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRecoveryCodes(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteRecoveryCodes, userID)
+	return err
+}
+
+const deleteTOTP = `-- name: DeleteTOTP :exec
+DELETE FROM user_totp WHERE user_id = $1
+`
+
+func (q *Queries) DeleteTOTP(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteTOTP, userID)
+	return err
+}
+
+const getTOTP = `-- name: GetTOTP :one
+SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetTOTP(ctx context.Context, userID int32) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, getTOTP, userID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useRecoveryCode = `-- name: UseRecoveryCode :one
+UPDATE recovery_codes SET used_at = now()
+ WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+RETURNING id, user_id, code_hash, used_at, created_at
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   int32  `json:"user_id"`
+	CodeHash string `json:"code_hash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (RecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useRecoveryCode, arg.UserID, arg.CodeHash)
+	var i RecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.CodeHash,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useTOTPStep = `-- name: UseTOTPStep :one
+UPDATE user_totp SET last_used_step = $2
+ WHERE user_id = $1 AND last_used_step < $2
+RETURNING user_id, secret, confirmed_at, last_used_step, created_at
+`
+
+type UseTOTPStepParams struct {
+	UserID       int32 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, useTOTPStep, arg.UserID, arg.LastUsedStep)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.ConfirmedAt,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,341 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: transfer.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createTransfer = `-- name: CreateTransfer :one
+INSERT INTO transfers (
+    user_id,
+    from_wallet_id,
+    to_wallet_id,
+    description,
+    value,
+    date
+) VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, user_id, from_wallet_id, to_wallet_id, description, value, date, created_at
+`
+
+type CreateTransferParams struct {
+	UserID       int32     `json:"user_id"`
+	FromWalletID int32     `json:"from_wallet_id"`
+	ToWalletID   int32     `json:"to_wallet_id"`
+	Description  string    `json:"description"`
+	Value        int32     `json:"value"`
+	Date         time.Time `json:"date"`
+}
+
+func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.UserID,
+		arg.FromWalletID,
+		arg.ToWalletID,
+		arg.Description,
+		arg.Value,
+		arg.Date,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FromWalletID,
+		&i.ToWalletID,
+		&i.Description,
+		&i.Value,
+		&i.Date,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTransferAccount = `-- name: CreateTransferAccount :one
+INSERT INTO accounts (
+    ledger_id,
+    wallet_id,
+    transfer_id,
+    title,
+    type,
+    description,
+    date,
+    value
+) VALUES (
+    (SELECT id FROM ledgers WHERE owner_id = $1 AND personal),
+    $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id
+`
+
+type CreateTransferAccountParams struct {
+	UserID      int32         `json:"user_id"`
+	WalletID    sql.NullInt32 `json:"wallet_id"`
+	TransferID  sql.NullInt32 `json:"transfer_id"`
+	Title       string        `json:"title"`
+	Type        string        `json:"type"`
+	Description string        `json:"description"`
+	Date        time.Time     `json:"date"`
+	Value       int32         `json:"value"`
+}
+
+// Wallets are personal, so the legs of a transfer go to the personal ledger
+// of its user.
+func (q *Queries) CreateTransferAccount(ctx context.Context, arg CreateTransferAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, createTransferAccount,
+		arg.UserID,
+		arg.WalletID,
+		arg.TransferID,
+		arg.Title,
+		arg.Type,
+		arg.Description,
+		arg.Date,
+		arg.Value,
+	)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.CategoryID,
+		&i.Title,
+		&i.Type,
+		&i.Description,
+		&i.Value,
+		&i.Date,
+		&i.CreatedAt,
+		&i.RecurrenceID,
+		&i.InstallmentGroupID,
+		&i.InstallmentNumber,
+		&i.WalletID,
+		&i.TransferID,
+		&i.LedgerID,
+	)
+	return i, err
+}
+
+const deleteTransfer = `-- name: DeleteTransfer :exec
+DELETE FROM transfers WHERE id = $1 AND user_id = $2
+`
+
+type DeleteTransferParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) DeleteTransfer(ctx context.Context, arg DeleteTransferParams) error {
+	_, err := q.db.ExecContext(ctx, deleteTransfer, arg.ID, arg.UserID)
+	return err
+}
+
+const deleteUserTransfers = `-- name: DeleteUserTransfers :exec
+DELETE FROM transfers WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserTransfers(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserTransfers, userID)
+	return err
+}
+
+const getTransfer = `-- name: GetTransfer :one
+SELECT id, user_id, from_wallet_id, to_wallet_id, description, value, date, created_at FROM transfers WHERE id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetTransferParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) GetTransfer(ctx context.Context, arg GetTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransfer, arg.ID, arg.UserID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FromWalletID,
+		&i.ToWalletID,
+		&i.Description,
+		&i.Value,
+		&i.Date,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferAccounts = `-- name: GetTransferAccounts :many
+SELECT id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id FROM accounts
+ WHERE transfer_id = $1
+ ORDER BY id
+`
+
+func (q *Queries) GetTransferAccounts(ctx context.Context, transferID sql.NullInt32) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, getTransferAccounts, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Type,
+			&i.Description,
+			&i.Value,
+			&i.Date,
+			&i.CreatedAt,
+			&i.RecurrenceID,
+			&i.InstallmentGroupID,
+			&i.InstallmentNumber,
+			&i.WalletID,
+			&i.TransferID,
+			&i.LedgerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTransfers = `-- name: GetTransfers :many
+SELECT id, user_id, from_wallet_id, to_wallet_id, description, value, date, created_at FROM transfers
+ WHERE user_id = $1
+ ORDER BY date DESC, id DESC
+`
+
+func (q *Queries) GetTransfers(ctx context.Context, userID int32) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, getTransfers, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.FromWalletID,
+			&i.ToWalletID,
+			&i.Description,
+			&i.Value,
+			&i.Date,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTransfer = `-- name: UpdateTransfer :one
+UPDATE transfers
+   SET description = $2, value = $3, date = $4
+ WHERE id = $1 AND user_id = $5
+RETURNING id, user_id, from_wallet_id, to_wallet_id, description, value, date, created_at
+`
+
+type UpdateTransferParams struct {
+	ID          int32     `json:"id"`
+	Description string    `json:"description"`
+	Value       int32     `json:"value"`
+	Date        time.Time `json:"date"`
+	UserID      int32     `json:"user_id"`
+}
+
+func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransfer,
+		arg.ID,
+		arg.Description,
+		arg.Value,
+		arg.Date,
+		arg.UserID,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.FromWalletID,
+		&i.ToWalletID,
+		&i.Description,
+		&i.Value,
+		&i.Date,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const updateTransferAccounts = `-- name: UpdateTransferAccounts :many
+UPDATE accounts
+   SET description = $2, value = $3, date = $4
+ WHERE transfer_id = $1
+RETURNING id, category_id, title, type, description, value, date, created_at, recurrence_id, installment_group_id, installment_number, wallet_id, transfer_id, ledger_id
+`
+
+type UpdateTransferAccountsParams struct {
+	TransferID  sql.NullInt32 `json:"transfer_id"`
+	Description string        `json:"description"`
+	Value       int32         `json:"value"`
+	Date        time.Time     `json:"date"`
+}
+
+func (q *Queries) UpdateTransferAccounts(ctx context.Context, arg UpdateTransferAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, updateTransferAccounts,
+		arg.TransferID,
+		arg.Description,
+		arg.Value,
+		arg.Date,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.CategoryID,
+			&i.Title,
+			&i.Type,
+			&i.Description,
+			&i.Value,
+			&i.Date,
+			&i.CreatedAt,
+			&i.RecurrenceID,
+			&i.InstallmentGroupID,
+			&i.InstallmentNumber,
+			&i.WalletID,
+			&i.TransferID,
+			&i.LedgerID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// DisableUserTx keeps a user from logging in and revokes their sessions.
+// Access tokens already issued keep working until they expire.
+func (store *SQLStore) DisableUserTx(ctx context.Context, userID int32) (User, error) {
+	return disableUserTx(ctx, store, userID)
+}
+
+func disableUserTx(ctx context.Context, store txRunner, userID int32) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		user, err = q.DisableUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeUserSessions(ctx, userID)
+	})
+
+	return user, err
+}
+
+type ForcePasswordResetTxParams struct {
+	UserID int32 `json:"user_id"`
+	// TokenHash is the hash of the reset token emailed to the user.
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ForcePasswordResetTx makes a user choose a new password: the current one
+// stops working, every session is revoked and a reset token is created.
+func (store *SQLStore) ForcePasswordResetTx(ctx context.Context, arg ForcePasswordResetTxParams) (User, error) {
+	return forcePasswordResetTx(ctx, store, arg)
+}
+
+func forcePasswordResetTx(ctx context.Context, store txRunner, arg ForcePasswordResetTxParams) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		// An empty hash is in no known format, so no password matches it.
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:       arg.UserID,
+			Password: "",
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.RevokeUserSessions(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreatePasswordResetToken(ctx, CreatePasswordResetTokenParams{
+			UserID:    arg.UserID,
+			TokenHash: arg.TokenHash,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return user, err
+}
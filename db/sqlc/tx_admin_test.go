@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	disabled, err := store.DisableUserTx(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.True(t, disabled.DisabledAt.Valid)
+
+	sessions, err := store.ListActiveSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	enabled, err := store.EnableUser(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.False(t, enabled.DisabledAt.Valid)
+}
+
+func TestForcePasswordResetTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	tokenHash := util.RandomString(32)
+	reset, err := store.ForcePasswordResetTx(context.Background(), ForcePasswordResetTxParams{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Empty(t, reset.Password)
+
+	sessions, err := store.ListActiveSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	resetToken, err := store.GetPasswordResetTokenByHash(context.Background(), tokenHash)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, resetToken.UserID)
+
+	_, err = store.ForcePasswordResetTx(context.Background(), ForcePasswordResetTxParams{
+		UserID:    user.ID + 1000000,
+		TokenHash: util.RandomString(32),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
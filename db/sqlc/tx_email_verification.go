@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrVerificationTokenInvalid is returned for verification tokens that do not
+// exist, have expired or were already used.
+var ErrVerificationTokenInvalid = errors.New("invalid or expired verification token")
+
+// VerifyEmailTx marks the email of the owner of a verification token as
+// verified and uses up every outstanding token of the user.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	return verifyEmailTx(ctx, store, tokenHash)
+}
+
+func verifyEmailTx(ctx context.Context, store txRunner, tokenHash string) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		verificationToken, err := q.GetEmailVerificationTokenByHash(ctx, tokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrVerificationTokenInvalid
+			}
+			return err
+		}
+		if verificationToken.UsedAt.Valid || !time.Now().Before(verificationToken.ExpiresAt) {
+			return ErrVerificationTokenInvalid
+		}
+
+		user, err = q.MarkUserEmailVerified(ctx, verificationToken.UserID)
+		if err != nil {
+			return err
+		}
+
+		return q.MarkEmailVerificationTokensUsed(ctx, verificationToken.UserID)
+	})
+
+	return user, err
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailVerificationToken(t *testing.T, user User, expiresAt time.Time) EmailVerificationToken {
+	arg := CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.RandomString(32),
+		ExpiresAt: expiresAt,
+	}
+
+	verificationToken, err := testQueries.CreateEmailVerificationToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, verificationToken.UserID)
+	require.Equal(t, arg.TokenHash, verificationToken.TokenHash)
+	require.False(t, verificationToken.UsedAt.Valid)
+
+	return verificationToken
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.EmailVerifiedAt.Valid)
+	verificationToken := createRandomEmailVerificationToken(t, user, time.Now().Add(time.Hour))
+	expired := createRandomEmailVerificationToken(t, createRandomUser(t), time.Now().Add(-time.Minute))
+
+	verified, err := store.VerifyEmailTx(context.Background(), verificationToken.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, verified.ID)
+	require.True(t, verified.EmailVerifiedAt.Valid)
+
+	_, err = store.VerifyEmailTx(context.Background(), verificationToken.TokenHash)
+	require.ErrorIs(t, err, ErrVerificationTokenInvalid)
+	_, err = store.VerifyEmailTx(context.Background(), expired.TokenHash)
+	require.ErrorIs(t, err, ErrVerificationTokenInvalid)
+	_, err = store.VerifyEmailTx(context.Background(), util.RandomString(32))
+	require.ErrorIs(t, err, ErrVerificationTokenInvalid)
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+)
+
+type CreateInstallmentsTxParams struct {
+	LedgerID         int32     `json:"ledger_id"`
+	CategoryID       int32     `json:"category_id"`
+	Title            string    `json:"title"`
+	Type             string    `json:"type"`
+	Description      string    `json:"description"`
+	TotalValue       int32     `json:"total_value"`
+	InstallmentCount int32     `json:"installment_count"`
+	FirstDate        time.Time `json:"first_date"`
+}
+
+type UpdateInstallmentsTxParams struct {
+	ID          int32  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	TotalValue  int32  `json:"total_value"`
+	LedgerID    int32  `json:"ledger_id"`
+}
+
+type InstallmentsTxResult struct {
+	Group    InstallmentGroup `json:"group"`
+	Accounts []Account        `json:"accounts"`
+}
+
+// CreateInstallmentsTx creates an installment group and one account per
+// installment, a month apart starting at the first date.
+func (store *SQLStore) CreateInstallmentsTx(ctx context.Context, arg CreateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	return createInstallmentsTx(ctx, store, arg)
+}
+
+func createInstallmentsTx(ctx context.Context, store txRunner, arg CreateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	var result InstallmentsTxResult
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+
+		result.Group, err = q.CreateInstallmentGroup(ctx, CreateInstallmentGroupParams(arg))
+		if err != nil {
+			return err
+		}
+
+		result.Accounts = make([]Account, 0, arg.InstallmentCount)
+		for n := int32(1); n <= arg.InstallmentCount; n++ {
+			acc, err := q.CreateInstallmentAccount(ctx, CreateInstallmentAccountParams{
+				LedgerID: arg.LedgerID,
+				CategoryID: sql.NullInt32{
+					Int32: arg.CategoryID,
+					Valid: true,
+				},
+				InstallmentGroupID: sql.NullInt32{
+					Int32: result.Group.ID,
+					Valid: true,
+				},
+				InstallmentNumber: sql.NullInt32{
+					Int32: n,
+					Valid: true,
+				},
+				Title:       InstallmentTitle(arg.Title, n, arg.InstallmentCount),
+				Type:        arg.Type,
+				Description: arg.Description,
+				Date:        util.AddMonths(arg.FirstDate, int(n-1)),
+				Value:       InstallmentValue(arg.TotalValue, n, arg.InstallmentCount),
+			})
+			if err != nil {
+				return err
+			}
+			result.Accounts = append(result.Accounts, acc)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// UpdateInstallmentsTx updates an installment group and rewrites the title,
+// description and value of every one of its accounts to match.
+func (store *SQLStore) UpdateInstallmentsTx(ctx context.Context, arg UpdateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	return updateInstallmentsTx(ctx, store, arg)
+}
+
+func updateInstallmentsTx(ctx context.Context, store txRunner, arg UpdateInstallmentsTxParams) (InstallmentsTxResult, error) {
+	var result InstallmentsTxResult
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+
+		result.Group, err = q.UpdateInstallmentGroup(ctx, UpdateInstallmentGroupParams(arg))
+		if err != nil {
+			return err
+		}
+
+		children, err := q.GetInstallmentAccounts(ctx, sql.NullInt32{
+			Int32: result.Group.ID,
+			Valid: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Accounts = make([]Account, 0, len(children))
+		for _, child := range children {
+			n := child.InstallmentNumber.Int32
+			acc, err := q.UpdateAccounts(ctx, UpdateAccountsParams{
+				ID:          child.ID,
+				Title:       InstallmentTitle(arg.Title, n, result.Group.InstallmentCount),
+				Description: arg.Description,
+				Value:       InstallmentValue(arg.TotalValue, n, result.Group.InstallmentCount),
+				LedgerID:    arg.LedgerID,
+			})
+			if err != nil {
+				return err
+			}
+			result.Accounts = append(result.Accounts, acc)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// InstallmentTitle numbers an installment as "title 3/12".
+func InstallmentTitle(title string, n, count int32) string {
+	return fmt.Sprintf("%s %d/%d", title, n, count)
+}
+
+// InstallmentValue splits total evenly across count installments; the cents
+// that do not divide evenly are added to the last one.
+func InstallmentValue(total int32, n, count int32) int32 {
+	value := total / count
+	if n == count {
+		value += total % count
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInstallments(t *testing.T, total, count int32) InstallmentsTxResult {
+	store := NewStore(testDB)
+	cat := createRandomCategory(t)
+
+	arg := CreateInstallmentsTxParams{
+		LedgerID:         cat.LedgerID,
+		CategoryID:       cat.ID,
+		Title:            util.RandomString(12),
+		Type:             cat.Type,
+		Description:      util.RandomString(20),
+		TotalValue:       total,
+		InstallmentCount: count,
+		FirstDate:        time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC),
+	}
+
+	result, err := store.CreateInstallmentsTx(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.TotalValue, result.Group.TotalValue)
+	require.Equal(t, arg.InstallmentCount, result.Group.InstallmentCount)
+	require.Len(t, result.Accounts, int(count))
+
+	return result
+}
+
+func TestCreateInstallmentsTx(t *testing.T) {
+	result := createRandomInstallments(t, 1000, 3)
+
+	var sum int32
+	for i, acc := range result.Accounts {
+		n := int32(i + 1)
+		require.Equal(t, result.Group.ID, acc.InstallmentGroupID.Int32)
+		require.Equal(t, n, acc.InstallmentNumber.Int32)
+		require.Equal(t, InstallmentTitle(result.Group.Title, n, 3), acc.Title)
+		sum += acc.Value
+	}
+
+	require.Equal(t, int32(333), result.Accounts[0].Value)
+	require.Equal(t, int32(334), result.Accounts[2].Value)
+	require.Equal(t, int32(1000), sum)
+	require.Equal(t, time.February, result.Accounts[1].Date.Month())
+	require.Equal(t, 28, result.Accounts[1].Date.Day())
+}
+
+func TestUpdateInstallmentsTx(t *testing.T) {
+	store := NewStore(testDB)
+	created := createRandomInstallments(t, 1000, 4)
+
+	arg := UpdateInstallmentsTxParams{
+		ID:          created.Group.ID,
+		Title:       util.RandomString(12),
+		Description: util.RandomString(20),
+		TotalValue:  2002,
+		LedgerID:    created.Group.LedgerID,
+	}
+
+	result, err := store.UpdateInstallmentsTx(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, result.Group.Title)
+	require.Len(t, result.Accounts, 4)
+	require.Equal(t, int32(500), result.Accounts[0].Value)
+	require.Equal(t, int32(502), result.Accounts[3].Value)
+	require.Equal(t, InstallmentTitle(arg.Title, 4, 4), result.Accounts[3].Title)
+}
+
+func TestDeleteInstallmentGroupCascades(t *testing.T) {
+	created := createRandomInstallments(t, 1200, 12)
+
+	err := testQueries.DeleteInstallmentGroup(context.Background(), DeleteInstallmentGroupParams{ID: created.Group.ID, LedgerID: created.Group.LedgerID})
+	require.NoError(t, err)
+
+	accs, err := testQueries.GetInstallmentAccounts(context.Background(), sql.NullInt32{
+		Int32: created.Group.ID,
+		Valid: true,
+	})
+	require.NoError(t, err)
+	require.Empty(t, accs)
+
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: created.Accounts[0].ID, LedgerID: created.Accounts[0].LedgerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
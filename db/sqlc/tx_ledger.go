@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// PersonalLedgerName is the name every user's personal ledger starts with.
+const PersonalLedgerName = "Personal"
+
+var (
+	// ErrInvitationInvalid is returned for invitations that do not exist, have
+	// expired or were already accepted. The cases are not told apart.
+	ErrInvitationInvalid = errors.New("invalid or expired invitation")
+	// ErrInvitationEmailMismatch is returned when an invitation is accepted by
+	// a user other than the one it was sent to.
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to another email address")
+	// ErrAlreadyLedgerMember is returned when the user accepting an
+	// invitation is a member of the ledger already.
+	ErrAlreadyLedgerMember = errors.New("already a member of this ledger")
+)
+
+// createLedgerWithOwner creates a ledger with its owner as its first member.
+func createLedgerWithOwner(ctx context.Context, q Querier, arg CreateLedgerParams) (Ledger, error) {
+	ledger, err := q.CreateLedger(ctx, arg)
+	if err != nil {
+		return ledger, err
+	}
+
+	_, err = q.AddLedgerMember(ctx, AddLedgerMemberParams{
+		LedgerID: ledger.ID,
+		UserID:   arg.OwnerID,
+		Role:     LedgerRoleOwner,
+	})
+	return ledger, err
+}
+
+type CreateLedgerTxParams struct {
+	Name    string `json:"name"`
+	OwnerID int32  `json:"owner_id"`
+}
+
+// CreateLedgerTx creates a shared ledger, with the user creating it as its
+// owner.
+func (store *SQLStore) CreateLedgerTx(ctx context.Context, arg CreateLedgerTxParams) (Ledger, error) {
+	return createLedgerTx(ctx, store, arg)
+}
+
+func createLedgerTx(ctx context.Context, store txRunner, arg CreateLedgerTxParams) (Ledger, error) {
+	var ledger Ledger
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		ledger, err = createLedgerWithOwner(ctx, q, CreateLedgerParams{
+			Name:    arg.Name,
+			OwnerID: arg.OwnerID,
+		})
+		return err
+	})
+
+	return ledger, err
+}
+
+// deleteLedgerData removes a ledger with all of its finance data, children first.
+// Members and invitations go with it through ON DELETE CASCADE.
+func deleteLedgerData(ctx context.Context, q Querier, ledgerID int32) error {
+	steps := []func(context.Context, int32) error{
+		q.DeleteLedgerAccounts,
+		q.DeleteLedgerInstallmentGroups,
+		q.DeleteLedgerRecurrences,
+		q.DeleteLedgerCategories,
+		q.DeleteLedger,
+	}
+	for _, step := range steps {
+		err := step(ctx, ledgerID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteLedgerTx removes a ledger with all of its finance data.
+func (store *SQLStore) DeleteLedgerTx(ctx context.Context, ledgerID int32) error {
+	return deleteLedgerTx(ctx, store, ledgerID)
+}
+
+func deleteLedgerTx(ctx context.Context, store txRunner, ledgerID int32) error {
+	return store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		return deleteLedgerData(ctx, q, ledgerID)
+	})
+}
+
+type AcceptLedgerInvitationTxParams struct {
+	TokenHash string `json:"token_hash"`
+	UserID    int32  `json:"user_id"`
+	// Email is the email of the user, which must be the one the invitation
+	// was sent to.
+	Email string `json:"email"`
+}
+
+// AcceptLedgerInvitationTx makes the user a member of the ledger of an
+// invitation, with the role they were invited as, and uses the invitation up.
+func (store *SQLStore) AcceptLedgerInvitationTx(ctx context.Context, arg AcceptLedgerInvitationTxParams) (LedgerMember, error) {
+	return acceptLedgerInvitationTx(ctx, store, arg)
+}
+
+func acceptLedgerInvitationTx(ctx context.Context, store txRunner, arg AcceptLedgerInvitationTxParams) (LedgerMember, error) {
+	var member LedgerMember
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		invitation, err := q.GetLedgerInvitationByHash(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrInvitationInvalid
+			}
+			return err
+		}
+		if invitation.AcceptedAt.Valid || !time.Now().Before(invitation.ExpiresAt) {
+			return ErrInvitationInvalid
+		}
+		if !strings.EqualFold(invitation.Email, arg.Email) {
+			return ErrInvitationEmailMismatch
+		}
+
+		_, err = q.GetLedgerMember(ctx, GetLedgerMemberParams{
+			LedgerID: invitation.LedgerID,
+			UserID:   arg.UserID,
+		})
+		if err == nil {
+			return ErrAlreadyLedgerMember
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		member, err = q.AddLedgerMember(ctx, AddLedgerMemberParams{
+			LedgerID: invitation.LedgerID,
+			UserID:   arg.UserID,
+			Role:     invitation.Role,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.MarkLedgerInvitationAccepted(ctx, invitation.ID)
+	})
+
+	return member, err
+}
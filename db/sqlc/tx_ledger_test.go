@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomLedgerInvitation(t *testing.T, ledger Ledger, email string, expiresAt time.Time) LedgerInvitation {
+	invitation, err := testQueries.CreateLedgerInvitation(context.Background(), CreateLedgerInvitationParams{
+		LedgerID:  ledger.ID,
+		Email:     email,
+		Role:      LedgerRoleViewer,
+		TokenHash: util.RandomString(64),
+		InvitedBy: sql.NullInt32{Int32: ledger.OwnerID, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	return invitation
+}
+
+func TestCreateLedgerTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+
+	ledger, err := store.CreateLedgerTx(context.Background(), CreateLedgerTxParams{Name: "Household", OwnerID: owner.ID})
+	require.NoError(t, err)
+	require.False(t, ledger.Personal)
+
+	member, err := testQueries.GetLedgerMember(context.Background(), GetLedgerMemberParams{LedgerID: ledger.ID, UserID: owner.ID})
+	require.NoError(t, err)
+	require.Equal(t, LedgerRoleOwner, member.Role)
+
+	ledgers, err := testQueries.ListUserLedgers(context.Background(), owner.ID)
+	require.NoError(t, err)
+	require.Len(t, ledgers, 2)
+	require.True(t, ledgers[0].Personal)
+	require.Equal(t, ledger.ID, ledgers[1].ID)
+}
+
+func TestAcceptLedgerInvitationTx(t *testing.T) {
+	store := NewStore(testDB)
+	owner := createRandomUser(t)
+	user := createRandomUser(t)
+	ledger, err := store.CreateLedgerTx(context.Background(), CreateLedgerTxParams{Name: "Household", OwnerID: owner.ID})
+	require.NoError(t, err)
+
+	expired := createRandomLedgerInvitation(t, ledger, user.Email, time.Now().Add(-time.Minute))
+	_, err = store.AcceptLedgerInvitationTx(context.Background(), AcceptLedgerInvitationTxParams{
+		TokenHash: expired.TokenHash, UserID: user.ID, Email: user.Email,
+	})
+	require.ErrorIs(t, err, ErrInvitationInvalid)
+
+	invitation := createRandomLedgerInvitation(t, ledger, strings.ToUpper(user.Email), time.Now().Add(time.Hour))
+	_, err = store.AcceptLedgerInvitationTx(context.Background(), AcceptLedgerInvitationTxParams{
+		TokenHash: invitation.TokenHash, UserID: owner.ID, Email: owner.Email,
+	})
+	require.ErrorIs(t, err, ErrInvitationEmailMismatch)
+
+	member, err := store.AcceptLedgerInvitationTx(context.Background(), AcceptLedgerInvitationTxParams{
+		TokenHash: invitation.TokenHash, UserID: user.ID, Email: user.Email,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ledger.ID, member.LedgerID)
+	require.Equal(t, LedgerRoleViewer, member.Role)
+
+	_, err = store.AcceptLedgerInvitationTx(context.Background(), AcceptLedgerInvitationTxParams{
+		TokenHash: invitation.TokenHash, UserID: user.ID, Email: user.Email,
+	})
+	require.ErrorIs(t, err, ErrInvitationInvalid)
+
+	again := createRandomLedgerInvitation(t, ledger, user.Email, time.Now().Add(time.Hour))
+	_, err = store.AcceptLedgerInvitationTx(context.Background(), AcceptLedgerInvitationTxParams{
+		TokenHash: again.TokenHash, UserID: user.ID, Email: user.Email,
+	})
+	require.ErrorIs(t, err, ErrAlreadyLedgerMember)
+}
+
+func TestDeleteLedgerTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	ledger, err := testQueries.GetLedger(context.Background(), account.LedgerID)
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteLedgerTx(context.Background(), ledger.ID))
+
+	_, err = testQueries.GetLedger(context.Background(), ledger.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: account.ID, LedgerID: ledger.ID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetLedgerMember(context.Background(), GetLedgerMemberParams{LedgerID: ledger.ID, UserID: ledger.OwnerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrResetTokenInvalid is returned for reset tokens that do not exist, have
+// expired or were already used. The cases are not told apart.
+var ErrResetTokenInvalid = errors.New("invalid or expired reset token")
+
+type ResetPasswordTxParams struct {
+	TokenHash string `json:"token_hash"`
+	// Password is the new password, already hashed.
+	Password string `json:"password"`
+}
+
+// ResetPasswordTx sets a new password for the owner of a reset token. The
+// token and every other outstanding token of the user are used up, and all
+// sessions of the user are revoked.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	return resetPasswordTx(ctx, store, arg)
+}
+
+func resetPasswordTx(ctx context.Context, store txRunner, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		resetToken, err := q.GetPasswordResetTokenByHash(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrResetTokenInvalid
+			}
+			return err
+		}
+		if resetToken.UsedAt.Valid || !time.Now().Before(resetToken.ExpiresAt) {
+			return ErrResetTokenInvalid
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:       resetToken.UserID,
+			Password: arg.Password,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.MarkPasswordResetTokensUsed(ctx, resetToken.UserID)
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeUserSessions(ctx, resetToken.UserID)
+	})
+
+	return user, err
+}
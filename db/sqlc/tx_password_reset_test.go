@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordResetToken(t *testing.T, user User, expiresAt time.Time) PasswordResetToken {
+	arg := CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: util.RandomString(32),
+		ExpiresAt: expiresAt,
+	}
+
+	resetToken, err := testQueries.CreatePasswordResetToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, resetToken.UserID)
+	require.Equal(t, arg.TokenHash, resetToken.TokenHash)
+	require.False(t, resetToken.UsedAt.Valid)
+
+	return resetToken
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+	first := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+	second := createRandomPasswordResetToken(t, user, time.Now().Add(time.Hour))
+
+	arg := ResetPasswordTxParams{
+		TokenHash: first.TokenHash,
+		Password:  util.RandomString(12),
+	}
+	updated, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.Equal(t, arg.Password, updated.Password)
+
+	revoked, err := testQueries.GetSessionByTokenHash(context.Background(), session.TokenHash)
+	require.NoError(t, err)
+	require.True(t, revoked.RevokedAt.Valid)
+
+	for _, used := range []PasswordResetToken{first, second} {
+		_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+			TokenHash: used.TokenHash,
+			Password:  util.RandomString(12),
+		})
+		require.ErrorIs(t, err, ErrResetTokenInvalid)
+	}
+}
+
+func TestResetPasswordTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	expired := createRandomPasswordResetToken(t, user, time.Now().Add(-time.Minute))
+
+	_, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		TokenHash: expired.TokenHash,
+		Password:  util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrResetTokenInvalid)
+
+	stored, err := testQueries.GetUserById(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, user.Password, stored.Password)
+}
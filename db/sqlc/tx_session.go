@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionExpired  = errors.New("session expired")
+	ErrSessionRevoked  = errors.New("session revoked")
+	ErrSessionReused   = errors.New("refresh token reused, session revoked")
+)
+
+type RotateSessionTxParams struct {
+	TokenHash    string    `json:"token_hash"`
+	NewTokenHash string    `json:"new_token_hash"`
+	NewExpiresAt time.Time `json:"new_expires_at"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+}
+
+// RotateSessionTx exchanges the refresh token behind TokenHash for a new one
+// in the same session family. A token can only be exchanged once: presenting
+// it again means it leaked, so the whole family is revoked and
+// ErrSessionReused is returned. The new session records the device that
+// refreshed it and keeps the login time of the family.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (Session, error) {
+	return rotateSessionTx(ctx, store, arg)
+}
+
+func rotateSessionTx(ctx context.Context, store txRunner, arg RotateSessionTxParams) (Session, error) {
+	var session Session
+	var reused bool
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		reused = false
+
+		old, err := q.GetSessionByTokenHash(ctx, arg.TokenHash)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSessionNotFound
+			}
+			return err
+		}
+		if old.RevokedAt.Valid {
+			return ErrSessionRevoked
+		}
+		if old.RotatedAt.Valid {
+			// The revocation has to be committed, so the error is only
+			// reported once the transaction is over.
+			reused = true
+			return q.RevokeSessionFamily(ctx, old.FamilyID)
+		}
+		if !time.Now().Before(old.ExpiresAt) {
+			return ErrSessionExpired
+		}
+
+		err = q.MarkSessionRotated(ctx, old.ID)
+		if err != nil {
+			return err
+		}
+
+		session, err = q.CreateSession(ctx, CreateSessionParams{
+			UserID:    old.UserID,
+			FamilyID:  old.FamilyID,
+			TokenHash: arg.NewTokenHash,
+			ExpiresAt: arg.NewExpiresAt,
+			UserAgent: arg.UserAgent,
+			ClientIp:  arg.ClientIp,
+			StartedAt: old.StartedAt,
+		})
+		return err
+	})
+	if err == nil && reused {
+		return session, ErrSessionReused
+	}
+
+	return session, err
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User, expiresAt time.Time) Session {
+	arg := CreateSessionParams{
+		UserID:    user.ID,
+		FamilyID:  util.RandomString(16),
+		TokenHash: util.RandomString(32),
+		ExpiresAt: expiresAt,
+		UserAgent: util.RandomString(12),
+		ClientIp:  "127.0.0.1",
+		StartedAt: time.Now(),
+	}
+
+	session, err := testQueries.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.UserID, session.UserID)
+	require.Equal(t, arg.FamilyID, session.FamilyID)
+	require.Equal(t, arg.TokenHash, session.TokenHash)
+	require.Equal(t, arg.UserAgent, session.UserAgent)
+	require.Equal(t, arg.ClientIp, session.ClientIp)
+	require.False(t, session.RotatedAt.Valid)
+	require.False(t, session.RevokedAt.Valid)
+
+	return session
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+
+	arg := RotateSessionTxParams{
+		TokenHash:    session.TokenHash,
+		NewTokenHash: util.RandomString(32),
+		NewExpiresAt: time.Now().Add(time.Hour),
+		UserAgent:    util.RandomString(12),
+		ClientIp:     "10.0.0.1",
+	}
+	rotated, err := store.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, session.FamilyID, rotated.FamilyID)
+	require.Equal(t, arg.NewTokenHash, rotated.TokenHash)
+	require.Equal(t, arg.UserAgent, rotated.UserAgent)
+	require.Equal(t, arg.ClientIp, rotated.ClientIp)
+	require.WithinDuration(t, session.StartedAt, rotated.StartedAt, time.Second)
+
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, rotated.ID, sessions[0].ID)
+
+	// Presenting the old token again revokes the whole family.
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		TokenHash:    session.TokenHash,
+		NewTokenHash: util.RandomString(32),
+		NewExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrSessionReused)
+
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		TokenHash:    rotated.TokenHash,
+		NewTokenHash: util.RandomString(32),
+		NewExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+func TestRotateExpiredSessionTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	session := createRandomSession(t, user, time.Now().Add(-time.Minute))
+
+	_, err := store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		TokenHash:    session.TokenHash,
+		NewTokenHash: util.RandomString(32),
+		NewExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrSessionExpired)
+
+	_, err = store.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		TokenHash:    util.RandomString(32),
+		NewTokenHash: util.RandomString(32),
+		NewExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrSessionNotFound)
+}
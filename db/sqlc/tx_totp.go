@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+)
+
+type ConfirmTOTPTxParams struct {
+	UserID int32 `json:"user_id"`
+	// Step is the time step of the code the user confirmed with, which
+	// cannot be used again.
+	Step int64 `json:"step"`
+	// RecoveryCodeHashes replace the recovery codes of the user.
+	RecoveryCodeHashes []string `json:"recovery_code_hashes"`
+}
+
+// ConfirmTOTPTx turns on two-factor authentication with the secret the user
+// enrolled, together with a fresh set of recovery codes.
+func (store *SQLStore) ConfirmTOTPTx(ctx context.Context, arg ConfirmTOTPTxParams) (UserTotp, error) {
+	return confirmTOTPTx(ctx, store, arg)
+}
+
+func confirmTOTPTx(ctx context.Context, store txRunner, arg ConfirmTOTPTxParams) (UserTotp, error) {
+	var totp UserTotp
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		totp, err = q.ConfirmTOTP(ctx, ConfirmTOTPParams{
+			UserID:       arg.UserID,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+
+		return replaceRecoveryCodes(ctx, q, arg.UserID, arg.RecoveryCodeHashes)
+	})
+
+	return totp, err
+}
+
+type ReplaceRecoveryCodesTxParams struct {
+	UserID     int32    `json:"user_id"`
+	CodeHashes []string `json:"code_hashes"`
+}
+
+// ReplaceRecoveryCodesTx swaps every recovery code of the user, used or not,
+// for a new set.
+func (store *SQLStore) ReplaceRecoveryCodesTx(ctx context.Context, arg ReplaceRecoveryCodesTxParams) error {
+	return replaceRecoveryCodesTx(ctx, store, arg)
+}
+
+func replaceRecoveryCodesTx(ctx context.Context, store txRunner, arg ReplaceRecoveryCodesTxParams) error {
+	return store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		return replaceRecoveryCodes(ctx, q, arg.UserID, arg.CodeHashes)
+	})
+}
+
+func replaceRecoveryCodes(ctx context.Context, q Querier, userID int32, codeHashes []string) error {
+	err := q.DeleteRecoveryCodes(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, codeHash := range codeHashes {
+		_, err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DisableTOTPTx turns two-factor authentication off, dropping the secret and
+// the recovery codes.
+func (store *SQLStore) DisableTOTPTx(ctx context.Context, userID int32) error {
+	return disableTOTPTx(ctx, store, userID)
+}
+
+func disableTOTPTx(ctx context.Context, store txRunner, userID int32) error {
+	return store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		err := q.DeleteTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+		return q.DeleteRecoveryCodes(ctx, userID)
+	})
+}
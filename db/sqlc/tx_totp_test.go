@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmTOTPTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	_, err := store.CreateTOTP(context.Background(), CreateTOTPParams{UserID: user.ID, Secret: util.RandomString(32)})
+	require.NoError(t, err)
+	// Enrolling again before confirming replaces the secret.
+	secret := util.RandomString(32)
+	_, err = store.CreateTOTP(context.Background(), CreateTOTPParams{UserID: user.ID, Secret: secret})
+	require.NoError(t, err)
+
+	hashes := []string{util.RandomString(32), util.RandomString(32)}
+	confirmed, err := store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{
+		UserID:             user.ID,
+		Step:               100,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	require.Equal(t, secret, confirmed.Secret)
+	require.True(t, confirmed.ConfirmedAt.Valid)
+	require.Equal(t, int64(100), confirmed.LastUsedStep)
+
+	count, err := store.CountUnusedRecoveryCodes(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// A confirmed secret is neither replaced nor confirmed again.
+	_, err = store.CreateTOTP(context.Background(), CreateTOTPParams{UserID: user.ID, Secret: util.RandomString(32)})
+	require.Error(t, err)
+	_, err = store.ConfirmTOTPTx(context.Background(), ConfirmTOTPTxParams{UserID: user.ID, Step: 200})
+	require.Error(t, err)
+
+	// Steps only move forward.
+	_, err = store.UseTOTPStep(context.Background(), UseTOTPStepParams{UserID: user.ID, LastUsedStep: 100})
+	require.Error(t, err)
+	_, err = store.UseTOTPStep(context.Background(), UseTOTPStepParams{UserID: user.ID, LastUsedStep: 101})
+	require.NoError(t, err)
+
+	_, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{UserID: user.ID, CodeHash: hashes[0]})
+	require.NoError(t, err)
+	_, err = store.UseRecoveryCode(context.Background(), UseRecoveryCodeParams{UserID: user.ID, CodeHash: hashes[0]})
+	require.Error(t, err)
+
+	err = store.DisableTOTPTx(context.Background(), user.ID)
+	require.NoError(t, err)
+	_, err = store.GetTOTP(context.Background(), user.ID)
+	require.Error(t, err)
+	count, err = store.CountUnusedRecoveryCodes(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
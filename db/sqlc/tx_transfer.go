@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type TransferTxParams struct {
+	UserID       int32     `json:"user_id"`
+	FromWalletID int32     `json:"from_wallet_id"`
+	ToWalletID   int32     `json:"to_wallet_id"`
+	Description  string    `json:"description"`
+	Value        int32     `json:"value"`
+	Date         time.Time `json:"date"`
+}
+
+type UpdateTransferTxParams struct {
+	ID          int32     `json:"id"`
+	Description string    `json:"description"`
+	Value       int32     `json:"value"`
+	Date        time.Time `json:"date"`
+	UserID      int32     `json:"user_id"`
+}
+
+type TransferTxResult struct {
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+	ToAccount   Account  `json:"to_account"`
+}
+
+// TransferTx records a transfer between two wallets as a debit on the source
+// wallet and a credit on the destination, both linked to the transfer row.
+func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return transferTx(ctx, store, arg)
+}
+
+func transferTx(ctx context.Context, store txRunner, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+
+		from, err := q.GetWallet(ctx, GetWalletParams{ID: arg.FromWalletID, UserID: arg.UserID})
+		if err != nil {
+			return err
+		}
+		to, err := q.GetWallet(ctx, GetWalletParams{ID: arg.ToWalletID, UserID: arg.UserID})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		if err != nil {
+			return err
+		}
+
+		transferID := sql.NullInt32{Int32: result.Transfer.ID, Valid: true}
+
+		result.FromAccount, err = q.CreateTransferAccount(ctx, CreateTransferAccountParams{
+			UserID:      arg.UserID,
+			WalletID:    sql.NullInt32{Int32: from.ID, Valid: true},
+			TransferID:  transferID,
+			Title:       fmt.Sprintf("Transfer to %s", to.Name),
+			Type:        "debit",
+			Description: arg.Description,
+			Date:        arg.Date,
+			Value:       arg.Value,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToAccount, err = q.CreateTransferAccount(ctx, CreateTransferAccountParams{
+			UserID:      arg.UserID,
+			WalletID:    sql.NullInt32{Int32: to.ID, Valid: true},
+			TransferID:  transferID,
+			Title:       fmt.Sprintf("Transfer from %s", from.Name),
+			Type:        "credit",
+			Description: arg.Description,
+			Date:        arg.Date,
+			Value:       arg.Value,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateTransferTx changes a transfer and both of its accounts together.
+func (store *SQLStore) UpdateTransferTx(ctx context.Context, arg UpdateTransferTxParams) (TransferTxResult, error) {
+	return updateTransferTx(ctx, store, arg)
+}
+
+func updateTransferTx(ctx context.Context, store txRunner, arg UpdateTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+
+		result.Transfer, err = q.UpdateTransfer(ctx, UpdateTransferParams(arg))
+		if err != nil {
+			return err
+		}
+
+		accs, err := q.UpdateTransferAccounts(ctx, UpdateTransferAccountsParams{
+			TransferID:  sql.NullInt32{Int32: result.Transfer.ID, Valid: true},
+			Description: arg.Description,
+			Value:       arg.Value,
+			Date:        arg.Date,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, result.ToAccount = transferLegs(result.Transfer, accs)
+		return nil
+	})
+
+	return result, err
+}
+
+// GetTransferDetails loads a transfer of the given user together with both of
+// its accounts.
+func (store *SQLStore) GetTransferDetails(ctx context.Context, arg GetTransferParams) (TransferTxResult, error) {
+	return getTransferDetails(ctx, store, arg)
+}
+
+func getTransferDetails(ctx context.Context, q Querier, arg GetTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.GetTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	accs, err := q.GetTransferAccounts(ctx, sql.NullInt32{Int32: result.Transfer.ID, Valid: true})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, result.ToAccount = transferLegs(result.Transfer, accs)
+	return result, nil
+}
+
+func transferLegs(transfer Transfer, accs []Account) (from Account, to Account) {
+	for _, acc := range accs {
+		if acc.WalletID.Int32 == transfer.FromWalletID {
+			from = acc
+		} else {
+			to = acc
+		}
+	}
+	return from, to
+}
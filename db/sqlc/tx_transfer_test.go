@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomTransfer(t *testing.T) (TransferTxResult, Wallet, Wallet) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	from := createRandomWallet(t, user)
+	to := createRandomWallet(t, user)
+
+	arg := TransferTxParams{
+		UserID:       user.ID,
+		FromWalletID: from.ID,
+		ToWalletID:   to.ID,
+		Description:  util.RandomString(20),
+		Value:        250,
+		Date:         time.Now(),
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Value, result.Transfer.Value)
+	require.Equal(t, result.Transfer.ID, result.FromAccount.TransferID.Int32)
+	require.Equal(t, result.Transfer.ID, result.ToAccount.TransferID.Int32)
+	require.Equal(t, "debit", result.FromAccount.Type)
+	require.Equal(t, "credit", result.ToAccount.Type)
+	require.Equal(t, from.ID, result.FromAccount.WalletID.Int32)
+	require.Equal(t, to.ID, result.ToAccount.WalletID.Int32)
+	require.False(t, result.FromAccount.CategoryID.Valid)
+
+	// The legs go to the personal ledger of the user.
+	ledger, err := testQueries.GetPersonalLedger(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Equal(t, ledger.ID, result.FromAccount.LedgerID)
+	require.Equal(t, ledger.ID, result.ToAccount.LedgerID)
+
+	return result, from, to
+}
+
+func TestTransferTx(t *testing.T) {
+	result, from, to := createRandomTransfer(t)
+
+	fromBalance, err := testQueries.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+		WalletID: from.ID,
+		UserID:   from.UserID,
+		Date:     time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(from.OpeningBalance-result.Transfer.Value), fromBalance.Balance)
+
+	toBalance, err := testQueries.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+		WalletID: to.ID,
+		UserID:   to.UserID,
+		Date:     time.Now(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(to.OpeningBalance+result.Transfer.Value), toBalance.Balance)
+
+	points, err := testQueries.GetAccountGraph(context.Background(), GetAccountGraphParams{
+		Granularity: "month",
+		StartDate:   result.FromAccount.Date,
+		EndDate:     result.FromAccount.Date,
+		LedgerID:    result.FromAccount.LedgerID,
+	})
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	require.Zero(t, points[0].CumulativeExpense)
+}
+
+func TestUpdateTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	created, _, _ := createRandomTransfer(t)
+
+	arg := UpdateTransferTxParams{
+		ID:          created.Transfer.ID,
+		Description: util.RandomString(20),
+		Value:       900,
+		Date:        time.Now().AddDate(0, 0, -3),
+		UserID:      created.Transfer.UserID,
+	}
+
+	result, err := store.UpdateTransferTx(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Value, result.Transfer.Value)
+	require.Equal(t, arg.Value, result.FromAccount.Value)
+	require.Equal(t, arg.Value, result.ToAccount.Value)
+	require.Equal(t, arg.Description, result.ToAccount.Description)
+	require.Equal(t, created.FromAccount.ID, result.FromAccount.ID)
+}
+
+func TestDeleteTransferCascades(t *testing.T) {
+	created, _, _ := createRandomTransfer(t)
+
+	err := testQueries.DeleteTransfer(context.Background(), DeleteTransferParams{ID: created.Transfer.ID, UserID: created.Transfer.UserID})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: created.FromAccount.ID, LedgerID: created.FromAccount.LedgerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: created.ToAccount.ID, LedgerID: created.ToAccount.LedgerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
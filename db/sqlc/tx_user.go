@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+)
+
+type ChangePasswordTxParams struct {
+	UserID int32 `json:"user_id"`
+	// Password is the new password, already hashed.
+	Password string `json:"password"`
+}
+
+// ChangePasswordTx sets a new password and revokes every session of the
+// user, so devices logged in with the old password have to log in again.
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	return changePasswordTx(ctx, store, arg)
+}
+
+func changePasswordTx(ctx context.Context, store txRunner, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			ID:       arg.UserID,
+			Password: arg.Password,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.RevokeUserSessions(ctx, arg.UserID)
+	})
+
+	return user, err
+}
+
+type ChangeEmailTxParams struct {
+	UserID int32  `json:"user_id"`
+	Email  string `json:"email"`
+}
+
+// ChangeEmailTx replaces the email of a user, which has to be verified again.
+// Verification tokens sent to the old address stop working.
+func (store *SQLStore) ChangeEmailTx(ctx context.Context, arg ChangeEmailTxParams) (User, error) {
+	return changeEmailTx(ctx, store, arg)
+}
+
+func changeEmailTx(ctx context.Context, store txRunner, arg ChangeEmailTxParams) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		user, err = q.UpdateUserEmail(ctx, UpdateUserEmailParams{
+			ID:    arg.UserID,
+			Email: arg.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.MarkEmailVerificationTokensUsed(ctx, arg.UserID)
+	})
+
+	return user, err
+}
+
+// CreateUserTx signs a user up with their personal ledger.
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	return createUserTx(ctx, store, arg)
+}
+
+func createUserTx(ctx context.Context, store txRunner, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = createLedgerWithOwner(ctx, q, CreateLedgerParams{
+			Name:     PersonalLedgerName,
+			OwnerID:  user.ID,
+			Personal: true,
+		})
+		return err
+	})
+
+	return user, err
+}
+
+// DeleteUserTx removes a user with their wallets, their transfers and the
+// ledgers they own with all of their finance data. Rows are deleted children
+// first, so the foreign keys without ON DELETE actions are satisfied;
+// sessions, tokens and memberships of other ledgers go with the user through
+// ON DELETE CASCADE. What they added to the ledgers of others stays there.
+func (store *SQLStore) DeleteUserTx(ctx context.Context, userID int32) error {
+	return deleteUserTx(ctx, store, userID)
+}
+
+func deleteUserTx(ctx context.Context, store txRunner, userID int32) error {
+	return store.ExecTx(ctx, TxOptions{}, func(q Querier) error {
+		ledgers, err := q.ListUserLedgers(ctx, userID)
+		if err != nil {
+			return err
+		}
+		for _, ledger := range ledgers {
+			if ledger.OwnerID != userID {
+				continue
+			}
+			err = deleteLedgerData(ctx, q, ledger.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		steps := []func(context.Context, int32) error{
+			q.DeleteUserTransfers,
+			q.DeleteUserWallets,
+			q.DeleteUser,
+		}
+		for _, step := range steps {
+			err := step(ctx, userID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
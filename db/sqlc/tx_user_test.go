@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	ledger, err := testQueries.GetLedger(context.Background(), account.LedgerID)
+	require.NoError(t, err)
+	userID := ledger.OwnerID
+	user, err := testQueries.GetUserById(context.Background(), userID)
+	require.NoError(t, err)
+	wallet := createRandomWallet(t, user)
+	session := createRandomSession(t, user, time.Now().Add(time.Hour))
+	other := createRandomAccount(t)
+
+	require.NoError(t, store.DeleteUserTx(context.Background(), userID))
+
+	_, err = testQueries.GetUserById(context.Background(), userID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: account.ID, LedgerID: account.LedgerID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetWallet(context.Background(), GetWalletParams{ID: wallet.ID, UserID: userID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetSessionByTokenHash(context.Background(), session.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testQueries.GetLedger(context.Background(), ledger.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.GetAccount(context.Background(), GetAccountParams{ID: other.ID, LedgerID: other.LedgerID})
+	require.NoError(t, err)
+}
+
+func TestChangeEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	verificationToken := createRandomEmailVerificationToken(t, user, time.Now().Add(time.Hour))
+	other := createRandomUser(t)
+
+	_, err := store.ChangeEmailTx(context.Background(), ChangeEmailTxParams{UserID: user.ID, Email: other.Email})
+	require.True(t, IsUniqueViolation(err))
+
+	email := util.RandomEmail()
+	updated, err := store.ChangeEmailTx(context.Background(), ChangeEmailTxParams{UserID: user.ID, Email: email})
+	require.NoError(t, err)
+	require.Equal(t, email, updated.Email)
+	require.False(t, updated.EmailVerifiedAt.Valid)
+
+	_, err = store.VerifyEmailTx(context.Background(), verificationToken.TokenHash)
+	require.ErrorIs(t, err, ErrVerificationTokenInvalid)
+}
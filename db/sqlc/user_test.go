@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/methyago/gofinance-backend/util"
@@ -15,7 +16,7 @@ func createRandomUser(t *testing.T) User {
 		Email:    util.RandomEmail(),
 	}
 
-	user, err := testQueries.CreateUser(context.Background(), arg)
+	user, err := NewStore(testDB).CreateUserTx(context.Background(), arg)
 
 	require.NoError(t, err)
 	require.NotEmpty(t, user)
@@ -55,3 +56,20 @@ func TestGetUserById(t *testing.T) {
 	require.Equal(t, user1.Email, user2.Email)
 	require.NotEmpty(t, user2.CreatedAt)
 }
+
+func TestCreateUserUsernameCaseInsensitive(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateUser(context.Background(), CreateUserParams{
+		Username: strings.ToUpper(user.Username),
+		Password: util.RandomString(12),
+		Email:    util.RandomEmail(),
+	})
+	constraint, ok := UniqueViolation(err)
+	require.True(t, ok)
+	require.Equal(t, "users_username_lower_idx", constraint)
+
+	found, err := testQueries.GetUser(context.Background(), strings.ToUpper(user.Username))
+	require.NoError(t, err)
+	require.Equal(t, user.ID, found.ID)
+}
@@ -0,0 +1,216 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.20.0
+// source: wallet.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createWallet = `-- name: CreateWallet :one
+INSERT INTO wallets (
+    user_id,
+    name,
+    type,
+    currency,
+    opening_balance
+) VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, type, currency, opening_balance, created_at
+`
+
+type CreateWalletParams struct {
+	UserID         int32  `json:"user_id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Currency       string `json:"currency"`
+	OpeningBalance int32  `json:"opening_balance"`
+}
+
+func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
+	row := q.db.QueryRowContext(ctx, createWallet,
+		arg.UserID,
+		arg.Name,
+		arg.Type,
+		arg.Currency,
+		arg.OpeningBalance,
+	)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Type,
+		&i.Currency,
+		&i.OpeningBalance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteUserWallets = `-- name: DeleteUserWallets :exec
+DELETE FROM wallets WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserWallets(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUserWallets, userID)
+	return err
+}
+
+const deleteWallet = `-- name: DeleteWallet :exec
+DELETE FROM wallets WHERE id = $1 AND user_id = $2
+`
+
+type DeleteWalletParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) DeleteWallet(ctx context.Context, arg DeleteWalletParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWallet, arg.ID, arg.UserID)
+	return err
+}
+
+const getWallet = `-- name: GetWallet :one
+SELECT id, user_id, name, type, currency, opening_balance, created_at FROM wallets WHERE id = $1 AND user_id = $2 LIMIT 1
+`
+
+type GetWalletParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"user_id"`
+}
+
+func (q *Queries) GetWallet(ctx context.Context, arg GetWalletParams) (Wallet, error) {
+	row := q.db.QueryRowContext(ctx, getWallet, arg.ID, arg.UserID)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Type,
+		&i.Currency,
+		&i.OpeningBalance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getWalletBalance = `-- name: GetWalletBalance :one
+SELECT w.id AS wallet_id,
+       w.opening_balance,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)::bigint AS income,
+       COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0)::bigint AS expense,
+       (w.opening_balance
+         + COALESCE(SUM(a.value) FILTER (WHERE a.type = 'credit'), 0)
+         - COALESCE(SUM(a.value) FILTER (WHERE a.type = 'debit'), 0))::bigint AS balance
+  FROM wallets w
+  LEFT JOIN accounts a ON a.wallet_id = w.id AND a.date <= $1::date
+ WHERE w.id = $2 AND w.user_id = $3
+ GROUP BY w.id
+`
+
+type GetWalletBalanceParams struct {
+	Date     time.Time `json:"date"`
+	WalletID int32     `json:"wallet_id"`
+	UserID   int32     `json:"user_id"`
+}
+
+type GetWalletBalanceRow struct {
+	WalletID       int32 `json:"wallet_id"`
+	OpeningBalance int32 `json:"opening_balance"`
+	Income         int64 `json:"income"`
+	Expense        int64 `json:"expense"`
+	Balance        int64 `json:"balance"`
+}
+
+// Accounts of type 'credit' are income and add to the balance, 'debit' are
+// expenses and subtract from it.
+func (q *Queries) GetWalletBalance(ctx context.Context, arg GetWalletBalanceParams) (GetWalletBalanceRow, error) {
+	row := q.db.QueryRowContext(ctx, getWalletBalance, arg.Date, arg.WalletID, arg.UserID)
+	var i GetWalletBalanceRow
+	err := row.Scan(
+		&i.WalletID,
+		&i.OpeningBalance,
+		&i.Income,
+		&i.Expense,
+		&i.Balance,
+	)
+	return i, err
+}
+
+const getWallets = `-- name: GetWallets :many
+SELECT id, user_id, name, type, currency, opening_balance, created_at FROM wallets
+ WHERE user_id = $1
+ ORDER BY name, id
+`
+
+func (q *Queries) GetWallets(ctx context.Context, userID int32) ([]Wallet, error) {
+	rows, err := q.db.QueryContext(ctx, getWallets, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Wallet{}
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Type,
+			&i.Currency,
+			&i.OpeningBalance,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateWallet = `-- name: UpdateWallet :one
+UPDATE wallets
+   SET name = $2, type = $3, currency = $4, opening_balance = $5
+ WHERE id = $1 AND user_id = $6
+RETURNING id, user_id, name, type, currency, opening_balance, created_at
+`
+
+type UpdateWalletParams struct {
+	ID             int32  `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Currency       string `json:"currency"`
+	OpeningBalance int32  `json:"opening_balance"`
+	UserID         int32  `json:"user_id"`
+}
+
+func (q *Queries) UpdateWallet(ctx context.Context, arg UpdateWalletParams) (Wallet, error) {
+	row := q.db.QueryRowContext(ctx, updateWallet,
+		arg.ID,
+		arg.Name,
+		arg.Type,
+		arg.Currency,
+		arg.OpeningBalance,
+		arg.UserID,
+	)
+	var i Wallet
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Type,
+		&i.Currency,
+		&i.OpeningBalance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWallet(t *testing.T, user User) Wallet {
+	arg := CreateWalletParams{
+		UserID:         user.ID,
+		Name:           util.RandomString(8),
+		Type:           "checking",
+		Currency:       "BRL",
+		OpeningBalance: 1000,
+	}
+
+	wallet, err := testQueries.CreateWallet(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, wallet)
+	require.Equal(t, arg.UserID, wallet.UserID)
+	require.Equal(t, arg.Name, wallet.Name)
+	require.Equal(t, arg.Type, wallet.Type)
+	require.Equal(t, arg.Currency, wallet.Currency)
+	require.Equal(t, arg.OpeningBalance, wallet.OpeningBalance)
+
+	return wallet
+}
+
+func TestCreateWallet(t *testing.T) {
+	createRandomWallet(t, createRandomUser(t))
+}
+
+func TestGetWallet(t *testing.T) {
+	wallet1 := createRandomWallet(t, createRandomUser(t))
+	wallet2, err := testQueries.GetWallet(context.Background(), GetWalletParams{ID: wallet1.ID, UserID: wallet1.UserID})
+
+	require.NoError(t, err)
+	require.Equal(t, wallet1.ID, wallet2.ID)
+	require.Equal(t, wallet1.Name, wallet2.Name)
+	require.NotEmpty(t, wallet2.CreatedAt)
+}
+
+func TestUpdateWallet(t *testing.T) {
+	wallet1 := createRandomWallet(t, createRandomUser(t))
+
+	arg := UpdateWalletParams{
+		ID:             wallet1.ID,
+		Name:           util.RandomString(8),
+		Type:           "savings",
+		Currency:       "USD",
+		OpeningBalance: 50,
+		UserID:         wallet1.UserID,
+	}
+
+	wallet2, err := testQueries.UpdateWallet(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, wallet2.Name)
+	require.Equal(t, arg.Type, wallet2.Type)
+	require.Equal(t, arg.Currency, wallet2.Currency)
+	require.Equal(t, arg.OpeningBalance, wallet2.OpeningBalance)
+}
+
+func TestDeleteWallet(t *testing.T) {
+	wallet := createRandomWallet(t, createRandomUser(t))
+	err := testQueries.DeleteWallet(context.Background(), DeleteWalletParams{ID: wallet.ID, UserID: wallet.UserID})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetWallet(context.Background(), GetWalletParams{ID: wallet.ID, UserID: wallet.UserID})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetWalletBalance(t *testing.T) {
+	cat := createRandomCategory(t)
+	ledger, err := testQueries.GetLedger(context.Background(), cat.LedgerID)
+	require.NoError(t, err)
+	user, err := testQueries.GetUserById(context.Background(), ledger.OwnerID)
+	require.NoError(t, err)
+	wallet := createRandomWallet(t, user)
+
+	today := time.Now()
+	entries := []struct {
+		accountType string
+		value       int32
+		date        time.Time
+	}{
+		{"credit", 500, today.AddDate(0, 0, -2)},
+		{"debit", 200, today.AddDate(0, 0, -1)},
+		{"debit", 100, today.AddDate(0, 0, 1)},
+	}
+	for _, entry := range entries {
+		_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			LedgerID: cat.LedgerID,
+			CategoryID: sql.NullInt32{
+				Int32: cat.ID,
+				Valid: true,
+			},
+			Title:       util.RandomString(12),
+			Type:        entry.accountType,
+			Description: util.RandomString(20),
+			Date:        entry.date,
+			Value:       entry.value,
+			WalletID: sql.NullInt32{
+				Int32: wallet.ID,
+				Valid: true,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	balance, err := testQueries.GetWalletBalance(context.Background(), GetWalletBalanceParams{
+		WalletID: wallet.ID,
+		UserID:   wallet.UserID,
+		Date:     today,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, wallet.ID, balance.WalletID)
+	require.Equal(t, int64(500), balance.Income)
+	require.Equal(t, int64(200), balance.Expense)
+	require.Equal(t, int64(1300), balance.Balance)
+}
@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buf bytes.Buffer
+	mailer := NewLogMailer(&buf, "noreply@example.com")
+
+	err := mailer.Send(context.Background(), Message{To: "alice@example.com", Subject: "Hello", Body: "line 1\nline 2"})
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "From: noreply@example.com\nTo: alice@example.com\nSubject: Hello\n\nline 1\nline 2\n")
+}
+
+func TestSMTPMessageFormat(t *testing.T) {
+	mailer := NewSMTPMailer(util.MailConfig{From: "noreply@example.com", SMTPHost: "localhost", SMTPPort: 25})
+
+	data, err := mailer.format(Message{To: "alice@example.com", Subject: "Olá", Body: "line 1\nline 2"})
+	require.NoError(t, err)
+	message := string(data)
+	require.True(t, strings.HasPrefix(message, "From: noreply@example.com\r\nTo: alice@example.com\r\nSubject: =?utf-8?q?Ol=C3=A1?=\r\n"))
+	require.True(t, strings.HasSuffix(message, "\r\n\r\nline 1\r\nline 2"))
+
+	_, err = mailer.format(Message{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "Hello"})
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	mailer, err := New(util.MailConfig{Driver: DriverSMTP, SMTPHost: "localhost", SMTPPort: 25})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPMailer{}, mailer)
+
+	mailer, err = New(util.MailConfig{})
+	require.NoError(t, err)
+	require.IsType(t, &LogMailer{}, mailer)
+
+	_, err = New(util.MailConfig{Driver: "pigeon"})
+	require.Error(t, err)
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
-	"os"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 	api "github.com/methyago/gofinance-backend/api"
 	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/recurring"
+	"github.com/methyago/gofinance-backend/util"
 )
 
 func main() {
@@ -17,18 +19,25 @@ func main() {
 		log.Fatal("cannot load env: ", err)
 	}
 
-	dbDriver := os.Getenv("DB_DRIVER")
-	dbSource := os.Getenv("DB_SOURCE")
-	serverAddress := os.Getenv("SERVER_ADDRESS")
+	config, err := util.LoadConfig()
+	if err != nil {
+		log.Fatal("cannot load config: ", err)
+	}
 
-	conn, err := sql.Open(dbDriver, dbSource)
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to db: ", err)
 	}
 
 	store := db.NewStore(conn)
-	server := api.NewServer(store)
-	err = server.Start(serverAddress)
+	go recurring.NewMaterializer(store, config.RecurrenceInterval).Start(context.Background())
+
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create server: ", err)
+	}
+
+	err = server.Start(config.ServerAddress)
 	if err != nil {
 		log.Fatal("cannot start api: ", err)
 	}
@@ -0,0 +1,98 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testParams = Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestHashAndVerify(t *testing.T) {
+	hasher, err := NewHasher(testParams)
+	require.NoError(t, err)
+	password := util.RandomString(12)
+
+	encoded, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	other, err := hasher.Hash(password)
+	require.NoError(t, err)
+	require.NotEqual(t, encoded, other, "salts must differ")
+
+	needsRehash, err := hasher.Verify(password, encoded)
+	require.NoError(t, err)
+	require.False(t, needsRehash)
+
+	_, err = hasher.Verify(util.RandomString(12), encoded)
+	require.ErrorIs(t, err, ErrMismatch)
+}
+
+func TestVerifyWithOtherParams(t *testing.T) {
+	old, err := NewHasher(testParams)
+	require.NoError(t, err)
+	encoded, err := old.Hash("correct horse")
+	require.NoError(t, err)
+
+	stronger := testParams
+	stronger.Iterations = 2
+	hasher, err := NewHasher(stronger)
+	require.NoError(t, err)
+
+	needsRehash, err := hasher.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	require.True(t, needsRehash)
+}
+
+func TestVerifyLegacyBcrypt(t *testing.T) {
+	hasher, err := NewHasher(testParams)
+	require.NoError(t, err)
+	password := util.RandomString(12)
+
+	legacy, err := bcrypt.GenerateFromPassword(legacyDigest(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	needsRehash, err := hasher.Verify(password, string(legacy))
+	require.NoError(t, err)
+	require.True(t, needsRehash)
+
+	_, err = hasher.Verify(util.RandomString(12), string(legacy))
+	require.ErrorIs(t, err, ErrMismatch)
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	hasher, err := NewHasher(testParams)
+	require.NoError(t, err)
+
+	for _, encoded := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ",
+	} {
+		_, err := hasher.Verify("password", encoded)
+		require.ErrorIs(t, err, ErrUnknownFormat, encoded)
+	}
+}
+
+func TestNewHasherParams(t *testing.T) {
+	_, err := NewHasher(DefaultParams)
+	require.NoError(t, err)
+
+	for _, params := range []Params{
+		{Memory: 1024, Iterations: 0, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		{Memory: 4, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32},
+		{Memory: 1024, Iterations: 1, Parallelism: 0, SaltLength: 16, KeyLength: 32},
+		{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 4, KeyLength: 32},
+	} {
+		_, err := NewHasher(params)
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,101 @@
+package recurring
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+// Materializer turns recurrence rules into account rows once they fall due.
+// Inserts go through CreateRecurrenceAccount, which ignores an occurrence that
+// already exists, so running it twice for the same day is harmless.
+type Materializer struct {
+	store    db.Store
+	interval time.Duration
+}
+
+func NewMaterializer(store db.Store, interval time.Duration) *Materializer {
+	return &Materializer{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Start runs the materializer right away and then on every tick until ctx is done.
+func (m *Materializer) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		err := m.Run(ctx, time.Now())
+		if err != nil {
+			log.Println("cannot materialize recurrences: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run creates every occurrence due up to and including today.
+func (m *Materializer) Run(ctx context.Context, today time.Time) error {
+	today = Day(today)
+
+	rules, err := m.store.GetPendingRecurrences(ctx, today)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		err = m.materialize(ctx, rule, today)
+		if err != nil {
+			log.Printf("cannot materialize recurrence %d: %v", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+func (m *Materializer) materialize(ctx context.Context, rule db.Recurrence, today time.Time) error {
+	from := rule.StartDate
+	if rule.MaterializedUntil.Valid {
+		from = rule.MaterializedUntil.Time.AddDate(0, 0, 1)
+	}
+
+	for _, date := range Occurrences(rule, from, today, 0) {
+		arg := db.CreateRecurrenceAccountParams{
+			LedgerID: rule.LedgerID,
+			CategoryID: sql.NullInt32{
+				Int32: rule.CategoryID,
+				Valid: true,
+			},
+			RecurrenceID: sql.NullInt32{
+				Int32: rule.ID,
+				Valid: true,
+			},
+			Title:       rule.Title,
+			Type:        rule.Type,
+			Description: rule.Description,
+			Date:        date,
+			Value:       rule.Value,
+		}
+
+		_, err := m.store.CreateRecurrenceAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.store.SetRecurrenceMaterializedUntil(ctx, db.SetRecurrenceMaterializedUntilParams{
+		ID: rule.ID,
+		MaterializedUntil: sql.NullTime{
+			Time:  today,
+			Valid: true,
+		},
+	})
+}
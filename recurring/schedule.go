@@ -0,0 +1,87 @@
+package recurring
+
+import (
+	"time"
+
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+	FrequencyYearly  = "yearly"
+)
+
+// Day truncates t to midnight UTC, which is how Postgres hands back a date column.
+func Day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Nth returns the n-th occurrence (zero based) of the rule. Every occurrence is
+// computed from the start date, so a rule starting on the 31st lands on the last
+// day of shorter months without drifting to the 28th afterwards. Rules with an
+// unknown frequency or an interval below one have no occurrences, which Nth
+// reports as the zero time.
+func Nth(rule db.Recurrence, n int) time.Time {
+	if rule.Interval < 1 {
+		return time.Time{}
+	}
+	start := Day(rule.StartDate)
+	step := n * int(rule.Interval)
+
+	switch rule.Frequency {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, step)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*step)
+	case FrequencyMonthly:
+		return util.AddMonths(start, step)
+	case FrequencyYearly:
+		return util.AddMonths(start, 12*step)
+	}
+	return time.Time{}
+}
+
+// Occurrences lists the dates of the rule that fall within [from, to], honouring
+// the rule's end date and occurrence count. A zero to leaves the range open and
+// a positive limit caps the number of dates returned; an open-ended rule needs
+// at least one of the two, otherwise nothing is returned.
+func Occurrences(rule db.Recurrence, from, to time.Time, limit int) []time.Time {
+	dates := []time.Time{}
+	if to.IsZero() && limit <= 0 && !rule.EndDate.Valid && !rule.Occurrences.Valid {
+		return dates
+	}
+
+	from = Day(from)
+	if !to.IsZero() {
+		to = Day(to)
+	}
+
+	for n := 0; ; n++ {
+		if rule.Occurrences.Valid && n >= int(rule.Occurrences.Int32) {
+			break
+		}
+
+		date := Nth(rule, n)
+		if date.IsZero() {
+			break
+		}
+		if !to.IsZero() && date.After(to) {
+			break
+		}
+		if rule.EndDate.Valid && date.After(Day(rule.EndDate.Time)) {
+			break
+		}
+		if date.Before(from) {
+			continue
+		}
+
+		dates = append(dates, date)
+		if limit > 0 && len(dates) >= limit {
+			break
+		}
+	}
+	return dates
+}
@@ -0,0 +1,84 @@
+package recurring
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrencesMonthlyClampsToMonthEnd(t *testing.T) {
+	rule := db.Recurrence{
+		Frequency: FrequencyMonthly,
+		Interval:  1,
+		StartDate: date(2023, time.January, 31),
+	}
+
+	dates := Occurrences(rule, rule.StartDate, date(2023, time.April, 30), 0)
+
+	require.Equal(t, []time.Time{
+		date(2023, time.January, 31),
+		date(2023, time.February, 28),
+		date(2023, time.March, 31),
+		date(2023, time.April, 30),
+	}, dates)
+}
+
+func TestOccurrencesHonoursCountAndEndDate(t *testing.T) {
+	rule := db.Recurrence{
+		Frequency:   FrequencyWeekly,
+		Interval:    2,
+		StartDate:   date(2023, time.March, 1),
+		Occurrences: sql.NullInt32{Int32: 3, Valid: true},
+	}
+
+	dates := Occurrences(rule, rule.StartDate, time.Time{}, 0)
+	require.Equal(t, []time.Time{
+		date(2023, time.March, 1),
+		date(2023, time.March, 15),
+		date(2023, time.March, 29),
+	}, dates)
+
+	rule.Occurrences = sql.NullInt32{}
+	rule.EndDate = sql.NullTime{Time: date(2023, time.March, 20), Valid: true}
+
+	dates = Occurrences(rule, date(2023, time.March, 2), time.Time{}, 0)
+	require.Equal(t, []time.Time{date(2023, time.March, 15)}, dates)
+}
+
+func TestOccurrencesOpenEndedNeedsBound(t *testing.T) {
+	rule := db.Recurrence{
+		Frequency: FrequencyYearly,
+		Interval:  1,
+		StartDate: date(2020, time.February, 29),
+	}
+
+	require.Empty(t, Occurrences(rule, rule.StartDate, time.Time{}, 0))
+	require.Equal(t, []time.Time{
+		date(2020, time.February, 29),
+		date(2021, time.February, 28),
+		date(2022, time.February, 28),
+	}, Occurrences(rule, rule.StartDate, time.Time{}, 3))
+}
+
+func TestOccurrencesInvalidRule(t *testing.T) {
+	rule := db.Recurrence{
+		Frequency: "hourly",
+		Interval:  1,
+		StartDate: date(2023, time.March, 1),
+	}
+
+	// Without a known frequency the dates would never pass to.
+	require.True(t, Nth(rule, 1).IsZero())
+	require.Empty(t, Occurrences(rule, rule.StartDate, date(2023, time.March, 31), 0))
+
+	rule.Frequency = FrequencyDaily
+	rule.Interval = 0
+	require.Empty(t, Occurrences(rule, rule.StartDate, date(2023, time.March, 31), 0))
+}
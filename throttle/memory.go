@@ -0,0 +1,60 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps attempts in the process, so they are lost on restart and
+// not shared between instances.
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempts
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{attempts: map[string]Attempts{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	if attempts.LastFailedAt.Before(resetBefore) {
+		attempts = Attempts{}
+	}
+	attempts.Failures++
+	attempts.LastFailedAt = failedAt
+	s.attempts[key] = attempts
+	return attempts, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) (Attempts, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempts := s.attempts[key]
+	delete(s.attempts, key)
+	return attempts, nil
+}
+
+func (s *MemoryStore) Prune(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, attempts := range s.attempts {
+		if attempts.LastFailedAt.Before(before) {
+			delete(s.attempts, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package throttle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+)
+
+// PostgresStore keeps attempts in the login_attempts table, where every
+// instance of the API sees them. Failures are counted by a single upsert, so
+// concurrent attempts are not lost.
+type PostgresStore struct {
+	querier db.Querier
+}
+
+func NewPostgresStore(querier db.Querier) *PostgresStore {
+	return &PostgresStore{querier: querier}
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) (Attempts, error) {
+	attempt, err := s.querier.GetLoginAttempt(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return newAttempts(attempt), nil
+}
+
+func (s *PostgresStore) RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (Attempts, error) {
+	attempt, err := s.querier.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		Key:         key,
+		FailedAt:    failedAt,
+		ResetBefore: resetBefore,
+	})
+	if err != nil {
+		return Attempts{}, err
+	}
+	return newAttempts(attempt), nil
+}
+
+func (s *PostgresStore) Reset(ctx context.Context, key string) (Attempts, error) {
+	attempt, err := s.querier.DeleteLoginAttempt(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Attempts{}, nil
+	}
+	if err != nil {
+		return Attempts{}, err
+	}
+	return newAttempts(attempt), nil
+}
+
+func (s *PostgresStore) Prune(ctx context.Context, before time.Time) error {
+	return s.querier.DeleteStaleLoginAttempts(ctx, before)
+}
+
+func newAttempts(attempt db.LoginAttempt) Attempts {
+	return Attempts{
+		Failures:     int(attempt.Failures),
+		LastFailedAt: attempt.LastFailedAt,
+	}
+}
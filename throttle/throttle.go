@@ -0,0 +1,267 @@
+// Package throttle slows down password guessing on login. Failed attempts are
+// counted per username and per client IP; past a number of free attempts each
+// further one has to wait twice as long as the previous, and enough failures
+// lock the key out for a while.
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	db "github.com/methyago/gofinance-backend/db/sqlc"
+	"github.com/methyago/gofinance-backend/util"
+)
+
+const (
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+// Attempts is the count of recent failed logins of a key.
+type Attempts struct {
+	Failures     int
+	LastFailedAt time.Time
+}
+
+// Store keeps the failed attempts of each key. The memory store suits a
+// single instance; instances sharing a database share the postgres store.
+type Store interface {
+	Get(ctx context.Context, key string) (Attempts, error)
+	// RecordFailure adds a failure at failedAt, first forgetting the earlier
+	// ones when the last of them happened before resetBefore.
+	RecordFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (Attempts, error)
+	// Reset forgets the failures of key and returns what they were.
+	Reset(ctx context.Context, key string) (Attempts, error)
+	// Prune forgets every key whose last failure happened before before.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// Policy is how many failures a key is allowed and what happens past them.
+type Policy struct {
+	// FreeAttempts failures go without delay. Each further failure has to be
+	// followed by a wait of BaseDelay, doubling with every failure.
+	FreeAttempts int
+	BaseDelay    time.Duration
+	// LockoutAttempts failures lock the key out for LockoutDuration.
+	LockoutAttempts int
+	LockoutDuration time.Duration
+	// ResetAfter without failures, the count starts over.
+	ResetAfter time.Duration
+}
+
+// blockedUntil returns when the key with attempts may try again, or the zero
+// time if it may right away.
+func (p Policy) blockedUntil(attempts Attempts) time.Time {
+	switch {
+	case attempts.Failures >= p.LockoutAttempts:
+		return attempts.LastFailedAt.Add(p.LockoutDuration)
+	case attempts.Failures >= p.FreeAttempts:
+		delay := p.LockoutDuration
+		if shift := attempts.Failures - p.FreeAttempts; shift < 32 && p.BaseDelay<<shift < delay {
+			delay = p.BaseDelay << shift
+		}
+		return attempts.LastFailedAt.Add(delay)
+	default:
+		return time.Time{}
+	}
+}
+
+func (p Policy) wait(attempts Attempts, now time.Time) time.Duration {
+	if now.Sub(attempts.LastFailedAt) > p.ResetAfter {
+		return 0
+	}
+	wait := p.blockedUntil(attempts).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+func (p Policy) validate() error {
+	if p.FreeAttempts < 0 || p.LockoutAttempts < p.FreeAttempts || p.LockoutAttempts == 0 {
+		return fmt.Errorf("lockout attempts must be positive and at least the free attempts")
+	}
+	if p.BaseDelay <= 0 || p.LockoutDuration < p.BaseDelay || p.ResetAfter < p.LockoutDuration {
+		return fmt.Errorf("delays must be positive and no longer than the lockout, which must not outlast the reset")
+	}
+	return nil
+}
+
+// Limiter applies a policy to usernames and another to client IPs.
+type Limiter struct {
+	store  Store
+	user   Policy
+	ip     Policy
+	logger *log.Logger
+	now    func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// New builds the limiter described by config. The postgres store keeps its
+// data through querier.
+func New(config util.ThrottleConfig, querier db.Querier) (*Limiter, error) {
+	var store Store
+	switch config.Store {
+	case StoreMemory, "":
+		store = NewMemoryStore()
+	case StorePostgres:
+		store = NewPostgresStore(querier)
+	default:
+		return nil, fmt.Errorf("unsupported login throttle store %q", config.Store)
+	}
+
+	user := Policy{
+		FreeAttempts:    config.FreeAttempts,
+		BaseDelay:       config.BaseDelay,
+		LockoutAttempts: config.LockoutAttempts,
+		LockoutDuration: config.LockoutDuration,
+		ResetAfter:      config.ResetAfter,
+	}
+	ip := user
+	ip.FreeAttempts = config.IPFreeAttempts
+	ip.LockoutAttempts = config.IPLockoutAttempts
+	return NewLimiter(store, user, ip, log.Default())
+}
+
+// NewLimiter limits usernames with user and client IPs with ip, and writes
+// lockouts and unlocks to logger.
+func NewLimiter(store Store, user, ip Policy, logger *log.Logger) (*Limiter, error) {
+	if err := user.validate(); err != nil {
+		return nil, fmt.Errorf("invalid username policy: %w", err)
+	}
+	if err := ip.validate(); err != nil {
+		return nil, fmt.Errorf("invalid client IP policy: %w", err)
+	}
+	return &Limiter{
+		store:  store,
+		user:   user,
+		ip:     ip,
+		logger: logger,
+		now:    time.Now,
+	}, nil
+}
+
+type limitedKey struct {
+	name   string
+	policy Policy
+}
+
+// keys returns the keys a login attempt counts against. Usernames are
+// matched regardless of case, like the users they name.
+func (l *Limiter) keys(username, ip string) []limitedKey {
+	keys := []limitedKey{{name: userKey(username), policy: l.user}}
+	if ip != "" {
+		keys = append(keys, limitedKey{name: "ip:" + ip, policy: l.ip})
+	}
+	return keys
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+// Check returns how long a login as username from ip has to wait before it
+// may be tried, zero when it may be tried now. Keys whose lockout is over
+// start over with their free attempts.
+func (l *Limiter) Check(ctx context.Context, username, ip string) (time.Duration, error) {
+	now := l.now()
+	var wait time.Duration
+	for _, key := range l.keys(username, ip) {
+		attempts, err := l.store.Get(ctx, key.name)
+		if err != nil {
+			return 0, err
+		}
+		if attempts.Failures >= key.policy.LockoutAttempts && !now.Before(key.policy.blockedUntil(attempts)) {
+			err = l.expireLockout(ctx, key)
+			if err != nil {
+				return 0, err
+			}
+			continue
+		}
+		wait = longer(wait, key.policy.wait(attempts, now))
+	}
+	return wait, nil
+}
+
+// expireLockout forgets the failures of a key whose lockout has run out and
+// logs the unlock. Of concurrent calls only the one that found the failures
+// logs it.
+func (l *Limiter) expireLockout(ctx context.Context, key limitedKey) error {
+	attempts, err := l.store.Reset(ctx, key.name)
+	if err != nil {
+		return err
+	}
+	if attempts.Failures >= key.policy.LockoutAttempts {
+		l.logger.Printf("audit: login unlocked for %s as the lockout after %d failed attempts expired at %s",
+			key.name, attempts.Failures, key.policy.blockedUntil(attempts).Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Failure records a failed login as username from ip and returns how long
+// the next attempt has to wait.
+func (l *Limiter) Failure(ctx context.Context, username, ip string) (time.Duration, error) {
+	now := l.now()
+	l.prune(ctx, now)
+
+	var wait time.Duration
+	for _, key := range l.keys(username, ip) {
+		attempts, err := l.store.RecordFailure(ctx, key.name, now, now.Add(-key.policy.ResetAfter))
+		if err != nil {
+			return 0, err
+		}
+		if attempts.Failures >= key.policy.LockoutAttempts {
+			l.logger.Printf("audit: login locked for %s after %d failed attempts until %s",
+				key.name, attempts.Failures, key.policy.blockedUntil(attempts).Format(time.RFC3339))
+		}
+		wait = longer(wait, key.policy.wait(attempts, now))
+	}
+	return wait, nil
+}
+
+// Success forgets the failed logins as username. Those from the client IP
+// are kept, or a single known password would let an IP guess on forever.
+func (l *Limiter) Success(ctx context.Context, username string) error {
+	key := userKey(username)
+	attempts, err := l.store.Reset(ctx, key)
+	if err != nil {
+		return err
+	}
+	if attempts.Failures >= l.user.LockoutAttempts {
+		l.logger.Printf("audit: login unlocked for %s by a successful login after %d failed attempts",
+			key, attempts.Failures)
+	}
+	return nil
+}
+
+// prune drops keys without recent failures, at most once per reset period.
+func (l *Limiter) prune(ctx context.Context, now time.Time) {
+	resetAfter := longer(l.user.ResetAfter, l.ip.ResetAfter)
+
+	l.mu.Lock()
+	due := now.Sub(l.lastPruned) > resetAfter
+	if due {
+		l.lastPruned = now
+	}
+	l.mu.Unlock()
+
+	if due {
+		err := l.store.Prune(ctx, now.Add(-resetAfter))
+		if err != nil {
+			log.Printf("cannot prune login attempts: %v", err)
+		}
+	}
+}
+
+func longer(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
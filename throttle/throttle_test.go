@@ -0,0 +1,166 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPolicy = Policy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	LockoutAttempts: 6,
+	LockoutDuration: 15 * time.Minute,
+	ResetAfter:      time.Hour,
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(t *testing.T) (*Limiter, *testClock, *bytes.Buffer) {
+	ipPolicy := testPolicy
+	ipPolicy.FreeAttempts = 10
+	ipPolicy.LockoutAttempts = 20
+
+	var audit bytes.Buffer
+	limiter, err := NewLimiter(NewMemoryStore(), testPolicy, ipPolicy, log.New(&audit, "", 0))
+	require.NoError(t, err)
+
+	clock := &testClock{now: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	limiter.now = clock.Now
+	return limiter, clock, &audit
+}
+
+func TestBackoff(t *testing.T) {
+	limiter, clock, _ := newTestLimiter(t)
+	ctx := context.Background()
+
+	for i := 1; i < testPolicy.FreeAttempts; i++ {
+		wait, err := limiter.Failure(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+
+	for _, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		wait, err := limiter.Failure(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+		require.Equal(t, want, wait)
+
+		wait, err = limiter.Check(ctx, "ALICE", "192.0.2.2")
+		require.NoError(t, err)
+		require.Equal(t, want, wait)
+
+		clock.now = clock.now.Add(want)
+		wait, err = limiter.Check(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+}
+
+func TestLockout(t *testing.T) {
+	limiter, clock, audit := newTestLimiter(t)
+	ctx := context.Background()
+
+	var wait time.Duration
+	var err error
+	for i := 0; i < testPolicy.LockoutAttempts; i++ {
+		wait, err = limiter.Failure(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+	}
+	require.Equal(t, testPolicy.LockoutDuration, wait)
+	require.Contains(t, audit.String(), "login locked for user:alice after 6 failed attempts")
+
+	// Other users are not affected.
+	wait, err = limiter.Check(ctx, "bob", "192.0.2.1")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	clock.now = clock.now.Add(testPolicy.LockoutDuration)
+	wait, err = limiter.Check(ctx, "alice", "192.0.2.1")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	require.NoError(t, limiter.Success(ctx, "Alice"))
+	require.Contains(t, audit.String(), "login unlocked for user:alice")
+
+	// The client IP keeps its failures.
+	attempts, err := limiter.store.Get(ctx, "ip:192.0.2.1")
+	require.NoError(t, err)
+	require.Equal(t, testPolicy.LockoutAttempts, attempts.Failures)
+}
+
+func TestLockoutExpiry(t *testing.T) {
+	limiter, clock, audit := newTestLimiter(t)
+	ctx := context.Background()
+
+	for i := 0; i < testPolicy.LockoutAttempts; i++ {
+		_, err := limiter.Failure(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+	}
+
+	clock.now = clock.now.Add(testPolicy.LockoutDuration - time.Second)
+	wait, err := limiter.Check(ctx, "alice", "192.0.2.1")
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+	require.NotContains(t, audit.String(), "unlocked")
+
+	// The lockout running out is logged once, before the key starts over.
+	clock.now = clock.now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		wait, err = limiter.Check(ctx, "alice", "192.0.2.1")
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+	require.Equal(t, 1, strings.Count(audit.String(), "login unlocked for user:alice as the lockout after 6 failed attempts expired at 2023-05-01T12:15:00Z"))
+
+	attempts, err := limiter.store.Get(ctx, "user:alice")
+	require.NoError(t, err)
+	require.Zero(t, attempts.Failures)
+
+	wait, err = limiter.Failure(ctx, "alice", "192.0.2.1")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+	require.NoError(t, limiter.Success(ctx, "alice"))
+	require.Equal(t, 1, strings.Count(audit.String(), "unlocked"))
+}
+
+func TestResetAfter(t *testing.T) {
+	limiter, clock, _ := newTestLimiter(t)
+	ctx := context.Background()
+
+	for i := 0; i < testPolicy.LockoutAttempts; i++ {
+		_, err := limiter.Failure(ctx, "alice", "")
+		require.NoError(t, err)
+	}
+
+	clock.now = clock.now.Add(testPolicy.ResetAfter + time.Second)
+	wait, err := limiter.Failure(ctx, "alice", "")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	attempts, err := limiter.store.Get(ctx, "user:alice")
+	require.NoError(t, err)
+	require.Equal(t, 1, attempts.Failures)
+}
+
+func TestInvalidPolicy(t *testing.T) {
+	policy := testPolicy
+	policy.LockoutAttempts = policy.FreeAttempts - 1
+	_, err := NewLimiter(NewMemoryStore(), policy, testPolicy, log.Default())
+	require.Error(t, err)
+
+	policy = testPolicy
+	policy.ResetAfter = policy.LockoutDuration / 2
+	_, err = NewLimiter(NewMemoryStore(), testPolicy, policy, log.Default())
+	require.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package token
+
+import (
+	"errors"
+	"os"
+
+	"github.com/methyago/gofinance-backend/util"
+)
+
+// LoadKeyring builds the keyring described by config, reading key files from
+// disk. The previous key is optional; its algorithm defaults to the current
+// one.
+func LoadKeyring(config util.TokenConfig) (*Keyring, error) {
+	current, err := loadKey(config.KeyID, config.Algorithm, config.Secret, config.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.PreviousKeyID == "" {
+		return NewKeyring(current)
+	}
+
+	algorithm := config.PreviousAlgorithm
+	if algorithm == "" {
+		algorithm = config.Algorithm
+	}
+	previous, err := loadKey(config.PreviousKeyID, algorithm, config.PreviousSecret, config.PreviousKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if config.PreviousValidUntil.IsZero() {
+		return nil, errors.New("the previous key needs a valid until time")
+	}
+	previous.ValidUntil = config.PreviousValidUntil
+
+	return NewKeyring(current, previous)
+}
+
+func loadKey(id, algorithm, secret, keyFile string) (Key, error) {
+	if algorithm == AlgorithmHS256 {
+		return NewHMACKey(id, []byte(secret))
+	}
+
+	material, err := os.ReadFile(keyFile)
+	if err != nil {
+		return Key{}, err
+	}
+	return ParseKey(id, algorithm, material)
+}
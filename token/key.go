@@ -0,0 +1,111 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minSecretSize is the shortest HS256 secret accepted, the size of the hash.
+const minSecretSize = 32
+
+var errNoSigningKey = errors.New("key can only verify tokens")
+
+// Key is one key of a Keyring. A key parsed from a public key can only verify
+// tokens.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// ValidUntil stops a retired key from verifying tokens once the grace
+	// period is over. Zero means the key never expires.
+	ValidUntil time.Time
+
+	signKey   any
+	verifyKey any
+}
+
+// NewHMACKey returns an HS256 key for secret.
+func NewHMACKey(id string, secret []byte) (Key, error) {
+	if len(secret) < minSecretSize {
+		return Key{}, fmt.Errorf("HS256 secret must have at least %d bytes", minSecretSize)
+	}
+	return Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}, nil
+}
+
+// ParseKey builds a key for algorithm. For HS256 material is the secret; for
+// RS256 and EdDSA it is a PEM encoded private key (PKCS #1 or PKCS #8) or a
+// public key (PKIX).
+func ParseKey(id, algorithm string, material []byte) (Key, error) {
+	if algorithm == AlgorithmHS256 {
+		return NewHMACKey(id, material)
+	}
+
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return Key{}, fmt.Errorf("%s key %q is not PEM encoded", algorithm, id)
+	}
+
+	var signKey, verifyKey any
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		verifyKey = key
+	} else if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		// PKCS #8 also holds keys that cannot sign at all, like X25519.
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return Key{}, fmt.Errorf("%s key %q cannot sign", algorithm, id)
+		}
+		signKey = signer
+		verifyKey = signer.Public()
+	} else if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		signKey = key
+		verifyKey = key.Public()
+	} else {
+		return Key{}, fmt.Errorf("cannot parse %s key %q", algorithm, id)
+	}
+
+	key := Key{
+		ID:        id,
+		signKey:   signKey,
+		verifyKey: verifyKey,
+	}
+	switch algorithm {
+	case AlgorithmRS256:
+		_, private := signKey.(*rsa.PrivateKey)
+		if _, ok := verifyKey.(*rsa.PublicKey); !ok || (signKey != nil && !private) {
+			return Key{}, fmt.Errorf("key %q is not an RSA key", id)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		_, private := signKey.(ed25519.PrivateKey)
+		if _, ok := verifyKey.(ed25519.PublicKey); !ok || (signKey != nil && !private) {
+			return Key{}, fmt.Errorf("key %q is not an Ed25519 key", id)
+		}
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return Key{}, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	return key, nil
+}
+
+// CanSign reports whether the private half of the key is known.
+func (k Key) CanSign() bool {
+	return k.signKey != nil
+}
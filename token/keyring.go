@@ -0,0 +1,74 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey = errors.New("token signed with an unknown key")
+	ErrKeyExpired = errors.New("token signed with a retired key")
+)
+
+// Keyring signs tokens with its current key and verifies them with any key it
+// holds, picked by the kid header. Rotating means promoting a new current key
+// and keeping the old one, with a ValidUntil at least one token lifetime away,
+// so tokens issued before the rotation keep working until they expire.
+type Keyring struct {
+	current Key
+	keys    map[string]Key
+	now     func() time.Time
+}
+
+func NewKeyring(current Key, previous ...Key) (*Keyring, error) {
+	if !current.CanSign() {
+		return nil, fmt.Errorf("current key %q: %w", current.ID, errNoSigningKey)
+	}
+
+	keyring := &Keyring{
+		current: current,
+		keys:    map[string]Key{},
+		now:     time.Now,
+	}
+	for _, key := range append([]Key{current}, previous...) {
+		if _, ok := keyring.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keyring.keys[key.ID] = key
+	}
+	return keyring, nil
+}
+
+// Sign signs claims with the current key.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.current.Method, claims)
+	token.Header["kid"] = k.current.ID
+	return token.SignedString(k.current.signKey)
+}
+
+// Parse verifies tokenString and decodes it into claims. It also validates the
+// registered claims, such as the expiry.
+func (k *Keyring) Parse(tokenString string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, k.verifyKey)
+	return err
+}
+
+func (k *Keyring) verifyKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if !key.ValidUntil.IsZero() && k.now().After(key.ValidUntil) {
+		return nil, ErrKeyExpired
+	}
+	// The algorithm comes from the key, never from the token, so a token
+	// cannot pick "none" or use an RSA public key as an HMAC secret.
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.verifyKey, nil
+}
@@ -0,0 +1,240 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/methyago/gofinance-backend/util"
+	"github.com/stretchr/testify/require"
+)
+
+func rsaPEM(t *testing.T) (private, public []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	private = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return private, public
+}
+
+func ed25519PEM(t *testing.T) (private, public []byte) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	private = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return private, public
+}
+
+func testClaims() *jwt.RegisteredClaims {
+	return &jwt.RegisteredClaims{
+		Subject:   "alice",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}
+}
+
+func TestSignAndParse(t *testing.T) {
+	rsaPrivate, _ := rsaPEM(t)
+	edPrivate, _ := ed25519PEM(t)
+
+	testCases := []struct {
+		algorithm string
+		material  []byte
+	}{
+		{AlgorithmHS256, []byte(util.RandomString(32))},
+		{AlgorithmRS256, rsaPrivate},
+		{AlgorithmEdDSA, edPrivate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			key, err := ParseKey("k1", tc.algorithm, tc.material)
+			require.NoError(t, err)
+			keyring, err := NewKeyring(key)
+			require.NoError(t, err)
+
+			signed, err := keyring.Sign(testClaims())
+			require.NoError(t, err)
+
+			claims := &jwt.RegisteredClaims{}
+			require.NoError(t, keyring.Parse(signed, claims))
+			require.Equal(t, "alice", claims.Subject)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+			require.Equal(t, "k1", parsed.Header["kid"])
+			require.Equal(t, tc.algorithm, parsed.Method.Alg())
+		})
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	_, rsaPublic := rsaPEM(t)
+	_, edPublic := ed25519PEM(t)
+
+	_, err := ParseKey("k", AlgorithmHS256, []byte("short"))
+	require.Error(t, err)
+
+	_, err = ParseKey("k", AlgorithmRS256, []byte("not pem"))
+	require.Error(t, err)
+
+	_, err = ParseKey("k", AlgorithmRS256, edPublic)
+	require.Error(t, err)
+
+	_, err = ParseKey("k", AlgorithmEdDSA, rsaPublic)
+	require.Error(t, err)
+
+	_, err = ParseKey("k", "ES256", rsaPublic)
+	require.Error(t, err)
+
+	// Private keys of another type fail instead of panicking, including
+	// PKCS #8 keys that cannot sign, like this X25519 key.
+	x25519, err := hex.DecodeString("302e020100300506032b656e04220420" + strings.Repeat("11", 32))
+	require.NoError(t, err)
+	_, err = ParseKey("k", AlgorithmEdDSA, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: x25519}))
+	require.Error(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	_, err = ParseKey("k", AlgorithmRS256, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER}))
+	require.Error(t, err)
+
+	rsaPrivate, _ := rsaPEM(t)
+	_, err = ParseKey("k", AlgorithmEdDSA, rsaPrivate)
+	require.Error(t, err)
+
+	// A public key verifies but cannot be the current signing key.
+	key, err := ParseKey("k", AlgorithmRS256, rsaPublic)
+	require.NoError(t, err)
+	require.False(t, key.CanSign())
+	_, err = NewKeyring(key)
+	require.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	oldKey, err := NewHMACKey("old", []byte(util.RandomString(32)))
+	require.NoError(t, err)
+	oldKeyring, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	issuedBefore, err := oldKeyring.Sign(testClaims())
+	require.NoError(t, err)
+
+	rsaPrivate, _ := rsaPEM(t)
+	newKey, err := ParseKey("new", AlgorithmRS256, rsaPrivate)
+	require.NoError(t, err)
+
+	now := time.Now()
+	oldKey.ValidUntil = now.Add(time.Hour)
+	keyring, err := NewKeyring(newKey, oldKey)
+	require.NoError(t, err)
+	keyring.now = func() time.Time { return now }
+
+	issuedAfter, err := keyring.Sign(testClaims())
+	require.NoError(t, err)
+
+	require.NoError(t, keyring.Parse(issuedBefore, &jwt.RegisteredClaims{}))
+	require.NoError(t, keyring.Parse(issuedAfter, &jwt.RegisteredClaims{}))
+	require.ErrorIs(t, oldKeyring.Parse(issuedAfter, &jwt.RegisteredClaims{}), ErrUnknownKey)
+
+	// Once the grace period is over the old key is no longer accepted.
+	keyring.now = func() time.Time { return now.Add(2 * time.Hour) }
+	require.ErrorIs(t, keyring.Parse(issuedBefore, &jwt.RegisteredClaims{}), ErrKeyExpired)
+	require.NoError(t, keyring.Parse(issuedAfter, &jwt.RegisteredClaims{}))
+
+	_, err = NewKeyring(newKey, newKey)
+	require.Error(t, err)
+}
+
+func TestAlgorithmConfusion(t *testing.T) {
+	rsaPrivate, rsaPublic := rsaPEM(t)
+	key, err := ParseKey("k1", AlgorithmRS256, rsaPrivate)
+	require.NoError(t, err)
+	keyring, err := NewKeyring(key)
+	require.NoError(t, err)
+
+	// An HS256 token keyed with the published RSA public key must not verify.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims())
+	forged.Header["kid"] = "k1"
+	signed, err := forged.SignedString(rsaPublic)
+	require.NoError(t, err)
+
+	require.Error(t, keyring.Parse(signed, &jwt.RegisteredClaims{}))
+}
+
+func TestJWKS(t *testing.T) {
+	rsaPrivate, _ := rsaPEM(t)
+	_, edPublic := ed25519PEM(t)
+
+	current, err := ParseKey("rsa", AlgorithmRS256, rsaPrivate)
+	require.NoError(t, err)
+	previous, err := ParseKey("ed", AlgorithmEdDSA, edPublic)
+	require.NoError(t, err)
+	previous.ValidUntil = time.Now().Add(time.Hour)
+	secret, err := NewHMACKey("hmac", []byte(util.RandomString(32)))
+	require.NoError(t, err)
+
+	keyring, err := NewKeyring(current, previous, secret)
+	require.NoError(t, err)
+
+	set := keyring.JWKS()
+	require.Len(t, set.Keys, 2)
+	require.Equal(t, "ed", set.Keys[0].KeyID)
+	require.Equal(t, "OKP", set.Keys[0].KeyType)
+	require.Equal(t, "Ed25519", set.Keys[0].Curve)
+	require.NotEmpty(t, set.Keys[0].X)
+	require.Equal(t, "rsa", set.Keys[1].KeyID)
+	require.Equal(t, "RSA", set.Keys[1].KeyType)
+	require.Equal(t, "RS256", set.Keys[1].Algorithm)
+	require.Equal(t, "AQAB", set.Keys[1].E)
+
+	keyring.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	require.Len(t, keyring.JWKS().Keys, 1)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	edPrivate, _ := ed25519PEM(t)
+	keyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(keyFile, edPrivate, 0600))
+
+	config := util.TokenConfig{
+		Algorithm:          AlgorithmEdDSA,
+		KeyID:              "2024-02",
+		PrivateKeyFile:     keyFile,
+		PreviousAlgorithm:  AlgorithmHS256,
+		PreviousKeyID:      "2024-01",
+		PreviousSecret:     util.RandomString(32),
+		PreviousValidUntil: time.Now().Add(time.Hour),
+	}
+	keyring, err := LoadKeyring(config)
+	require.NoError(t, err)
+	require.Len(t, keyring.keys, 2)
+	require.Equal(t, "2024-02", keyring.current.ID)
+
+	config.PreviousValidUntil = time.Time{}
+	_, err = LoadKeyring(config)
+	require.Error(t, err)
+
+	config.PrivateKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = LoadKeyring(config)
+	require.Error(t, err)
+}
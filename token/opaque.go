@@ -0,0 +1,28 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaqueToken returns a random URL safe token with 256 bits of entropy,
+// for refresh tokens and other secrets that are looked up rather than
+// verified.
+func NewOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashOpaqueToken is what gets stored for an opaque token, so a leaked table
+// does not leak usable tokens. The token is random, so a plain SHA-256 is
+// enough.
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
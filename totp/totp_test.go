@@ -0,0 +1,66 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA-1 key of the RFC 6238 test vectors.
+var rfcSecret = encoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestRFC6238Vectors(t *testing.T) {
+	key, err := decodeSecret(rfcSecret)
+	require.NoError(t, err)
+
+	for unix, want := range map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	} {
+		step := Step(time.Unix(unix, 0))
+		require.Equal(t, want, hotp(key, uint64(step), 8), "time %d", unix)
+
+		code, err := Code(rfcSecret, step)
+		require.NoError(t, err)
+		require.Equal(t, want[2:], code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Date(2023, 5, 1, 12, 0, 10, 0, time.UTC)
+	code, err := Code(secret, Step(now))
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now, 1)
+	require.True(t, ok)
+	require.Equal(t, Step(now), step)
+
+	// Still valid one step later, not two.
+	_, ok = Validate(secret, code, now.Add(Period), 1)
+	require.True(t, ok)
+	_, ok = Validate(secret, code, now.Add(2*Period), 1)
+	require.False(t, ok)
+
+	_, ok = Validate(secret, "12345", now, 1)
+	require.False(t, ok)
+	_, ok = Validate("not base32!", code, now, 1)
+	require.False(t, ok)
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Go Finance", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	require.True(t, strings.HasPrefix(uri, "otpauth://totp/Go%20Finance:alice@example.com?"))
+	require.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	require.Contains(t, uri, "issuer=Go%20Finance")
+	require.Contains(t, uri, "digits=6")
+	require.Contains(t, uri, "period=30")
+}
@@ -0,0 +1,299 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the settings of the service, read from environment variables
+// (main.go loads the .env file into the environment first).
+type Config struct {
+	DBDriver      string
+	DBSource      string
+	ServerAddress string
+	// TrustedProxies are the addresses or CIDR ranges of the reverse proxies
+	// whose X-Forwarded-For header tells the client IP. With none, the client
+	// IP is the address of the connection.
+	TrustedProxies     []string
+	RecurrenceInterval time.Duration
+	Token              TokenConfig
+	Mail               MailConfig
+	Password           PasswordConfig
+	LoginThrottle      ThrottleConfig
+
+	// PasswordResetURL is the page of the client that lets users pick a new
+	// password. The reset token is appended as the token query parameter.
+	PasswordResetURL      string
+	PasswordResetDuration time.Duration
+
+	// EmailVerificationPolicy is what unverified users are kept from doing,
+	// one of the EmailVerification constants.
+	EmailVerificationPolicy   string
+	EmailVerificationURL      string
+	EmailVerificationDuration time.Duration
+
+	// TOTPIssuer names the service in authenticator apps.
+	TOTPIssuer string
+	// MFATokenDuration is how long the challenge token that login returns to
+	// users with two-factor authentication can be exchanged for real tokens.
+	MFATokenDuration time.Duration
+
+	// LedgerInvitationURL is the page of the client that accepts an
+	// invitation to a shared ledger, with the token as its query parameter.
+	LedgerInvitationURL      string
+	LedgerInvitationDuration time.Duration
+}
+
+const (
+	// EmailVerificationNone lets unverified users use the API.
+	EmailVerificationNone = "none"
+	// EmailVerificationLogin refuses to log unverified users in.
+	EmailVerificationLogin = "login"
+	// EmailVerificationWrites lets unverified users log in and read, but not
+	// change, their data.
+	EmailVerificationWrites = "writes"
+)
+
+// TokenConfig describes the key that signs access tokens and, during a
+// rotation, the key it replaced.
+type TokenConfig struct {
+	Algorithm      string
+	KeyID          string
+	Secret         string
+	PrivateKeyFile string
+	Duration       time.Duration
+	// RefreshDuration is how long a refresh token can be exchanged for a
+	// new pair of tokens.
+	RefreshDuration time.Duration
+
+	PreviousAlgorithm  string
+	PreviousKeyID      string
+	PreviousSecret     string
+	PreviousKeyFile    string
+	PreviousValidUntil time.Time
+}
+
+// PasswordConfig holds the argon2id cost of new password hashes. Raising it
+// makes existing hashes get rehashed as users log in.
+type PasswordConfig struct {
+	// Memory is in KiB.
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// ThrottleConfig limits failed logins per username and, more loosely, per
+// client IP. Store is where failures are counted: "memory" for a single
+// instance or "postgres" to share them between instances.
+type ThrottleConfig struct {
+	Store             string
+	FreeAttempts      int
+	LockoutAttempts   int
+	IPFreeAttempts    int
+	IPLockoutAttempts int
+	// BaseDelay is the wait after the first failure past the free attempts,
+	// doubled after every further one.
+	BaseDelay       time.Duration
+	LockoutDuration time.Duration
+	// ResetAfter without failures, a key starts over.
+	ResetAfter time.Duration
+}
+
+// MailConfig selects how emails are sent: through an SMTP server, or written
+// to a log file (stdout when LogFile is empty) for local development.
+type MailConfig struct {
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	LogFile      string
+}
+
+func LoadConfig() (Config, error) {
+	config := Config{
+		DBDriver:       os.Getenv("DB_DRIVER"),
+		DBSource:       os.Getenv("DB_SOURCE"),
+		ServerAddress:  os.Getenv("SERVER_ADDRESS"),
+		TrustedProxies: envList("TRUSTED_PROXIES"),
+		Token: TokenConfig{
+			Algorithm:         envOr("JWT_ALGORITHM", "HS256"),
+			KeyID:             envOr("JWT_KEY_ID", "default"),
+			Secret:            os.Getenv("JWT_SECRET"),
+			PrivateKeyFile:    os.Getenv("JWT_PRIVATE_KEY_FILE"),
+			PreviousAlgorithm: os.Getenv("JWT_PREVIOUS_ALGORITHM"),
+			PreviousKeyID:     os.Getenv("JWT_PREVIOUS_KEY_ID"),
+			PreviousSecret:    os.Getenv("JWT_PREVIOUS_SECRET"),
+			PreviousKeyFile:   os.Getenv("JWT_PREVIOUS_KEY_FILE"),
+		},
+		Mail: MailConfig{
+			Driver:       envOr("MAIL_DRIVER", "log"),
+			From:         os.Getenv("MAIL_FROM"),
+			SMTPHost:     os.Getenv("SMTP_HOST"),
+			SMTPUsername: os.Getenv("SMTP_USERNAME"),
+			SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+			LogFile:      os.Getenv("MAIL_LOG_FILE"),
+		},
+		PasswordResetURL:        os.Getenv("PASSWORD_RESET_URL"),
+		EmailVerificationPolicy: envOr("EMAIL_VERIFICATION_POLICY", EmailVerificationNone),
+		EmailVerificationURL:    os.Getenv("EMAIL_VERIFICATION_URL"),
+		TOTPIssuer:              envOr("TOTP_ISSUER", "GoFinance"),
+		LedgerInvitationURL:     os.Getenv("LEDGER_INVITATION_URL"),
+	}
+
+	var err error
+	config.RecurrenceInterval, err = envDuration("RECURRENCE_INTERVAL", time.Hour)
+	if err != nil {
+		return config, err
+	}
+	config.Token.Duration, err = envDuration("JWT_DURATION", 15*time.Minute)
+	if err != nil {
+		return config, err
+	}
+	config.Token.RefreshDuration, err = envDuration("REFRESH_TOKEN_DURATION", 30*24*time.Hour)
+	if err != nil {
+		return config, err
+	}
+
+	config.PasswordResetDuration, err = envDuration("PASSWORD_RESET_DURATION", time.Hour)
+	if err != nil {
+		return config, err
+	}
+	config.EmailVerificationDuration, err = envDuration("EMAIL_VERIFICATION_DURATION", 48*time.Hour)
+	if err != nil {
+		return config, err
+	}
+	config.MFATokenDuration, err = envDuration("MFA_TOKEN_DURATION", 5*time.Minute)
+	if err != nil {
+		return config, err
+	}
+	config.LedgerInvitationDuration, err = envDuration("LEDGER_INVITATION_DURATION", 7*24*time.Hour)
+	if err != nil {
+		return config, err
+	}
+	switch config.EmailVerificationPolicy {
+	case EmailVerificationNone, EmailVerificationLogin, EmailVerificationWrites:
+	default:
+		return config, fmt.Errorf("unsupported EMAIL_VERIFICATION_POLICY %q", config.EmailVerificationPolicy)
+	}
+	config.Mail.SMTPPort, err = envInt("SMTP_PORT", 587)
+	if err != nil {
+		return config, err
+	}
+
+	memory, err := envInt("ARGON2_MEMORY", 64*1024)
+	if err != nil {
+		return config, err
+	}
+	iterations, err := envInt("ARGON2_ITERATIONS", 3)
+	if err != nil {
+		return config, err
+	}
+	parallelism, err := envInt("ARGON2_PARALLELISM", 2)
+	if err != nil {
+		return config, err
+	}
+	if memory <= 0 || iterations <= 0 || parallelism <= 0 || parallelism > 255 {
+		return config, fmt.Errorf("argon2 parameters out of range")
+	}
+	config.Password = PasswordConfig{
+		Memory:      uint32(memory),
+		Iterations:  uint32(iterations),
+		Parallelism: uint8(parallelism),
+	}
+
+	config.LoginThrottle, err = loadThrottleConfig()
+	if err != nil {
+		return config, err
+	}
+
+	if value := os.Getenv("JWT_PREVIOUS_VALID_UNTIL"); value != "" {
+		config.Token.PreviousValidUntil, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return config, fmt.Errorf("cannot parse JWT_PREVIOUS_VALID_UNTIL: %w", err)
+		}
+	}
+
+	return config, nil
+}
+
+func loadThrottleConfig() (ThrottleConfig, error) {
+	config := ThrottleConfig{Store: envOr("LOGIN_THROTTLE_STORE", "memory")}
+
+	var err error
+	for _, setting := range []struct {
+		name     string
+		fallback int
+		value    *int
+	}{
+		{"LOGIN_FREE_ATTEMPTS", 5, &config.FreeAttempts},
+		{"LOGIN_LOCKOUT_ATTEMPTS", 10, &config.LockoutAttempts},
+		{"LOGIN_IP_FREE_ATTEMPTS", 20, &config.IPFreeAttempts},
+		{"LOGIN_IP_LOCKOUT_ATTEMPTS", 100, &config.IPLockoutAttempts},
+	} {
+		*setting.value, err = envInt(setting.name, setting.fallback)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	config.BaseDelay, err = envDuration("LOGIN_BACKOFF_BASE", time.Second)
+	if err != nil {
+		return config, err
+	}
+	config.LockoutDuration, err = envDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute)
+	if err != nil {
+		return config, err
+	}
+	config.ResetAfter, err = envDuration("LOGIN_ATTEMPTS_RESET_AFTER", time.Hour)
+	if err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envList splits a comma-separated variable, dropping empty items.
+func envList(name string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(name), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func envDuration(name string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %s: %w", name, err)
+	}
+	return duration, nil
+}
+
+func envInt(name string, fallback int) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("cannot parse %s: %w", name, err)
+	}
+	return number, nil
+}
@@ -0,0 +1,119 @@
+// Package validation holds the rules user supplied credentials have to meet.
+// Each function returns an error that can be shown to the user as is.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 30
+	MaxEmailLength    = 254
+	MinPasswordLength = 8
+	// MaxPasswordLength bounds the work a single login can cause.
+	MaxPasswordLength = 128
+)
+
+// reservedUsernames would be shadowed by routes under /user.
+var reservedUsernames = map[string]bool{
+	"id":     true,
+	"verify": true,
+}
+
+// Username checks that username is 3 to 30 ASCII letters, digits, dots,
+// dashes or underscores and starts with a letter or a digit.
+func Username(username string) error {
+	if len(username) < MinUsernameLength || len(username) > MaxUsernameLength {
+		return fmt.Errorf("username must be between %d and %d characters long", MinUsernameLength, MaxUsernameLength)
+	}
+	for i, c := range username {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case i > 0 && (c == '.' || c == '-' || c == '_'):
+		default:
+			return fmt.Errorf("username can only contain letters, digits, dots, dashes and underscores and must start with a letter or a digit")
+		}
+	}
+	if reservedUsernames[strings.ToLower(username)] {
+		return fmt.Errorf("username %q is reserved", username)
+	}
+	return nil
+}
+
+// Email checks that email is a bare address, without a display name, whose
+// domain has at least two labels.
+func Email(email string) error {
+	if len(email) > MaxEmailLength {
+		return fmt.Errorf("email must be at most %d characters long", MaxEmailLength)
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return fmt.Errorf("email is not a valid address")
+	}
+	at := strings.LastIndex(email, "@")
+	domain := email[at+1:]
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("email is not a valid address")
+	}
+	return nil
+}
+
+// commonPasswords are refused outright; they are the first ones tried in any
+// guessing attack.
+var commonPasswords = map[string]bool{
+	"12345678":   true,
+	"123456789":  true,
+	"1234567890": true,
+	"11111111":   true,
+	"87654321":   true,
+	"password":   true,
+	"password1":  true,
+	"passw0rd":   true,
+	"qwerty123":  true,
+	"qwertyuiop": true,
+	"1q2w3e4r":   true,
+	"abc12345":   true,
+	"iloveyou":   true,
+	"sunshine":   true,
+	"football":   true,
+	"baseball":   true,
+	"welcome1":   true,
+	"letmein1":   true,
+	"trustno1":   true,
+	"admin123":   true,
+	"gofinance":  true,
+}
+
+// Password checks password against the strength policy: 8 to 128
+// characters, not a single repeated character, not a well known password and
+// not containing any of related, such as the username or the local part of
+// the email.
+func Password(password string, related ...string) error {
+	length := utf8.RuneCountInString(password)
+	if length < MinPasswordLength || length > MaxPasswordLength {
+		return fmt.Errorf("password must be between %d and %d characters long", MinPasswordLength, MaxPasswordLength)
+	}
+
+	lower := strings.ToLower(password)
+	first, _ := utf8.DecodeRuneInString(lower)
+	if strings.TrimLeft(lower, string(first)) == "" {
+		return fmt.Errorf("password cannot be a single repeated character")
+	}
+	if commonPasswords[lower] {
+		return fmt.Errorf("password is too common")
+	}
+	for _, value := range related {
+		value = strings.ToLower(value)
+		if at := strings.LastIndex(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+		if len(value) >= MinUsernameLength && strings.Contains(lower, value) {
+			return fmt.Errorf("password cannot contain your username or email")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsername(t *testing.T) {
+	valid := []string{"bob", "alice_1", "Jane.Doe", "x-y-z", "0day", strings.Repeat("a", MaxUsernameLength)}
+	for _, username := range valid {
+		require.NoError(t, Username(username), username)
+	}
+
+	invalid := []string{"", "ab", strings.Repeat("a", MaxUsernameLength+1), "_alice", ".bob", "al ice", "bob@home", "josé", "verify", "VERIFY"}
+	for _, username := range invalid {
+		require.Error(t, Username(username), username)
+	}
+}
+
+func TestEmail(t *testing.T) {
+	valid := []string{"alice@example.com", "a.b+tag@mail.example.org"}
+	for _, email := range valid {
+		require.NoError(t, Email(email), email)
+	}
+
+	invalid := []string{"", "alice", "alice@", "@example.com", "alice@localhost", "Alice <alice@example.com>", "alice@example.com.", "alice@.com",
+		strings.Repeat("a", MaxEmailLength) + "@example.com"}
+	for _, email := range invalid {
+		require.Error(t, Email(email), email)
+	}
+}
+
+func TestPassword(t *testing.T) {
+	require.NoError(t, Password("correct horse battery", "alice", "alice@example.com"))
+	require.NoError(t, Password("kvmdpqzrta"))
+	require.NoError(t, Password("éèéèéèéèéè"))
+
+	testCases := map[string][]string{
+		"short":                                  nil,
+		strings.Repeat("a", MaxPasswordLength+1): nil,
+		"aaaaaaaaaa":                             nil,
+		"éééééééééééé":                           nil,
+		"ÉéÉéÉéÉéÉé":                             nil,
+		"Password1":                              nil,
+		"my-alice-secret":                        {"Alice"},
+		"secret-bobby-99":                        {"carol", "bobby@example.com"},
+	}
+	for password, related := range testCases {
+		require.Error(t, Password(password, related...), password)
+	}
+}